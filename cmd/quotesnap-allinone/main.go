@@ -0,0 +1,313 @@
+// Command quotesnap-allinone runs the HTTP API and the event worker in one process, with events
+// queued in memory and stored in memory or, with EVENT_STORE=sqlite, in SQLite. It needs neither
+// MongoDB nor Redis, which suits local development and small deployments; features backed by
+// those stores (consent, erasure, rollups, trending, webhooks, sinks and the live tail) are not
+// available in this mode.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+
+	apphttp "quotesnap/internal/app/http"
+	appworker "quotesnap/internal/app/worker"
+	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/geoip"
+	"quotesnap/internal/infra/logger"
+	"quotesnap/internal/infra/metrics"
+	queueasynq "quotesnap/internal/infra/queue/asynq"
+	queuememory "quotesnap/internal/infra/queue/memory"
+	"quotesnap/internal/infra/redaction"
+	"quotesnap/internal/infra/repository"
+	repomemory "quotesnap/internal/infra/repository/memory"
+	"quotesnap/internal/infra/tracing"
+	"quotesnap/internal/infra/useragent"
+)
+
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+	log := logger.New(cfg.AppName + "-allinone")
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.AppName+"-allinone", cfg.TracingEndpoint)
+	if err != nil {
+		log.Error("failed to initialize tracing", "error", err)
+		exit(1)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("tracing shutdown error", "error", err)
+		}
+	}()
+
+	retention, err := cfg.RetentionPolicy()
+	if err != nil {
+		log.Error("invalid retention configuration", "error", err)
+		exit(1)
+	}
+
+	// SQLite keeps events durably on disk; otherwise they live in memory, optionally snapshotted.
+	var (
+		eventRepo  eventStore
+		memoryRepo *repomemory.EventRepository
+		checks     []usecase.DependencyCheck
+	)
+	if cfg.EventStore == config.EventStoreSQLite {
+		openCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		sqliteRepo, closeEvents, err := repository.NewSQLiteEventStore(openCtx, cfg, retention, log)
+		cancel()
+		if err != nil {
+			log.Error("failed to initialize event repository", "error", err)
+			exit(1)
+		}
+		defer closeEvents()
+		eventRepo = sqliteRepo
+		checks = repository.HealthChecks(sqliteRepo)
+	} else {
+		memoryRepo, err = repomemory.NewEventRepository(cfg.MemorySnapshotPath, retention, log)
+		if err != nil {
+			log.Error("failed to initialize event repository", "error", err)
+			exit(1)
+		}
+		eventRepo = memoryRepo
+	}
+
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
+
+	userAgentEnricher, err := useragent.NewEnricher(cfg.BotAction)
+	if err != nil {
+		log.Error("failed to initialize user agent enricher", "error", err)
+		exit(1)
+	}
+	var geoReader *geoip.Reader
+	if cfg.GeoIPDatabasePath != "" {
+		geoReader, err = geoip.Open(cfg.GeoIPDatabasePath, log)
+		if err != nil {
+			log.Error("failed to open geoip database", "error", err)
+			exit(1)
+		}
+		defer geoReader.Close()
+		go geoReader.Watch(runCtx, cfg.GeoIPReloadInterval)
+	}
+	geoEnricher, err := geoip.NewEnricher(geoReader, cfg.GeoIPIPMode)
+	if err != nil {
+		log.Error("failed to initialize geoip enricher", "error", err)
+		exit(1)
+	}
+
+	persistEvent := usecase.NewPersistEvent(metrics.InstrumentEventRepository(eventRepo, storeName(memoryRepo)), metrics.NewPersistDelay())
+	processor := appworker.NewEventProcessor(persistEvent, nil, log, userAgentEnricher, geoEnricher)
+	handleEvent := metrics.InstrumentEventHandler(queueasynq.EventIngestTaskType, processor.ProcessEvent)
+	queue := queuememory.NewQueue(cfg.MemoryQueueSize, cfg.MemoryQueueWorkers, cfg.MemoryQueueAttempts, handleEvent, log)
+
+	var redactionRules []redaction.RuleConfig
+	if cfg.RedactionRulesPath != "" {
+		redactionRules, err = redaction.LoadRules(cfg.RedactionRulesPath)
+		if err != nil {
+			log.Error("failed to load redaction rules", "error", err)
+			exit(1)
+		}
+	}
+	redactor, err := redaction.NewEngine(redactionRules, cfg.RedactionHashSalt)
+	if err != nil {
+		log.Error("failed to initialize redaction engine", "error", err)
+		exit(1)
+	}
+
+	ingestEvent := usecase.NewIngestEvent(metrics.InstrumentQueue(queue, "memory"), nil, redactor)
+	handlers := []routeRegistrar{apphttp.NewEventHandler(ingestEvent, cfg.RequestTimeout, log)}
+	adminHandlers := []routeRegistrar{apphttp.NewRedactionHandler(redactor)}
+	if cfg.AdminAPIToken == "" {
+		log.Warn("ADMIN_API_TOKEN is not set, admin endpoints are disabled")
+		adminHandlers = nil
+	}
+
+	if retention.Enabled() {
+		go purge(runCtx, usecase.NewEnforceRetention(eventRepo), cfg.RetentionPurgeInterval, log)
+	}
+	snapshotsDone := make(chan struct{})
+	if memoryRepo != nil && cfg.MemorySnapshotPath != "" {
+		snapshotCtx, stopSnapshots := context.WithCancel(context.Background())
+		defer stopSnapshots()
+		go func() {
+			defer close(snapshotsDone)
+			memoryRepo.RunSnapshots(snapshotCtx, cfg.MemorySnapshotInterval)
+		}()
+		defer func() {
+			stopSnapshots()
+			<-snapshotsDone
+		}()
+	}
+
+	// Events are kept in process, so there is no dependency to probe.
+	health := apphttp.NewHealthHandler(usecase.NewCheckReadiness(cfg.ReadinessTimeout, checks...))
+	router, err := buildRouter(cfg.AppName+"-allinone", cfg.AdminAPIToken, cfg.TrustedProxies, health, handlers, adminHandlers)
+	if err != nil {
+		log.Error("failed to build router", "error", err)
+		exit(1)
+	}
+	srv := &http.Server{
+		Addr:         cfg.HTTPAddr + ":" + cfg.HTTPPort,
+		Handler:      router,
+		ReadTimeout:  cfg.RequestTimeout + time.Second,
+		WriteTimeout: cfg.RequestTimeout + 2*time.Second,
+	}
+
+	errorCh := make(chan error, 2)
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errorCh <- err
+		}
+	}()
+	// Metrics reveal traffic and internals, so they are served apart from public traffic.
+	var internalSrv *http.Server
+	if cfg.InternalHTTPAddr != "" {
+		internalSrv = newInternalServer(cfg.InternalHTTPAddr)
+		go func() {
+			if err := internalSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errorCh <- err
+			}
+		}()
+	}
+	log.Info("all-in-one server started", "addr", srv.Addr, "event_store", storeName(memoryRepo))
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case sig := <-sigCh:
+		log.Info("shutdown signal received", "signal", sig.String())
+	case err := <-errorCh:
+		log.Error("http server error", "error", err)
+	}
+
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("http server shutdown error", "error", err)
+	}
+	if internalSrv != nil {
+		if err := internalSrv.Shutdown(shutdownCtx); err != nil {
+			log.Error("internal http server shutdown error", "error", err)
+		}
+	}
+
+	// Drain accepted events before the final snapshot so none are lost on a clean shutdown.
+	queue.Close()
+	stopRun()
+}
+
+// eventStore is what the all-in-one process needs from event storage.
+type eventStore interface {
+	usecase.EventRepository
+	usecase.EventPurger
+}
+
+func storeName(memoryRepo *repomemory.EventRepository) string {
+	if memoryRepo != nil {
+		return "memory"
+	}
+	return config.EventStoreSQLite
+}
+
+// newInternalServer serves /metrics on addr.
+func newInternalServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+}
+
+// routeRegistrar is implemented by every HTTP handler.
+type routeRegistrar interface {
+	Register(rg *gin.RouterGroup)
+}
+
+func buildRouter(serviceName, adminToken string, trustedProxies []string, health routeRegistrar, handlers, adminHandlers []routeRegistrar) (*gin.Engine, error) {
+	gin.SetMode(gin.ReleaseMode)
+
+	r := gin.New()
+	// The client ip feeds geolocation, so forwarding headers are only believed from known proxies.
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
+	r.Use(gin.Recovery())
+	r.Use(gin.Logger())
+	r.Use(cors.Default())
+	r.Use(apphttp.RecordMetrics(metrics.NewHTTPRecorder()))
+	r.Use(otelgin.Middleware(serviceName))
+
+	// /healthz predates the probes and is kept for existing load balancer checks.
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	health.Register(&r.RouterGroup)
+
+	api := r.Group("/api/v1")
+	for _, handler := range handlers {
+		handler.Register(api)
+	}
+
+	if len(adminHandlers) > 0 {
+		admin := api.Group("/admin", apphttp.RequireBearerToken(adminToken))
+		for _, handler := range adminHandlers {
+			handler.Register(admin)
+		}
+	}
+
+	return r, nil
+}
+
+// purge enforces retention every interval until ctx is cancelled.
+func purge(ctx context.Context, enforce *usecase.EnforceRetention, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := enforce.Execute(ctx)
+			if err != nil {
+				log.Warn("retention purge failed", "error", err)
+				continue
+			}
+			if deleted > 0 {
+				log.Info("purged expired events", "deleted", deleted)
+			}
+		}
+	}
+}
+
+func exit(code int) {
+	os.Exit(code)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+)
+
+type apiKeyCreated struct {
+	domain.APIKey
+	Secret string `json:"secret"`
+}
+
+// apiKeysCreate issues a key. Only its hash is stored, so the secret is printed here or nowhere.
+func apiKeysCreate(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("apikeys create", flag.ContinueOnError)
+	name := flags.String("name", "", "what the key is for")
+	scopes := flags.String("scopes", "", "comma-separated endpoint groups the key opens: "+scopeNames())
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *name == "" {
+		return usageError("-name is required")
+	}
+	if *scopes == "" {
+		return usageError("-scopes is required, from " + scopeNames())
+	}
+
+	keys, err := manageAPIKeys(ctx, env)
+	if err != nil {
+		return err
+	}
+	key, secret, err := keys.Create(ctx, *name, strings.Split(*scopes, ","))
+	if err != nil {
+		return err
+	}
+	return env.out.print(apiKeyCreated{APIKey: key, Secret: secret},
+		[]string{"ID", "NAME", "SCOPES", "SECRET"},
+		[][]string{{key.ID.String(), key.Name, joinScopes(key.Scopes), secret}})
+}
+
+func scopeNames() string {
+	return joinScopes(domain.APIKeyScopes)
+}
+
+func joinScopes(scopes []domain.APIKeyScope) string {
+	names := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		names = append(names, string(scope))
+	}
+	return strings.Join(names, ",")
+}
+
+func apiKeysList(ctx context.Context, env *env, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("apikeys list", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	keys, err := manageAPIKeys(ctx, env)
+	if err != nil {
+		return err
+	}
+	list, err := keys.List(ctx)
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(list))
+	for _, key := range list {
+		revoked := "-"
+		if key.RevokedAt != nil {
+			revoked = formatTime(*key.RevokedAt)
+		}
+		rows = append(rows, []string{key.ID.String(), key.Name, key.Hint + "…", joinScopes(key.Scopes), formatTime(key.CreatedAt), revoked})
+	}
+	return env.out.print(list, []string{"ID", "NAME", "HINT", "SCOPES", "CREATED", "REVOKED"}, rows)
+}
+
+func apiKeysRevoke(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("apikeys revoke", flag.ContinueOnError)
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return usageError("expected the id of the key to revoke")
+	}
+	id, err := uuid.Parse(flags.Arg(0))
+	if err != nil {
+		return usageError("invalid key id")
+	}
+
+	keys, err := manageAPIKeys(ctx, env)
+	if err != nil {
+		return err
+	}
+	if err := keys.Revoke(ctx, id); err != nil {
+		if errors.Is(err, usecase.ErrNotFound) {
+			return errors.Errorf("no api key with id %s", id)
+		}
+		return err
+	}
+	return env.out.print(map[string]string{"id": id.String(), "state": "revoked"},
+		[]string{"ID", "STATE"},
+		[][]string{{id.String(), "revoked"}})
+}
+
+func manageAPIKeys(ctx context.Context, env *env) (*usecase.ManageAPIKeys, error) {
+	db, err := env.database(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store, err := inframongorepo.NewAPIKeyRepository(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	return usecase.NewManageAPIKeys(store), nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/geoip"
+	"quotesnap/internal/infra/redaction"
+	"quotesnap/internal/infra/sink"
+)
+
+type configCheck struct {
+	Check string `json:"check"`
+	OK    bool   `json:"ok"`
+	Error string `json:"error,omitempty"`
+}
+
+// configValidate reports every problem the loader found in the settings, then the checks that
+// need the adapters: opening the GeoIP database, compiling redaction rules and building sinks. It
+// connects to no database.
+func configValidate(_ context.Context, env *env, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("config validate", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	var results []configCheck
+	var loadErrs config.Errors
+	if errors.As(env.cfgErr, &loadErrs) {
+		for _, err := range loadErrs {
+			results = append(results, configCheck{Check: "settings", Error: err.Error()})
+		}
+	} else if env.cfgErr != nil {
+		results = append(results, configCheck{Check: "settings", Error: env.cfgErr.Error()})
+	} else {
+		results = append(results, configCheck{Check: "settings", OK: true})
+	}
+
+	cfg := env.cfg
+	checks := []struct {
+		name string
+		run  func() error
+	}{
+		{"geoip", func() error {
+			if cfg.GeoIPDatabasePath == "" {
+				return nil
+			}
+			reader, err := geoip.Open(cfg.GeoIPDatabasePath, env.log)
+			if err != nil {
+				return errors.Wrap(err, "geoip_database_path")
+			}
+			return reader.Close()
+		}},
+		{"redaction rules", func() error {
+			var rules []redaction.RuleConfig
+			if cfg.RedactionRulesPath != "" {
+				var err error
+				if rules, err = redaction.LoadRules(cfg.RedactionRulesPath); err != nil {
+					return errors.Wrap(err, "redaction_rules_path")
+				}
+			}
+			_, err := redaction.NewEngine(rules, cfg.RedactionHashSalt)
+			return err
+		}},
+		{"sinks", func() error {
+			if cfg.SinksConfigPath == "" {
+				return nil
+			}
+			configs, err := sink.LoadConfigs(cfg.SinksConfigPath)
+			if err != nil {
+				return errors.Wrap(err, "sinks_config_path")
+			}
+			// Building opens file sinks, which also proves their paths are writable.
+			sinks, err := sink.Build(configs, env.redis())
+			for _, built := range sinks {
+				if closer, ok := built.(io.Closer); ok {
+					closer.Close()
+				}
+			}
+			return errors.Wrap(err, "sinks_config_path")
+		}},
+	}
+	for _, check := range checks {
+		result := configCheck{Check: check.name, OK: true}
+		if err := check.run(); err != nil {
+			result.OK, result.Error = false, err.Error()
+		}
+		results = append(results, result)
+	}
+
+	rows := make([][]string, 0, len(results))
+	failed := 0
+	for _, result := range results {
+		status := "ok"
+		if !result.OK {
+			status = "failed"
+			failed++
+		}
+		rows = append(rows, []string{result.Check, status, orDash(result.Error)})
+	}
+	if err := env.out.print(results, []string{"CHECK", "STATUS", "ERROR"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return errors.Errorf("%d of %d checks failed", failed, len(results))
+	}
+	return nil
+}
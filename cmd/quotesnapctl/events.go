@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/config"
+	infraredis "quotesnap/internal/infra/redis"
+	"quotesnap/internal/infra/repository"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+)
+
+// maxImportLine bounds one NDJSON line; events are capped well below this at ingestion.
+const maxImportLine = 4 << 20
+
+// eventsTail prints events published on the live stream until interrupted.
+func eventsTail(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("events tail", flag.ContinueOnError)
+	name := flags.String("name", "", "only print events with this name")
+	source := flags.String("source", "", "only print events from this source")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	events, err := infraredis.NewEventStream(env.redis(), env.cfg.EventStreamChannel, env.log).Subscribe(ctx)
+	if err != nil {
+		return err
+	}
+	query := usecase.EventQuery{Name: *name, Source: *source}
+	for event := range events {
+		if !query.Matches(event) {
+			continue
+		}
+		if err := env.out.line(event, formatTime(event.ReceivedAt), event.ID.String(), event.Name, event.Source, event.UserID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type transferOutput struct {
+	File   string `json:"file"`
+	Events int    `json:"events"`
+	// Skipped counts imported events dropped for erased users or missing consent.
+	Skipped int `json:"skipped,omitempty"`
+}
+
+// eventsExport writes the stored events matching the flags to an NDJSON file, or to standard
+// output when the file is "-".
+func eventsExport(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("events export", flag.ContinueOnError)
+	file := flags.String("file", "", "destination file, gzipped when it ends in .gz, or - for standard output")
+	since := flags.String("since", "", "only export events received at or after this RFC 3339 time")
+	until := flags.String("until", "", "only export events received before this RFC 3339 time")
+	name := flags.String("name", "", "only export events with this name")
+	source := flags.String("source", "", "only export events from this source")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usageError("-file is required")
+	}
+	query := usecase.EventQuery{Name: *name, Source: *source}
+	var err error
+	if query.Since, err = parseTimeFlag("since", *since); err != nil {
+		return err
+	}
+	if query.Until, err = parseTimeFlag("until", *until); err != nil {
+		return err
+	}
+
+	store, closeStore, err := openEventStore(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	w, closeFile, err := createExport(*file)
+	if err != nil {
+		return err
+	}
+	encoder := json.NewEncoder(w)
+	count := 0
+	err = store.EachEvent(ctx, query, func(event domain.Event) error {
+		if !query.Matches(event) {
+			return nil
+		}
+		count++
+		return errors.Wrap(encoder.Encode(event), "write event")
+	})
+	if closeErr := closeFile(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrapf(err, "export stopped after %d events", count)
+	}
+	if *file == "-" {
+		// Standard output carries the events; a summary would corrupt them.
+		return nil
+	}
+	return env.out.print(transferOutput{File: *file, Events: count}, []string{"FILE", "EVENTS"}, [][]string{{*file, strconv.Itoa(count)}})
+}
+
+// eventsImport stores the events of an NDJSON file, replacing stored events with the same id so
+// an interrupted import can be run again. Events go through the tombstone and consent filters of
+// ingestion, so an import cannot bring back the data of an erased user.
+func eventsImport(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("events import", flag.ContinueOnError)
+	file := flags.String("file", "", "source file, gunzipped when it ends in .gz, or - for standard input")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return usageError("-file is required")
+	}
+
+	r, closeFile, err := openImport(*file)
+	if err != nil {
+		return err
+	}
+	defer closeFile()
+
+	store, closeStore, err := openEventStore(ctx, env)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	filters, err := importFilters(ctx, env)
+	if err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), maxImportLine)
+	count, skipped, line := 0, 0, 0
+scan:
+	for scanner.Scan() {
+		line++
+		raw := strings.TrimSpace(scanner.Text())
+		if raw == "" {
+			continue
+		}
+		var event domain.Event
+		if err := json.Unmarshal([]byte(raw), &event); err != nil {
+			return errors.Wrapf(err, "line %d: decode event (imported %d events)", line, count)
+		}
+		if event.ID == uuid.Nil || event.Name == "" || event.ReceivedAt.IsZero() {
+			return errors.Errorf("line %d: event needs an id, a name and received_at (imported %d events)", line, count)
+		}
+		if err := domain.CheckOccurredAt(event.OccurredAt, event.ReceivedAt); err != nil {
+			return errors.Wrapf(err, "line %d: event %s (imported %d events)", line, event.ID, count)
+		}
+		for _, filter := range filters {
+			if err := filter.Filter(ctx, &event); err != nil {
+				if errors.Is(err, usecase.ErrEventDropped) {
+					skipped++
+					continue scan
+				}
+				return errors.Wrapf(err, "line %d: filter event %s (imported %d events)", line, event.ID, count)
+			}
+		}
+		if err := store.Upsert(ctx, event); err != nil {
+			return errors.Wrapf(err, "line %d: store event %s (imported %d events)", line, event.ID, count)
+		}
+		count++
+	}
+	if err := scanner.Err(); err != nil {
+		return errors.Wrapf(err, "read %s (imported %d events)", *file, count)
+	}
+	return env.out.print(transferOutput{File: *file, Events: count, Skipped: skipped}, []string{"FILE", "EVENTS", "SKIPPED"},
+		[][]string{{*file, strconv.Itoa(count), strconv.Itoa(skipped)}})
+}
+
+// importFilters returns the ingestion filters protecting erased users and consent choices.
+func importFilters(ctx context.Context, env *env) ([]usecase.EventFilter, error) {
+	db, err := env.database(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tombstones, err := inframongorepo.NewTombstoneRepository(ctx, db, env.log)
+	if err != nil {
+		return nil, errors.Wrap(err, "open tombstones")
+	}
+	consents := inframongorepo.NewConsentRepository(db)
+	consentFilter, err := usecase.NewConsentFilter(consents, consents, domain.ConsentAction(env.cfg.ConsentAction), env.cfg.ConsentDefault == "granted")
+	if err != nil {
+		return nil, err
+	}
+	return []usecase.EventFilter{usecase.NewTombstoneFilter(tombstones), consentFilter}, nil
+}
+
+// openEventStore opens the configured event store, connecting to MongoDB only when it holds
+// the events.
+func openEventStore(ctx context.Context, env *env) (repository.EventStore, func(), error) {
+	retention, err := env.cfg.RetentionPolicy()
+	if err != nil {
+		return nil, nil, err
+	}
+	var db *mongo.Database
+	if env.cfg.EventStore == config.EventStoreMongo {
+		if db, err = env.database(ctx); err != nil {
+			return nil, nil, err
+		}
+	}
+	store, closeStore, err := repository.NewEventStore(ctx, env.cfg, db, retention, env.log)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "open event store")
+	}
+	return store, closeStore, nil
+}
+
+func parseTimeFlag(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, usageError("-" + name + " must be an RFC 3339 time")
+	}
+	return parsed, nil
+}
+
+// createExport opens path for writing. The close function flushes compression and reports
+// write errors the file system deferred.
+func createExport(path string) (io.Writer, func() error, error) {
+	if path == "-" {
+		return os.Stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "create export file")
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, f.Close, nil
+	}
+	gz := gzip.NewWriter(f)
+	return gz, func() error {
+		if err := gz.Close(); err != nil {
+			f.Close()
+			return errors.Wrap(err, "compress export file")
+		}
+		return f.Close()
+	}, nil
+}
+
+func openImport(path string) (io.Reader, func(), error) {
+	if path == "-" {
+		return os.Stdin, func() {}, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "open import file")
+	}
+	if !strings.HasSuffix(path, ".gz") {
+		return f, func() { f.Close() }, nil
+	}
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, nil, errors.Wrap(err, "decompress import file")
+	}
+	return gz, func() {
+		gz.Close()
+		f.Close()
+	}, nil
+}
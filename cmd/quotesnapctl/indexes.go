@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+
+	"quotesnap/internal/infra/repository"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+)
+
+type indexInfo struct {
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+	Keys       bson.D `json:"keys"`
+	Unique     bool   `json:"unique,omitempty"`
+}
+
+// indexesCreate opens every store the services use. Each store creates its indexes, and the SQL
+// event stores apply their migrations, when opened. Like the worker, it also applies the retention
+// TTL of the event store; doing it here lets a deployment prepare the
+// databases before the services start.
+func indexesCreate(ctx context.Context, env *env, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("indexes create", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	retention, err := env.cfg.RetentionPolicy()
+	if err != nil {
+		return err
+	}
+	db, err := env.database(ctx)
+	if err != nil {
+		return err
+	}
+
+	events, closeEvents, err := repository.NewEventStore(ctx, env.cfg, db, retention, env.log)
+	if err != nil {
+		return errors.Wrap(err, "prepare event store")
+	}
+	err = repository.ApplyRetention(ctx, events)
+	closeEvents()
+	if err != nil {
+		return err
+	}
+	if _, err := inframongorepo.NewTombstoneRepository(ctx, db, env.log); err != nil {
+		return errors.Wrap(err, "prepare tombstones")
+	}
+	if _, err := inframongorepo.NewRollupRepository(ctx, db); err != nil {
+		return errors.Wrap(err, "prepare rollups")
+	}
+	if _, err := inframongorepo.NewWebhookRepository(ctx, db, env.cfg.WebhookDeliveryRetention, env.log); err != nil {
+		return errors.Wrap(err, "prepare webhooks")
+	}
+	if _, err := inframongorepo.NewPoisonEventRepository(ctx, db, env.cfg.PoisonEventRetention); err != nil {
+		return errors.Wrap(err, "prepare poison events")
+	}
+	if _, err := inframongorepo.NewAPIKeyRepository(ctx, db); err != nil {
+		return errors.Wrap(err, "prepare api keys")
+	}
+
+	collections, err := db.ListCollectionNames(ctx, bson.D{})
+	if err != nil {
+		return errors.Wrap(err, "list collections")
+	}
+	sort.Strings(collections)
+
+	indexes := []indexInfo{}
+	rows := [][]string{}
+	for _, collection := range collections {
+		cursor, err := db.Collection(collection).Indexes().List(ctx)
+		if err != nil {
+			return errors.Wrapf(err, "list indexes of %s", collection)
+		}
+		var specs []struct {
+			Name   string `bson:"name"`
+			Key    bson.D `bson:"key"`
+			Unique bool   `bson:"unique"`
+		}
+		if err := cursor.All(ctx, &specs); err != nil {
+			return errors.Wrapf(err, "decode indexes of %s", collection)
+		}
+		for _, spec := range specs {
+			indexes = append(indexes, indexInfo{Collection: collection, Name: spec.Name, Keys: spec.Key, Unique: spec.Unique})
+			keys := make([]string, 0, len(spec.Key))
+			for _, key := range spec.Key {
+				keys = append(keys, key.Key)
+			}
+			unique := ""
+			if spec.Unique {
+				unique = "unique"
+			}
+			rows = append(rows, []string{collection, spec.Name, strings.Join(keys, ","), orDash(unique)})
+		}
+	}
+	return env.out.print(indexes, []string{"COLLECTION", "INDEX", "KEYS", "UNIQUE"}, rows)
+}
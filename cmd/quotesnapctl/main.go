@@ -0,0 +1,202 @@
+// Command quotesnapctl performs operational tasks directly against the Redis and MongoDB instances
+// of a deployment: queue statistics and control, index creation, configuration checks, live event
+// tailing, event import and export, and admin API key management. It loads the same configuration
+// as the services, and prints tables or, with -o json, JSON.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"quotesnap/internal/infra/config"
+	inframongo "quotesnap/internal/infra/mongodb"
+	queueasynq "quotesnap/internal/infra/queue/asynq"
+	infraredis "quotesnap/internal/infra/redis"
+)
+
+// command is one "<group> <name>" subcommand.
+type command struct {
+	args    string
+	summary string
+	run     func(ctx context.Context, env *env, args []string) error
+}
+
+var commands = map[string]command{
+	"queue stats":     {"", "show the size and latency of every queue", queueStats},
+	"queue pause":     {"[queue]", "stop workers from taking tasks off a queue", queuePause},
+	"queue resume":    {"[queue]", "let workers take tasks off a paused queue again", queueResume},
+	"queue drain":     {"[-timeout d] [-interval d] [queue]", "wait until a queue has no pending, scheduled, retrying or running task", queueDrain},
+	"indexes create":  {"", "create the indexes and migrations of every store, then list the indexes", indexesCreate},
+	"config validate": {"", "check the configuration read from the environment", configValidate},
+	"events tail":     {"[-name n] [-source s]", "print events as they are ingested", eventsTail},
+	"events export":   {"-file f [-since t] [-until t] [-name n] [-source s]", "write stored events to an NDJSON file, gzipped when f ends in .gz", eventsExport},
+	"events import":   {"-file f", "store the events of an NDJSON file, replacing events with the same id", eventsImport},
+	"apikeys create":  {"-name n -scopes s,s", "issue an admin API key for some scopes and print its secret once", apiKeysCreate},
+	"apikeys list":    {"", "list admin API keys", apiKeysList},
+	"apikeys revoke":  {"<id>", "revoke an admin API key", apiKeysRevoke},
+}
+
+func main() {
+	flags := flag.NewFlagSet("quotesnapctl", flag.ContinueOnError)
+	output := flags.String("o", "table", "output format: table or json")
+	configFlags := config.RegisterFlags(flags)
+	flags.Usage = func() { usage(flags.Output()) }
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		exit(2)
+	}
+	cfg, cfgErr := configFlags.Load()
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "quotesnapctl: print configuration: %v\n", err)
+			exit(1)
+		}
+		if cfgErr != nil {
+			fmt.Fprintf(os.Stderr, "quotesnapctl: invalid configuration: %v\n", cfgErr)
+			exit(2)
+		}
+		exit(0)
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "quotesnapctl: unknown output format %q\n", *output)
+		exit(2)
+	}
+
+	args := flags.Args()
+	if len(args) < 2 {
+		usage(os.Stderr)
+		exit(2)
+	}
+	name := args[0] + " " + args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "quotesnapctl: unknown command %q\n", strings.Join(args[:2], " "))
+		usage(os.Stderr)
+		exit(2)
+	}
+
+	// config validate reports the problems itself; every other command needs a valid configuration.
+	if cfgErr != nil && name != "config validate" {
+		fmt.Fprintf(os.Stderr, "quotesnapctl: invalid configuration: %v\n", cfgErr)
+		exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	env := &env{
+		cfg:    cfg,
+		cfgErr: cfgErr,
+		out:    &printer{w: os.Stdout, json: *output == "json"},
+		log:    slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: slog.LevelWarn})),
+	}
+	err := cmd.run(ctx, env, args[2:])
+	env.close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "quotesnapctl %s %s: %v\n", args[0], args[1], err)
+		var usageErr usageError
+		if errors.As(err, &usageErr) {
+			exit(2)
+		}
+		exit(1)
+	}
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: quotesnapctl [-o table|json] [-config file] [-set key=value] [-print-config] <command> [flags]")
+	fmt.Fprintln(w, "\ncommands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := commands[name]
+		fmt.Fprintf(w, "  %s\n        %s\n", strings.TrimSpace(name+" "+cmd.args), cmd.summary)
+	}
+}
+
+// usageError reports invalid arguments, exiting with status 2.
+type usageError string
+
+func (e usageError) Error() string { return string(e) }
+
+// parseFlags parses args into flags, turning failures into usage errors.
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		return usageError(err.Error())
+	}
+	return nil
+}
+
+// env holds the configuration and the connections opened on demand by a command.
+type env struct {
+	cfg    config.Config
+	cfgErr error
+	out    *printer
+	log    *slog.Logger
+
+	mongoClient *mongo.Client
+	redisClient *redis.Client
+	inspector   *asynq.Inspector
+}
+
+// database connects to MongoDB on first use.
+func (e *env) database(ctx context.Context) (*mongo.Database, error) {
+	if e.mongoClient == nil {
+		connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		client, err := inframongo.Connect(connectCtx, e.cfg.MongoURI)
+		if err != nil {
+			return nil, errors.Wrap(err, "connect to mongodb")
+		}
+		e.mongoClient = client
+	}
+	return e.mongoClient.Database(e.cfg.MongoDatabase), nil
+}
+
+func (e *env) redis() *redis.Client {
+	if e.redisClient == nil {
+		e.redisClient = infraredis.NewClient(e.cfg.RedisAddr, e.cfg.RedisPassword)
+	}
+	return e.redisClient
+}
+
+func (e *env) asynqInspector() *asynq.Inspector {
+	if e.inspector == nil {
+		e.inspector = queueasynq.NewInspector(e.cfg.RedisAddr, e.cfg.RedisPassword)
+	}
+	return e.inspector
+}
+
+func (e *env) close() {
+	if e.inspector != nil {
+		e.inspector.Close()
+	}
+	if e.redisClient != nil {
+		e.redisClient.Close()
+	}
+	if e.mongoClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		e.mongoClient.Disconnect(ctx)
+	}
+}
+
+func exit(code int) {
+	os.Exit(code)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/queue/redisstream"
+)
+
+type queueStatsOutput struct {
+	Queues []queueInfo              `json:"queues"`
+	Stream *redisstream.StreamStats `json:"stream,omitempty"`
+}
+
+type queueInfo struct {
+	Queue          string  `json:"queue"`
+	Paused         bool    `json:"paused"`
+	Size           int     `json:"size"`
+	Pending        int     `json:"pending"`
+	Active         int     `json:"active"`
+	Scheduled      int     `json:"scheduled"`
+	Retry          int     `json:"retry"`
+	Archived       int     `json:"archived"`
+	Completed      int     `json:"completed"`
+	ProcessedToday int     `json:"processed_today"`
+	FailedToday    int     `json:"failed_today"`
+	LatencySeconds float64 `json:"latency_seconds"`
+}
+
+func newQueueInfo(info *asynq.QueueInfo) queueInfo {
+	return queueInfo{
+		Queue:          info.Queue,
+		Paused:         info.Paused,
+		Size:           info.Size,
+		Pending:        info.Pending,
+		Active:         info.Active,
+		Scheduled:      info.Scheduled,
+		Retry:          info.Retry,
+		Archived:       info.Archived,
+		Completed:      info.Completed,
+		ProcessedToday: info.Processed,
+		FailedToday:    info.Failed,
+		LatencySeconds: info.Latency.Seconds(),
+	}
+}
+
+// queueStats lists every Asynq queue, plus the ingest stream when it carries ingested events.
+func queueStats(ctx context.Context, env *env, args []string) error {
+	if err := parseFlags(flag.NewFlagSet("queue stats", flag.ContinueOnError), args); err != nil {
+		return err
+	}
+
+	inspector := env.asynqInspector()
+	names, err := inspector.Queues()
+	if err != nil {
+		return errors.Wrap(err, "list queues")
+	}
+	sort.Strings(names)
+
+	var output queueStatsOutput
+	rows := make([][]string, 0, len(names))
+	for _, name := range names {
+		info, err := inspector.GetQueueInfo(name)
+		if err != nil {
+			return errors.Wrapf(err, "read queue %s", name)
+		}
+		queue := newQueueInfo(info)
+		output.Queues = append(output.Queues, queue)
+		state := "active"
+		if queue.Paused {
+			state = "paused"
+		}
+		rows = append(rows, []string{
+			queue.Queue, state, strconv.Itoa(queue.Pending), strconv.Itoa(queue.Active), strconv.Itoa(queue.Scheduled),
+			strconv.Itoa(queue.Retry), strconv.Itoa(queue.Archived), strconv.Itoa(queue.ProcessedToday),
+			strconv.Itoa(queue.FailedToday), info.Latency.Round(time.Millisecond).String(),
+		})
+	}
+
+	if env.cfg.QueueBackend == config.QueueBackendRedisStream {
+		stats, err := redisstream.Stats(ctx, env.redis(), env.cfg.RedisStreamKey, env.cfg.RedisStreamGroup)
+		if err != nil {
+			return err
+		}
+		output.Stream = &stats
+		// Streams have no notion of scheduled or retrying entries; pending ones are being handled.
+		rows = append(rows, []string{
+			stats.Stream + " (stream)", "-", strconv.FormatInt(stats.Lag, 10), strconv.FormatInt(stats.Pending, 10), "-",
+			"-", strconv.FormatInt(stats.DeadLetters, 10), "-", "-", "-",
+		})
+	}
+
+	return env.out.print(output,
+		[]string{"QUEUE", "STATE", "PENDING", "ACTIVE", "SCHEDULED", "RETRY", "ARCHIVED", "PROCESSED", "FAILED", "LATENCY"},
+		rows)
+}
+
+type queueStateOutput struct {
+	Queue  string `json:"queue"`
+	Paused bool   `json:"paused"`
+}
+
+func queuePause(_ context.Context, env *env, args []string) error {
+	queue, err := queueArg(env, "queue pause", args)
+	if err != nil {
+		return err
+	}
+	if err := env.asynqInspector().PauseQueue(queue); err != nil {
+		return errors.Wrapf(err, "pause queue %s", queue)
+	}
+	return env.out.print(queueStateOutput{Queue: queue, Paused: true}, []string{"QUEUE", "STATE"}, [][]string{{queue, "paused"}})
+}
+
+func queueResume(_ context.Context, env *env, args []string) error {
+	queue, err := queueArg(env, "queue resume", args)
+	if err != nil {
+		return err
+	}
+	if err := env.asynqInspector().UnpauseQueue(queue); err != nil {
+		return errors.Wrapf(err, "resume queue %s", queue)
+	}
+	return env.out.print(queueStateOutput{Queue: queue, Paused: false}, []string{"QUEUE", "STATE"}, [][]string{{queue, "active"}})
+}
+
+// queueArg returns the queue named in args, the ingest queue by default.
+func queueArg(env *env, name string, args []string) (string, error) {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	if err := parseFlags(flags, args); err != nil {
+		return "", err
+	}
+	switch flags.NArg() {
+	case 0:
+		return defaultQueue(env, name)
+	case 1:
+		return flags.Arg(0), nil
+	}
+	return "", usageError("expected at most one queue name")
+}
+
+// defaultQueue returns the Asynq queue carrying ingested events. The stream backend has no
+// equivalent: ingestion cannot be paused there and Redis before 7 does not report what is left
+// to deliver, so a queue must be named explicitly.
+func defaultQueue(env *env, name string) (string, error) {
+	if env.cfg.QueueBackend == config.QueueBackendRedisStream {
+		return "", usageError(name + " does not support QUEUE_BACKEND=" + config.QueueBackendRedisStream + ", name an Asynq queue to act on it")
+	}
+	return env.cfg.AsynqQueue, nil
+}
+
+// queueDrain polls until the queue holds no outstanding task. Archived and completed tasks do not
+// count: nothing will process them.
+func queueDrain(ctx context.Context, env *env, args []string) error {
+	flags := flag.NewFlagSet("queue drain", flag.ContinueOnError)
+	timeout := flags.Duration("timeout", 10*time.Minute, "give up after this long")
+	interval := flags.Duration("interval", 2*time.Second, "time between checks")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return usageError("expected at most one queue name")
+	}
+	queue := flags.Arg(0)
+	if flags.NArg() == 0 {
+		var err error
+		if queue, err = defaultQueue(env, "queue drain"); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	inspector := env.asynqInspector()
+	for {
+		info, err := inspector.GetQueueInfo(queue)
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			info = &asynq.QueueInfo{Queue: queue}
+		} else if err != nil {
+			return errors.Wrapf(err, "read queue %s", queue)
+		}
+		if info.Paused {
+			return errors.Errorf("queue %s is paused and will never drain, resume it first", queue)
+		}
+		outstanding := info.Pending + info.Active + info.Scheduled + info.Retry
+		if outstanding == 0 {
+			return env.out.print(newQueueInfo(info), []string{"QUEUE", "STATE"}, [][]string{{queue, "drained"}})
+		}
+		if !env.out.json {
+			fmt.Fprintf(os.Stderr, "%s: %d pending, %d active, %d scheduled, %d retry\n", queue, info.Pending, info.Active, info.Scheduled, info.Retry)
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("queue %s still holds %d tasks after %s", queue, outstanding, *timeout)
+		case <-time.After(*interval):
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Command tracking-migrate copies stored events between the regular events collection and the
+// events_ts time-series collection.
+//
+// A typical switch to time-series storage runs the copy once, sets MONGO_EVENT_STORAGE=timeseries
+// on every service and worker, waits until no worker still running the old setting can write
+// (the old workers are stopped and `quotesnapctl queue drain` reports the queue empty), then runs
+// the copy again. The second run re-reads the events received within -overlap of the newest one
+// copied and inserts those still missing, so events written out of reception order or during the
+// switch are picked up; -overlap 0 rechecks every event. The source collection is left untouched
+// and can be dropped once the result is verified.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/logger"
+	inframongo "quotesnap/internal/infra/mongodb"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+)
+
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	to := flag.String("to", inframongorepo.EventStorageTimeSeries, "destination storage: timeseries or collection")
+	batchSize := flag.Int("batch", 1000, "documents copied per batch")
+	overlap := flag.Duration("overlap", 24*time.Hour, "on reruns, recheck events received this long before the newest copied one; 0 rechecks all")
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+	log := logger.New(cfg.AppName + "-migrate")
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	var from string
+	switch *to {
+	case inframongorepo.EventStorageTimeSeries:
+		from = inframongorepo.EventStorageCollection
+	case inframongorepo.EventStorageCollection:
+		from = inframongorepo.EventStorageTimeSeries
+	default:
+		log.Error("unknown -to, expected timeseries or collection", "to", *to)
+		exit(2)
+	}
+	if *batchSize <= 0 {
+		log.Error("-batch must be positive")
+		exit(2)
+	}
+	if *overlap < 0 {
+		log.Error("-overlap must not be negative")
+		exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	mongoClient, err := inframongo.Connect(connectCtx, cfg.MongoURI)
+	if err != nil {
+		log.Error("failed to connect to mongodb", "error", err)
+		exit(1)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	// The configured policy is used so opening the collections leaves their TTL indexes as the
+	// services expect them.
+	retention, err := cfg.RetentionPolicy()
+	if err != nil {
+		log.Error("invalid retention configuration", "error", err)
+		exit(1)
+	}
+
+	database := mongoClient.Database(cfg.MongoDatabase)
+	granularity := cfg.MongoTimeSeriesGranularity
+	source, err := inframongorepo.NewEventRepository(database, inframongorepo.EventStorage{Mode: from, Granularity: granularity}, retention)
+	if err != nil {
+		log.Error("failed to open source events", "storage", from, "error", err)
+		exit(1)
+	}
+	destination, err := inframongorepo.NewEventRepository(database, inframongorepo.EventStorage{Mode: *to, Granularity: granularity}, retention)
+	if err != nil {
+		log.Error("failed to open destination events", "storage", *to, "error", err)
+		exit(1)
+	}
+
+	started := time.Now()
+	copied, err := destination.CopyEventsFrom(ctx, source, *batchSize, *overlap, func(total int64) {
+		log.Info("copy progress", "copied", total)
+	})
+	if err != nil {
+		log.Error("migration failed", "copied", copied, "error", err)
+		exit(1)
+	}
+	log.Info("migration complete", "from", from, "to", *to, "copied", copied, "elapsed", time.Since(started).String())
+}
+
+func exit(code int) {
+	os.Exit(code)
+}
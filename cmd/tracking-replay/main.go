@@ -0,0 +1,170 @@
+// Command tracking-replay re-enqueues historical events from the event store or the event archive
+// on the replay queue so the worker pipeline processes them again. Events replayed from the archive
+// are stored marked as restored, which keeps archival from writing them to the archive again.
+//
+// Replayed events are only stored: they are not counted into rollups or trending scores, nor sent
+// to webhooks or sinks. After replaying into a store that lacked the events, rebuild the rollups
+// of the days they occurred with tracking-rollups once the replay queue has drained; the command
+// prints the range.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/archive"
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/logger"
+	inframongo "quotesnap/internal/infra/mongodb"
+	queueasynq "quotesnap/internal/infra/queue/asynq"
+	"quotesnap/internal/infra/repository"
+)
+
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	from := flag.String("from", "store", "event source: store (the configured EVENT_STORE) or archive")
+	since := flag.String("since", "", "replay events received at or after this RFC 3339 time")
+	until := flag.String("until", "", "replay events received before this RFC 3339 time")
+	name := flag.String("name", "", "only replay events with this name")
+	source := flag.String("source", "", "only replay events from this source")
+	metadata := flag.String("metadata", "", "only replay events whose metadata holds these string values, as key=value,key=value")
+	perSecond := flag.Int("rate", 0, "maximum events enqueued per second (default replay_rate)")
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+	log := logger.New(cfg.AppName + "-replay")
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	query := usecase.EventQuery{Name: *name, Source: *source}
+	if query.Since, err = parseTime(*since); err != nil {
+		log.Error("invalid -since", "error", err)
+		exit(2)
+	}
+	if query.Until, err = parseTime(*until); err != nil {
+		log.Error("invalid -until", "error", err)
+		exit(2)
+	}
+	if query.Metadata, err = parseMetadata(*metadata); err != nil {
+		log.Error("invalid -metadata", "error", err)
+		exit(2)
+	}
+	if *perSecond == 0 {
+		*perSecond = cfg.ReplayRate
+	}
+	if *perSecond <= 0 {
+		log.Error("-rate must be positive")
+		exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var events usecase.EventSource
+	switch *from {
+	case "store", "mongo":
+		connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+		mongoClient, err := inframongo.Connect(connectCtx, cfg.MongoURI)
+		if err != nil {
+			log.Error("failed to connect to mongodb", "error", err)
+			exit(1)
+		}
+		defer mongoClient.Disconnect(context.Background())
+
+		retention, err := cfg.RetentionPolicy()
+		if err != nil {
+			log.Error("invalid retention configuration", "error", err)
+			exit(1)
+		}
+		eventRepo, closeEvents, err := repository.NewEventStore(ctx, cfg, mongoClient.Database(cfg.MongoDatabase), retention, log)
+		if err != nil {
+			log.Error("failed to initialize event repository", "error", err)
+			exit(1)
+		}
+		defer closeEvents()
+		events = eventRepo
+	case "archive":
+		store, err := archive.NewStore(ctx, cfg.ArchiveStore, cfg.ArchiveLocalDir, archive.S3Config{
+			Endpoint:  cfg.ArchiveS3Endpoint,
+			Bucket:    cfg.ArchiveS3Bucket,
+			Prefix:    cfg.ArchiveS3Prefix,
+			AccessKey: cfg.ArchiveS3AccessKey,
+			SecretKey: cfg.ArchiveS3SecretKey,
+			UseSSL:    cfg.ArchiveS3UseSSL,
+		})
+		if err != nil {
+			log.Error("failed to initialize archive store", "error", err)
+			exit(1)
+		}
+		events = archive.NewReader(store)
+	default:
+		log.Error("unknown -from, expected store or archive", "from", *from)
+		exit(2)
+	}
+
+	asynqClient := queueasynq.NewClient(cfg.RedisAddr, cfg.RedisPassword)
+	defer asynqClient.Close()
+
+	limiter := rate.NewLimiter(rate.Limit(*perSecond), 1)
+	replay := usecase.NewReplayEvents(queueasynq.NewReplayDispatcher(asynqClient, cfg.AsynqReplayQueue), limiter)
+
+	started := time.Now()
+	result, err := replay.Execute(ctx, events, query)
+	if err != nil {
+		log.Error("replay failed", "enqueued", result.Enqueued, "skipped", result.Skipped, "error", err)
+		exit(1)
+	}
+	log.Info("replay enqueued", "from", *from, "enqueued", result.Enqueued, "skipped", result.Skipped, "queue", cfg.AsynqReplayQueue, "elapsed", time.Since(started).String())
+	if result.Enqueued > 0 {
+		log.Warn("replayed events are not counted into rollups or trending; once the replay queue has drained, rebuild the rollups of the days they occurred if the store lacked them",
+			"rebuild", "tracking-rollups -since "+result.FirstOccurred.UTC().Format(time.DateOnly)+" -until "+result.LastOccurred.UTC().AddDate(0, 0, 1).Format(time.DateOnly))
+	}
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseMetadata(value string) (map[string]string, error) {
+	if value == "" {
+		return nil, nil
+	}
+	filters := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, errors.Errorf("expected key=value, got %q", pair)
+		}
+		filters[key] = val
+	}
+	return filters, nil
+}
+
+func exit(code int) {
+	os.Exit(code)
+}
@@ -0,0 +1,97 @@
+// Command tracking-rollups recomputes the minute, hour and day event rollups from raw events.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/logger"
+	inframongo "quotesnap/internal/infra/mongodb"
+	"quotesnap/internal/infra/repository"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+)
+
+func main() {
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	since := flag.String("since", "", "first day to rebuild, YYYY-MM-DD (required)")
+	until := flag.String("until", "", "day after the last one to rebuild, YYYY-MM-DD (defaults to tomorrow)")
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
+	log := logger.New(cfg.AppName + "-rollups")
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	from, err := time.Parse(time.DateOnly, *since)
+	if err != nil {
+		log.Error("invalid or missing -since", "error", err)
+		exit(2)
+	}
+	to := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1)
+	if *until != "" {
+		if to, err = time.Parse(time.DateOnly, *until); err != nil {
+			log.Error("invalid -until", "error", err)
+			exit(2)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	mongoClient, err := inframongo.Connect(connectCtx, cfg.MongoURI)
+	if err != nil {
+		log.Error("failed to connect to mongodb", "error", err)
+		exit(1)
+	}
+	defer mongoClient.Disconnect(context.Background())
+
+	retention, err := cfg.RetentionPolicy()
+	if err != nil {
+		log.Error("invalid retention configuration", "error", err)
+		exit(1)
+	}
+
+	database := mongoClient.Database(cfg.MongoDatabase)
+	eventRepo, closeEvents, err := repository.NewEventStore(ctx, cfg, database, retention, log)
+	if err != nil {
+		log.Error("failed to initialize event repository", "error", err)
+		exit(1)
+	}
+	defer closeEvents()
+	rollups, err := inframongorepo.NewRollupRepository(ctx, database)
+	if err != nil {
+		log.Error("failed to initialize rollup repository", "error", err)
+		exit(1)
+	}
+
+	started := time.Now()
+	counted, err := usecase.NewRebuildRollups(eventRepo, rollups).Execute(ctx, from, to)
+	if err != nil {
+		log.Error("rollup rebuild failed", "counted", counted, "error", err)
+		exit(1)
+	}
+	log.Info("rollups rebuilt", "since", *since, "until", to.Format(time.DateOnly), "events", counted, "elapsed", time.Since(started).String())
+}
+
+func exit(code int) {
+	os.Exit(code)
+}
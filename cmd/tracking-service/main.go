@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log/slog"
 	"net/http"
 	"os"
@@ -12,19 +13,56 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	apphttp "quotesnap/internal/app/http"
+	"quotesnap/internal/core/domain"
 	"quotesnap/internal/core/usecase"
 	"quotesnap/internal/infra/config"
 	"quotesnap/internal/infra/logger"
+	"quotesnap/internal/infra/metrics"
 	inframongo "quotesnap/internal/infra/mongodb"
 	queueasynq "quotesnap/internal/infra/queue/asynq"
+	"quotesnap/internal/infra/queue/redisstream"
+	"quotesnap/internal/infra/redaction"
+	infraredis "quotesnap/internal/infra/redis"
+	"quotesnap/internal/infra/repository"
 	inframongorepo "quotesnap/internal/infra/repository/mongo"
+	"quotesnap/internal/infra/tracing"
 )
 
 func main() {
-	cfg := config.New()
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
 	log := logger.New(cfg.AppName)
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.AppName, cfg.TracingEndpoint)
+	if err != nil {
+		log.Error("failed to initialize tracing", "error", err)
+		exit(1)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("tracing shutdown error", "error", err)
+		}
+	}()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -42,11 +80,29 @@ func main() {
 		}
 	}()
 
+	retention, err := cfg.RetentionPolicy()
+	if err != nil {
+		log.Error("invalid retention configuration", "error", err)
+		exit(1)
+	}
+
 	database := mongoClient.Database(cfg.MongoDatabase)
-	if _, err := inframongorepo.NewEventRepository(database); err != nil {
+	eventRepo, closeEvents, err := repository.NewEventStore(ctx, cfg, database, retention, log)
+	if err != nil {
 		log.Error("failed to initialize event repository", "error", err)
 		exit(1)
 	}
+	defer closeEvents()
+
+	tombstones, err := inframongorepo.NewTombstoneRepository(ctx, database, log)
+	if err != nil {
+		log.Error("failed to initialize tombstone repository", "error", err)
+		exit(1)
+	}
+
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
+	go tombstones.Watch(runCtx, cfg.TombstoneRefreshInterval)
 
 	queueClient := queueasynq.NewClient(cfg.RedisAddr, cfg.RedisPassword)
 	defer func() {
@@ -55,11 +111,130 @@ func main() {
 		}
 	}()
 
-	dispatcher := queueasynq.NewDispatcher(queueClient, cfg.AsynqQueue)
-	ingestEvent := usecase.NewIngestEvent(dispatcher)
+	var redactionRules []redaction.RuleConfig
+	if cfg.RedactionRulesPath != "" {
+		redactionRules, err = redaction.LoadRules(cfg.RedactionRulesPath)
+		if err != nil {
+			log.Error("failed to load redaction rules", "error", err)
+			exit(1)
+		}
+	}
+	redactor, err := redaction.NewEngine(redactionRules, cfg.RedactionHashSalt)
+	if err != nil {
+		log.Error("failed to initialize redaction engine", "error", err)
+		exit(1)
+	}
+
+	consents := inframongorepo.NewConsentRepository(database)
+	consentFilter, err := usecase.NewConsentFilter(consents, consents, domain.ConsentAction(cfg.ConsentAction), cfg.ConsentDefault == "granted")
+	if err != nil {
+		log.Error("failed to initialize consent filter", "error", err)
+		exit(1)
+	}
+
+	redisClient := infraredis.NewClient(cfg.RedisAddr, cfg.RedisPassword)
+	defer redisClient.Close()
+	eventStream := infraredis.NewEventStream(redisClient, cfg.EventStreamChannel, log)
+
+	var dispatcher usecase.EventQueue
+	switch cfg.QueueBackend {
+	case config.QueueBackendAsynq:
+		dispatcher = queueasynq.NewDispatcher(queueClient, cfg.AsynqQueue)
+	case config.QueueBackendRedisStream:
+		dispatcher = redisstream.NewProducer(redisClient, cfg.RedisStreamKey, int64(cfg.RedisStreamMaxLen))
+	default:
+		log.Error("unknown queue backend", "backend", cfg.QueueBackend)
+		exit(1)
+	}
+	dispatcher = metrics.InstrumentQueue(dispatcher, cfg.QueueBackend)
+	ingestEvent := usecase.NewIngestEvent(dispatcher, eventStream, usecase.NewTombstoneFilter(tombstones), consentFilter, redactor)
 	eventHandler := apphttp.NewEventHandler(ingestEvent, cfg.RequestTimeout, log)
+	consentHandler := apphttp.NewConsentHandler(usecase.NewUpdateConsent(consents), cfg.RequestTimeout, log)
 
-	router := buildRouter(log, eventHandler)
+	poisonEvents, err := inframongorepo.NewPoisonEventRepository(ctx, database, cfg.PoisonEventRetention)
+	if err != nil {
+		log.Error("failed to initialize poison event repository", "error", err)
+		exit(1)
+	}
+	webhooks, err := inframongorepo.NewWebhookRepository(ctx, database, cfg.WebhookDeliveryRetention, log)
+	if err != nil {
+		log.Error("failed to initialize webhook repository", "error", err)
+		exit(1)
+	}
+	exportUserData := usecase.NewExportUserData(eventRepo, consents, poisonEvents, webhooks)
+	erasureDispatcher := queueasynq.NewErasureDispatcher(queueClient, cfg.AsynqQueue, cfg.ErasureDelay)
+	requestErasure := usecase.NewRequestErasure(inframongorepo.NewErasureRepository(database), tombstones, erasureDispatcher)
+
+	rollups, err := inframongorepo.NewRollupRepository(ctx, database)
+	if err != nil {
+		log.Error("failed to initialize rollup repository", "error", err)
+		exit(1)
+	}
+
+	trending, err := infraredis.NewTrendingStore(redisClient, cfg.TrendingBucket, cfg.TrendingHalfLife, cfg.TrendingMaxWindow)
+	if err != nil {
+		log.Error("failed to initialize trending store", "error", err)
+		exit(1)
+	}
+	trendingHandler := apphttp.NewTrendingHandler(usecase.NewGetTrendingQuotes(trending, cfg.TrendingMaxWindow), cfg.RequestTimeout, log)
+
+	webhookDispatcher := queueasynq.NewWebhookDispatcher(queueClient, cfg.AsynqQueue, cfg.WebhookMaxAttempts)
+	manageWebhooks := usecase.NewManageWebhooks(webhooks, webhooks, webhookDispatcher, cfg.WebhookAllowPrivate)
+
+	inspector := queueasynq.NewInspector(cfg.RedisAddr, cfg.RedisPassword)
+	defer inspector.Close()
+	// The Asynq ingest queue is kept under the stream backend too, for tasks archived before a switch.
+	deadLetterQueues := []usecase.DeadLetterQueue{
+		queueasynq.NewDeadLetterQueue(inspector, queueClient, cfg.AsynqQueue),
+		queueasynq.NewReplayDeadLetterQueue(inspector, queueClient, cfg.AsynqReplayQueue),
+	}
+	if cfg.QueueBackend == config.QueueBackendRedisStream {
+		deadLetterQueues = append(deadLetterQueues, redisstream.NewDeadLetterQueue(redisClient, cfg.RedisStreamKey))
+	}
+	deadLetters := usecase.NewManageDeadLetters(deadLetterQueues...)
+
+	apiKeys, err := inframongorepo.NewAPIKeyRepository(ctx, database)
+	if err != nil {
+		log.Error("failed to initialize api key repository", "error", err)
+		exit(1)
+	}
+	// The admin token opens every admin endpoint; API keys issued with quotesnapctl open those of
+	// the scopes they were issued with.
+	adminAuth := apphttp.NewAdminAuth(cfg.AdminAPIToken, usecase.NewManageAPIKeys(apiKeys))
+
+	adminHandlers := []adminRoutes{
+		{scope: domain.APIKeyScopeUsers, handlers: []routeRegistrar{
+			// Consent takes the user id from the path, so only trusted backends may read or change it.
+			consentHandler,
+			apphttp.NewUserDataHandler(exportUserData, requestErasure, cfg.RequestTimeout, log),
+		}},
+		{scope: domain.APIKeyScopeStats, handlers: []routeRegistrar{
+			apphttp.NewRedactionHandler(redactor),
+			apphttp.NewConsentReportHandler(usecase.NewReportSuppressions(consents), cfg.RequestTimeout, log),
+			apphttp.NewRollupHandler(usecase.NewQueryRollups(rollups), cfg.RequestTimeout, log),
+			// Workers report every SINK_HEALTH_INTERVAL; missing three reports means the worker is gone.
+			apphttp.NewSinkHandler(usecase.NewGetSinkStatuses(infraredis.NewSinkStatusStore(redisClient, 3*cfg.SinkHealthInterval), 3*cfg.SinkHealthInterval), cfg.RequestTimeout, log),
+		}},
+		{scope: domain.APIKeyScopeWebhooks, handlers: []routeRegistrar{apphttp.NewWebhookHandler(manageWebhooks, cfg.RequestTimeout, log)}},
+		{scope: domain.APIKeyScopeDeadLetters, handlers: []routeRegistrar{apphttp.NewDeadLetterHandler(deadLetters, cfg.RequestTimeout, log)}},
+	}
+	handlers := []routeRegistrar{eventHandler, trendingHandler}
+	if cfg.AdminAPIToken == "" {
+		log.Warn("ADMIN_API_TOKEN is not set, admin endpoints and the live event stream are disabled")
+		adminHandlers = nil
+	} else {
+		// The live tail exposes raw events, so it shares the admin token despite its public path.
+		handlers = append(handlers, apphttp.NewEventStreamHandler(usecase.NewTailEvents(eventStream),
+			adminAuth.Require(domain.APIKeyScopeUsers), cfg.EventStreamMaxClients, log))
+	}
+
+	checks := append([]usecase.DependencyCheck{infraredis.NewHealthCheck(redisClient), inframongo.NewHealthCheck(mongoClient)}, repository.HealthChecks(eventRepo)...)
+	health := apphttp.NewHealthHandler(usecase.NewCheckReadiness(cfg.ReadinessTimeout, checks...))
+	router, err := buildRouter(log, cfg.AppName, cfg.TrustedProxies, adminAuth, health, handlers, adminHandlers)
+	if err != nil {
+		log.Error("failed to build router", "error", err)
+		exit(1)
+	}
 
 	srv := &http.Server{
 		Addr:         cfg.HTTPAddr + ":" + cfg.HTTPPort,
@@ -68,12 +243,22 @@ func main() {
 		WriteTimeout: cfg.RequestTimeout + 2*time.Second,
 	}
 
-	errorCh := make(chan error, 1)
+	errorCh := make(chan error, 2)
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			errorCh <- err
 		}
 	}()
+	// Metrics reveal traffic and internals, so they are served apart from public traffic.
+	var internalSrv *http.Server
+	if cfg.InternalHTTPAddr != "" {
+		internalSrv = newInternalServer(cfg.InternalHTTPAddr)
+		go func() {
+			if err := internalSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errorCh <- err
+			}
+		}()
+	}
 
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
@@ -90,24 +275,67 @@ func main() {
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Error("http server shutdown error", "error", err)
 	}
+	if internalSrv != nil {
+		if err := internalSrv.Shutdown(shutdownCtx); err != nil {
+			log.Error("internal http server shutdown error", "error", err)
+		}
+	}
+}
+
+// newInternalServer serves /metrics on addr.
+func newInternalServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+	return &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+}
+
+// routeRegistrar is implemented by every HTTP handler.
+type routeRegistrar interface {
+	Register(rg *gin.RouterGroup)
+}
+
+// adminRoutes are admin handlers sharing the API key scope that opens them.
+type adminRoutes struct {
+	scope    domain.APIKeyScope
+	handlers []routeRegistrar
 }
 
-func buildRouter(log *slog.Logger, handler *apphttp.EventHandler) *gin.Engine {
+func buildRouter(log *slog.Logger, serviceName string, trustedProxies []string, adminAuth *apphttp.AdminAuth, health routeRegistrar, handlers []routeRegistrar, adminHandlers []adminRoutes) (*gin.Engine, error) {
 	gin.SetMode(gin.ReleaseMode)
 
 	r := gin.New()
+	// The client ip feeds geolocation, so forwarding headers are only believed from known proxies.
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, err
+	}
 	r.Use(gin.Recovery())
 	r.Use(gin.Logger())
 	r.Use(cors.Default())
+	r.Use(apphttp.RecordMetrics(metrics.NewHTTPRecorder()))
+	r.Use(otelgin.Middleware(serviceName))
 
+	// /healthz predates the probes and is kept for existing load balancer checks.
 	r.GET("/healthz", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{"status": "ok"})
 	})
+	health.Register(&r.RouterGroup)
 
 	api := r.Group("/api/v1")
-	handler.Register(api)
+	for _, handler := range handlers {
+		handler.Register(api)
+	}
+
+	if len(adminHandlers) > 0 {
+		admin := api.Group("/admin")
+		for _, group := range adminHandlers {
+			scoped := admin.Group("", adminAuth.Require(group.scope))
+			for _, handler := range group.handlers {
+				handler.Register(scoped)
+			}
+		}
+	}
 
-	return r
+	return r, nil
 }
 
 func exit(code int) {
@@ -3,25 +3,72 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
+	"io"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
+	"github.com/gin-gonic/gin"
 	"github.com/hibiken/asynq"
 
+	apphttp "quotesnap/internal/app/http"
 	appworker "quotesnap/internal/app/worker"
+	"quotesnap/internal/core/domain"
 	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/archive"
 	"quotesnap/internal/infra/config"
+	"quotesnap/internal/infra/geoip"
 	"quotesnap/internal/infra/logger"
+	"quotesnap/internal/infra/metrics"
 	inframongo "quotesnap/internal/infra/mongodb"
 	queueasynq "quotesnap/internal/infra/queue/asynq"
+	"quotesnap/internal/infra/queue/redisstream"
+	infraredis "quotesnap/internal/infra/redis"
+	"quotesnap/internal/infra/repository"
 	inframongorepo "quotesnap/internal/infra/repository/mongo"
+	"quotesnap/internal/infra/sink"
+	"quotesnap/internal/infra/tracing"
+	"quotesnap/internal/infra/useragent"
+	"quotesnap/internal/infra/webhook"
 )
 
 func main() {
-	cfg := config.New()
+	configFlags := config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := configFlags.Load()
 	log := logger.New(cfg.AppName + "-worker")
+	if configFlags.PrintConfig {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Error("failed to print configuration", "error", err)
+			exit(1)
+		}
+	}
+	if err != nil {
+		log.Error("invalid configuration", "error", err)
+		exit(2)
+	}
+	if configFlags.PrintConfig {
+		exit(0)
+	}
+
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.AppName+"-worker", cfg.TracingEndpoint)
+	if err != nil {
+		log.Error("failed to initialize tracing", "error", err)
+		exit(1)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(shutdownCtx); err != nil {
+			log.Error("tracing shutdown error", "error", err)
+		}
+	}()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -39,28 +86,264 @@ func main() {
 		}
 	}()
 
+	retention, err := cfg.RetentionPolicy()
+	if err != nil {
+		log.Error("invalid retention configuration", "error", err)
+		exit(1)
+	}
+
 	database := mongoClient.Database(cfg.MongoDatabase)
-	eventRepo, err := inframongorepo.NewEventRepository(database)
+	eventRepo, closeEvents, err := repository.NewEventStore(ctx, cfg, database, retention, log)
 	if err != nil {
 		log.Error("failed to initialize event repository", "error", err)
 		exit(1)
 	}
+	defer closeEvents()
+	// The worker owns retention, so only its configuration sets the TTL of the event store.
+	if err := repository.ApplyRetention(ctx, eventRepo); err != nil {
+		log.Error("failed to apply event retention", "error", err)
+		exit(1)
+	}
+
+	userAgentEnricher, err := useragent.NewEnricher(cfg.BotAction)
+	if err != nil {
+		log.Error("failed to initialize user agent enricher", "error", err)
+		exit(1)
+	}
+
+	runCtx, stopRun := context.WithCancel(context.Background())
+	defer stopRun()
+
+	var geoReader *geoip.Reader
+	if cfg.GeoIPDatabasePath != "" {
+		geoReader, err = geoip.Open(cfg.GeoIPDatabasePath, log)
+		if err != nil {
+			log.Error("failed to open geoip database", "error", err)
+			exit(1)
+		}
+		defer geoReader.Close()
+		go geoReader.Watch(runCtx, cfg.GeoIPReloadInterval)
+	}
+	geoEnricher, err := geoip.NewEnricher(geoReader, cfg.GeoIPIPMode)
+	if err != nil {
+		log.Error("failed to initialize geoip enricher", "error", err)
+		exit(1)
+	}
 
-	persistEvent := usecase.NewPersistEvent(eventRepo)
-	processor := appworker.NewEventProcessor(persistEvent, log)
+	tombstones, err := inframongorepo.NewTombstoneRepository(ctx, database, log)
+	if err != nil {
+		log.Error("failed to initialize tombstone repository", "error", err)
+		exit(1)
+	}
+	go tombstones.Watch(runCtx, cfg.TombstoneRefreshInterval)
+
+	rollups, err := inframongorepo.NewRollupRepository(ctx, database)
+	if err != nil {
+		log.Error("failed to initialize rollup repository", "error", err)
+		exit(1)
+	}
+
+	trendingWeights, err := domain.ParseTrendingWeights(cfg.TrendingWeights)
+	if err != nil {
+		log.Error("invalid trending configuration", "error", err)
+		exit(1)
+	}
+	redisClient := infraredis.NewClient(cfg.RedisAddr, cfg.RedisPassword)
+	defer redisClient.Close()
+	trending, err := infraredis.NewTrendingStore(redisClient, cfg.TrendingBucket, cfg.TrendingHalfLife, cfg.TrendingMaxWindow)
+	if err != nil {
+		log.Error("failed to initialize trending store", "error", err)
+		exit(1)
+	}
+
+	webhooks, err := inframongorepo.NewWebhookRepository(ctx, database, cfg.WebhookDeliveryRetention, log)
+	if err != nil {
+		log.Error("failed to initialize webhook repository", "error", err)
+		exit(1)
+	}
+	if err := webhooks.Refresh(ctx); err != nil {
+		log.Error("failed to load webhook subscriptions", "error", err)
+		exit(1)
+	}
+	go webhooks.Watch(runCtx, cfg.WebhookRefreshInterval)
+
+	asynqClient := queueasynq.NewClient(cfg.RedisAddr, cfg.RedisPassword)
+	defer asynqClient.Close()
+	webhookDispatcher := queueasynq.NewWebhookDispatcher(asynqClient, cfg.AsynqQueue, cfg.WebhookMaxAttempts)
+
+	var sinkConfigs []sink.Config
+	if cfg.SinksConfigPath != "" {
+		sinkConfigs, err = sink.LoadConfigs(cfg.SinksConfigPath)
+		if err != nil {
+			log.Error("failed to load sink configuration", "error", err)
+			exit(1)
+		}
+	}
+	sinks, err := sink.Build(sinkConfigs, redisClient)
+	if err != nil {
+		log.Error("failed to initialize sinks", "error", err)
+		exit(1)
+	}
+	defer closeSinks(sinks, log)
+
+	// Observer failures fail the task, which is retried; their metric tells the observers apart.
+	counted := infraredis.NewCountedEvents(redisClient, cfg.CountedEventRetention)
+	observers := []usecase.EventObserver{
+		metrics.NewPersistDelay(),
+		metrics.InstrumentObserver(usecase.NewUpdateRollups(rollups, counted), "rollups"),
+		metrics.InstrumentObserver(usecase.NewUpdateTrending(trending, counted, trendingWeights), "trending"),
+		metrics.InstrumentObserver(usecase.NewDispatchWebhooks(webhooks, webhooks, webhookDispatcher), "webhooks"),
+	}
+	if len(sinks) > 0 {
+		names := make([]string, 0, len(sinkConfigs))
+		attempts := make(map[string]int, len(sinkConfigs))
+		for _, sinkConfig := range sinkConfigs {
+			names = append(names, sinkConfig.Name)
+			attempts[sinkConfig.Name] = sinkConfig.Attempts()
+		}
+		observers = append(observers, metrics.InstrumentObserver(usecase.NewFanOutEvent(names, queueasynq.NewSinkDispatcher(asynqClient, cfg.AsynqQueue, attempts), counted), "sinks"))
+	}
+	persistEvent := usecase.NewPersistEvent(metrics.InstrumentEventRepository(eventRepo, cfg.EventStore), observers...)
+	poisonEvents, err := inframongorepo.NewPoisonEventRepository(ctx, database, cfg.PoisonEventRetention)
+	if err != nil {
+		log.Error("failed to initialize poison event repository", "error", err)
+		exit(1)
+	}
+	processor := appworker.NewEventProcessor(persistEvent, usecase.NewRecordPoisonEvent(poisonEvents), log, usecase.NewTombstoneFilter(tombstones), userAgentEnricher, geoEnricher)
+
+	eraseUserData := usecase.NewEraseUserData(inframongorepo.NewErasureRepository(database), eventRepo, inframongorepo.NewConsentRepository(database), poisonEvents, webhooks)
+	erasureProcessor := appworker.NewErasureProcessor(eraseUserData, log)
 
 	mux := asynq.NewServeMux()
+	mux.Use(metrics.AsynqMiddleware)
 	mux.Handle(queueasynq.EventIngestTaskType, processor.Handler())
+	mux.Handle(queueasynq.EventReplayTaskType, processor.Handler())
+	mux.Handle(queueasynq.UserErasureTaskType, erasureProcessor.Handler())
+	deliverWebhook := usecase.NewDeliverWebhook(webhooks, webhooks, webhook.NewSender(cfg.WebhookTimeout, cfg.WebhookAllowPrivate))
+	mux.Handle(queueasynq.WebhookDeliveryTaskType, appworker.NewWebhookProcessor(deliverWebhook, log).Handler())
+
+	if len(sinks) > 0 {
+		writeToSink := usecase.NewWriteToSink(sinks, workerID())
+		mux.Handle(queueasynq.SinkWriteTaskType, appworker.NewSinkProcessor(writeToSink, log).Handler())
+		go reportSinks(runCtx, writeToSink, infraredis.NewSinkStatusStore(redisClient, 3*cfg.SinkHealthInterval), cfg.SinkHealthInterval, log)
+		log.Info("secondary sinks enabled", "sinks", len(sinks))
+	}
 
-	server := queueasynq.NewServer(cfg.RedisAddr, cfg.RedisPassword, cfg.AsynqQueue, cfg.AsynqConcurrency, log)
+	scheduler := queueasynq.NewScheduler(cfg.RedisAddr, cfg.RedisPassword, log)
+	periodic := 0
 
-	errorCh := make(chan error, 1)
+	if eventRepo.RequiresPurge() {
+		retentionProcessor := appworker.NewRetentionProcessor(usecase.NewEnforceRetention(eventRepo), log)
+		mux.Handle(queueasynq.EventPurgeTaskType, retentionProcessor.Handler())
+		if err := schedule(scheduler, cfg.RetentionPurgeInterval, queueasynq.NewEventPurgeTask(), cfg.AsynqQueue); err != nil {
+			log.Error("failed to schedule retention purge", "error", err)
+			exit(1)
+		}
+		periodic++
+	}
+
+	archiveAge, err := cfg.ArchiveAge()
+	if err != nil {
+		log.Error("invalid archive configuration", "error", err)
+		exit(1)
+	}
+	if archiveAge > 0 {
+		archiveStore, err := archive.NewStore(ctx, cfg.ArchiveStore, cfg.ArchiveLocalDir, archive.S3Config{
+			Endpoint:  cfg.ArchiveS3Endpoint,
+			Bucket:    cfg.ArchiveS3Bucket,
+			Prefix:    cfg.ArchiveS3Prefix,
+			AccessKey: cfg.ArchiveS3AccessKey,
+			SecretKey: cfg.ArchiveS3SecretKey,
+			UseSSL:    cfg.ArchiveS3UseSSL,
+		})
+		if err != nil {
+			log.Error("failed to initialize archive store", "error", err)
+			exit(1)
+		}
+		archiveWriter, err := archive.NewWriter(archiveStore, cfg.ArchiveFormats)
+		if err != nil {
+			log.Error("failed to initialize archive writer", "error", err)
+			exit(1)
+		}
+
+		archiveEvents := usecase.NewArchiveEvents(eventRepo, archiveWriter, archiveAge, cfg.ArchiveBatchSize)
+		mux.Handle(queueasynq.EventArchiveTaskType, appworker.NewArchiveProcessor(archiveEvents, log).Handler())
+		if err := schedule(scheduler, cfg.ArchiveInterval, queueasynq.NewEventArchiveTask(cfg.ArchiveInterval), cfg.AsynqQueue); err != nil {
+			log.Error("failed to schedule event archival", "error", err)
+			exit(1)
+		}
+		periodic++
+	}
+
+	if periodic > 0 {
+		if err := scheduler.Start(); err != nil {
+			log.Error("failed to start scheduler", "error", err)
+			exit(1)
+		}
+	}
+
+	// Live traffic is weighted well above replays so a backfill never starves ingestion.
+	queues := map[string]int{cfg.AsynqQueue: 6, cfg.AsynqReplayQueue: 1}
+	server := queueasynq.NewServer(cfg.RedisAddr, cfg.RedisPassword, queues, cfg.AsynqConcurrency, log)
+
+	errorCh := make(chan error, 3)
 	go func() {
 		if err := server.Run(mux); err != nil && !errors.Is(err, asynq.ErrServerClosed) {
 			errorCh <- err
 		}
 	}()
 
+	// Replays, webhooks and maintenance stay on Asynq whichever backend carries ingested events.
+	consumerCtx, stopConsumer := context.WithCancel(context.Background())
+	consumerDone := make(chan struct{})
+	switch cfg.QueueBackend {
+	case config.QueueBackendAsynq:
+		close(consumerDone)
+	case config.QueueBackendRedisStream:
+		consumer := redisstream.NewConsumer(redisClient, redisstream.ConsumerConfig{
+			Stream:         cfg.RedisStreamKey,
+			Group:          cfg.RedisStreamGroup,
+			Name:           workerID(),
+			Concurrency:    cfg.RedisStreamConcurrency,
+			Batch:          int64(cfg.RedisStreamBatch),
+			ClaimIdle:      cfg.RedisStreamClaimIdle,
+			MaxAttempts:    cfg.RedisStreamMaxAttempts,
+			HandlerTimeout: cfg.RedisStreamClaimIdle / 2,
+		}, metrics.InstrumentEventHandler(queueasynq.EventIngestTaskType, processor.ProcessEvent), log)
+		go func() {
+			defer close(consumerDone)
+			if err := consumer.Run(consumerCtx); err != nil {
+				errorCh <- err
+			}
+		}()
+	default:
+		log.Error("unknown queue backend", "backend", cfg.QueueBackend)
+		exit(1)
+	}
+
+	// The listener serves Kubernetes probes and Prometheus scrapes; the worker has no other HTTP API.
+	var httpServer *http.Server
+	if cfg.WorkerHTTPAddr != "" {
+		inspector := queueasynq.NewInspector(cfg.RedisAddr, cfg.RedisPassword)
+		defer inspector.Close()
+		if err := metrics.Register(metrics.NewQueueCollector(inspector, cfg.AsynqQueue, cfg.AsynqReplayQueue)); err != nil {
+			log.Error("failed to register queue metrics", "error", err)
+			exit(1)
+		}
+		checks := append([]usecase.DependencyCheck{infraredis.NewHealthCheck(redisClient), inframongo.NewHealthCheck(mongoClient)}, repository.HealthChecks(eventRepo)...)
+		readiness := usecase.NewCheckReadiness(cfg.ReadinessTimeout, checks...)
+		httpServer = &http.Server{
+			Addr:              cfg.WorkerHTTPAddr,
+			Handler:           buildRouter(apphttp.NewHealthHandler(readiness)),
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				errorCh <- err
+			}
+		}()
+	}
+
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
@@ -68,10 +351,79 @@ func main() {
 	case sig := <-sigCh:
 		log.Info("shutdown signal received", "signal", sig.String())
 	case err := <-errorCh:
-		log.Error("asynq server error", "error", err)
+		log.Error("worker error", "error", err)
 	}
 
+	if periodic > 0 {
+		scheduler.Shutdown()
+	}
+	stopConsumer()
+	<-consumerDone
 	server.Shutdown()
+
+	if httpServer != nil {
+		shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+		defer cancelShutdown()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			log.Error("http server shutdown error", "error", err)
+		}
+	}
+}
+
+// buildRouter serves the probes and metrics of the worker.
+func buildRouter(health *apphttp.HealthHandler) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+	health.Register(&r.RouterGroup)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	return r
+}
+
+// schedule registers task to run every interval. Unique keeps replicas from piling up runs.
+func schedule(scheduler *asynq.Scheduler, interval time.Duration, task *asynq.Task, queue string) error {
+	_, err := scheduler.Register("@every "+interval.String(), task, asynq.Queue(queue), asynq.Unique(interval))
+	return err
+}
+
+// closeSinks closes the sinks holding open files once the worker stopped writing to them.
+func closeSinks(sinks []usecase.EventSink, log *slog.Logger) {
+	for _, s := range sinks {
+		if closer, ok := s.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				log.Warn("failed to close sink", "sink", s.Name(), "error", err)
+			}
+		}
+	}
+}
+
+// reportSinks publishes sink health every interval until ctx is done.
+func reportSinks(ctx context.Context, writeToSink *usecase.WriteToSink, store usecase.SinkStatusStore, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		checkCtx, cancel := context.WithTimeout(ctx, interval)
+		if err := writeToSink.Report(checkCtx, store); err != nil {
+			log.Warn("failed to report sink health", "error", err)
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// workerID identifies this process in sink health reports.
+func workerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return host + ":" + strconv.Itoa(os.Getpid())
 }
 
 func exit(code int) {
@@ -0,0 +1,133 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+)
+
+const reportDateLayout = "2006-01-02"
+
+// ConsentHandler lets trusted backends read and update a user's consent choices. It trusts the
+// user id in the path, so it must be registered behind admin authentication.
+type ConsentHandler struct {
+	usecase        *usecase.UpdateConsent
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewConsentHandler builds a ConsentHandler instance.
+func NewConsentHandler(uc *usecase.UpdateConsent, timeout time.Duration, logger *slog.Logger) *ConsentHandler {
+	return &ConsentHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *ConsentHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/users/:user_id/consent", h.getConsent)
+	rg.PUT("/users/:user_id/consent", h.updateConsent)
+}
+
+type updateConsentRequest struct {
+	Categories map[string]bool `json:"categories"`
+}
+
+func (h *ConsentHandler) getConsent(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	consent, err := h.usecase.Get(ctx, c.Param("user_id"))
+	if err != nil {
+		h.logger.Error("consent lookup failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
+
+func (h *ConsentHandler) updateConsent(c *gin.Context) {
+	var req updateConsentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.logger.Warn("invalid consent payload", "error", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	consent, err := h.usecase.Execute(ctx, c.Param("user_id"), req.Categories)
+	if err != nil {
+		h.logger.Error("consent update failed", "error", err)
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrValidation) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, consent)
+}
+
+// ConsentReportHandler exposes suppression counters to administrators.
+type ConsentReportHandler struct {
+	usecase        *usecase.ReportSuppressions
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewConsentReportHandler builds a ConsentReportHandler instance.
+func NewConsentReportHandler(uc *usecase.ReportSuppressions, timeout time.Duration, logger *slog.Logger) *ConsentReportHandler {
+	return &ConsentReportHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *ConsentReportHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/consent/suppressions", h.report)
+}
+
+// report returns suppression counters for ?from=YYYY-MM-DD&to=YYYY-MM-DD, defaulting to the
+// last 30 days.
+func (h *ConsentReportHandler) report(c *gin.Context) {
+	to := time.Now().UTC()
+	from := to.AddDate(0, 0, -30)
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if from, err = time.Parse(reportDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from date"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if to, err = time.Parse(reportDateLayout, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to date"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	report, err := h.usecase.Execute(ctx, from, to)
+	if err != nil {
+		h.logger.Error("suppression report failed", "error", err)
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrValidation) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	for _, row := range report {
+		total += row.Count
+	}
+	c.JSON(http.StatusOK, gin.H{"total": total, "days": report})
+}
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// defaultDeadLetterLimit bounds listings that do not ask for a limit.
+const defaultDeadLetterLimit = 100
+
+// DeadLetterHandler lets administrators inspect and act on events that exhausted their retries.
+type DeadLetterHandler struct {
+	usecase        *usecase.ManageDeadLetters
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewDeadLetterHandler builds a DeadLetterHandler instance.
+func NewDeadLetterHandler(uc *usecase.ManageDeadLetters, timeout time.Duration, logger *slog.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *DeadLetterHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/dead-letters", h.list)
+	rg.POST("/dead-letters/redrive", h.redrive)
+	rg.POST("/dead-letters/edit", h.edit)
+	rg.POST("/dead-letters/delete", h.delete)
+}
+
+type deadLetterFilterRequest struct {
+	IDs        []string `json:"ids"`
+	ErrorClass string   `json:"error_class"`
+	Queue      string   `json:"queue"`
+	All        bool     `json:"all"`
+	Limit      int      `json:"limit"`
+}
+
+func (r deadLetterFilterRequest) filter() usecase.DeadLetterFilter {
+	return usecase.DeadLetterFilter{IDs: r.IDs, ErrorClass: r.ErrorClass, Queue: r.Queue, All: r.All, Limit: r.Limit}
+}
+
+type deadLetterEditRequest struct {
+	deadLetterFilterRequest
+	Patch struct {
+		Name     *string                    `json:"name"`
+		UserID   *string                    `json:"user_id"`
+		Source   *string                    `json:"source"`
+		Metadata map[string]json.RawMessage `json:"metadata"`
+	} `json:"patch"`
+}
+
+// list returns dead letters, optionally by ?error_class= and ?queue=, newest failure first within
+// each queue, up to ?limit=.
+func (h *DeadLetterHandler) list(c *gin.Context) {
+	limit := defaultDeadLetterLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+		limit = parsed
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	deadLetters, err := h.usecase.List(ctx, usecase.DeadLetterFilter{ErrorClass: c.Query("error_class"), Queue: c.Query("queue"), Limit: limit})
+	if err != nil {
+		h.fail(c, "dead letter listing failed", 0, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"dead_letters": deadLetters})
+}
+
+func (h *DeadLetterHandler) redrive(c *gin.Context) {
+	var req deadLetterFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	count, err := h.usecase.Redrive(ctx, req.filter())
+	if err != nil {
+		h.fail(c, "dead letter redrive failed", count, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"processed": count})
+}
+
+func (h *DeadLetterHandler) edit(c *gin.Context) {
+	var req deadLetterEditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	count, err := h.usecase.Edit(ctx, req.filter(), usecase.DeadLetterPatch{
+		Name:     req.Patch.Name,
+		UserID:   req.Patch.UserID,
+		Source:   req.Patch.Source,
+		Metadata: req.Patch.Metadata,
+	})
+	if err != nil {
+		h.fail(c, "dead letter edit failed", count, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"processed": count})
+}
+
+func (h *DeadLetterHandler) delete(c *gin.Context) {
+	var req deadLetterFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	count, err := h.usecase.Delete(ctx, req.filter())
+	if err != nil {
+		h.fail(c, "dead letter deletion failed", count, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"processed": count})
+}
+
+// fail maps use case errors to a status code and writes the error response, including how many
+// dead letters a bulk action processed before failing.
+func (h *DeadLetterHandler) fail(c *gin.Context, msg string, processed int, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error(), "processed": processed})
+	case errors.Is(err, usecase.ErrValidation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "processed": processed})
+	default:
+		h.logger.Error(msg, "error", err, "processed", processed)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "processed": processed})
+	}
+}
@@ -9,10 +9,15 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
 
 	"quotesnap/internal/core/usecase"
 )
 
+var tracer = otel.Tracer("quotesnap/internal/app/http")
+
 // EventHandler wires HTTP transport with the ingest event use case.
 type EventHandler struct {
 	usecase        *usecase.IngestEvent
@@ -36,6 +41,7 @@ type createEventRequest struct {
 	Source     string         `json:"source"`
 	Metadata   map[string]any `json:"metadata"`
 	OccurredAt *time.Time     `json:"occurred_at"`
+	Consent    []string       `json:"consent"`
 }
 
 type createEventResponse struct {
@@ -44,8 +50,12 @@ type createEventResponse struct {
 }
 
 func (h *EventHandler) createEvent(c *gin.Context) {
+	spanCtx, span := tracer.Start(c.Request.Context(), "createEvent")
+	defer span.End()
+
 	var req createEventRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
+		span.SetStatus(codes.Error, "invalid payload")
 		h.logger.Warn("invalid request payload", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
 		return
@@ -63,7 +73,7 @@ func (h *EventHandler) createEvent(c *gin.Context) {
 		occurredAt = req.OccurredAt.UTC()
 	}
 
-	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	ctx, cancel := context.WithTimeout(spanCtx, h.requestTimeout)
 	defer cancel()
 
 	event, err := h.usecase.Execute(ctx, usecase.IngestEventInput{
@@ -72,17 +82,26 @@ func (h *EventHandler) createEvent(c *gin.Context) {
 		Source:     req.Source,
 		Metadata:   metadata,
 		OccurredAt: occurredAt,
+		UserAgent:  c.Request.UserAgent(),
+		IP:         c.ClientIP(),
+		Consent:    req.Consent,
 	})
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		h.logger.Error("event ingestion failed", "error", err)
 		code := http.StatusInternalServerError
-		if errors.Is(err, usecase.ErrValidation) {
+		switch {
+		case errors.Is(err, usecase.ErrValidation):
 			code = http.StatusBadRequest
+		case errors.Is(err, usecase.ErrQueueFull):
+			code = http.StatusServiceUnavailable
 		}
 		c.JSON(code, gin.H{"error": err.Error()})
 		return
 	}
 
+	span.SetAttributes(attribute.String("event.id", event.ID.String()), attribute.String("event.name", event.Name))
 	c.JSON(http.StatusAccepted, createEventResponse{ID: event.ID.String(), ReceivedAt: event.ReceivedAt})
 }
 
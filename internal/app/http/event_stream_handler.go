@@ -0,0 +1,80 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// streamHeartbeat keeps idle tails alive through proxies that close silent connections.
+const streamHeartbeat = 15 * time.Second
+
+// EventStreamHandler serves a live tail of ingested events as Server-Sent Events.
+type EventStreamHandler struct {
+	usecase    *usecase.TailEvents
+	auth       gin.HandlerFunc
+	maxClients chan struct{}
+	logger     *slog.Logger
+}
+
+// NewEventStreamHandler builds an EventStreamHandler instance. auth guards the stream, which
+// exposes raw event payloads, and at most maxClients tails are served at once.
+func NewEventStreamHandler(uc *usecase.TailEvents, auth gin.HandlerFunc, maxClients int, logger *slog.Logger) *EventStreamHandler {
+	return &EventStreamHandler{usecase: uc, auth: auth, maxClients: make(chan struct{}, maxClients), logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *EventStreamHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/events/stream", h.auth, h.stream)
+}
+
+// stream sends every event matching ?name=&source=&user_id= as an "event" SSE message until
+// the client disconnects.
+func (h *EventStreamHandler) stream(c *gin.Context) {
+	select {
+	case h.maxClients <- struct{}{}:
+		defer func() { <-h.maxClients }()
+	default:
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "too many live tails"})
+		return
+	}
+
+	filter := usecase.TailFilter{Name: c.Query("name"), Source: c.Query("source"), UserID: c.Query("user_id")}
+	events, err := h.usecase.Execute(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("event tail failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Lift the server-wide write timeout for this response only.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("failed to clear stream write deadline", "error", err)
+	}
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			c.SSEvent("event", event)
+		case <-heartbeat.C:
+			if _, err := c.Writer.WriteString(": ping\n\n"); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
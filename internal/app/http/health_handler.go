@@ -0,0 +1,41 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// HealthHandler serves the Kubernetes liveness and readiness probes.
+type HealthHandler struct {
+	usecase *usecase.CheckReadiness
+}
+
+// NewHealthHandler builds a HealthHandler instance.
+func NewHealthHandler(uc *usecase.CheckReadiness) *HealthHandler {
+	return &HealthHandler{usecase: uc}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *HealthHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/livez", h.live)
+	rg.GET("/readyz", h.ready)
+}
+
+// live reports that the process is serving requests. It never checks dependencies, so an outage
+// elsewhere does not get every replica restarted.
+func (h *HealthHandler) live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// ready reports every dependency with its probe latency, answering 503 unless all are healthy.
+func (h *HealthHandler) ready(c *gin.Context) {
+	statuses, ready := h.usecase.Execute(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "dependencies": statuses})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "dependencies": statuses})
+}
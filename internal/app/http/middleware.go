@@ -0,0 +1,153 @@
+package http
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// RequireBearerToken rejects requests whose Authorization header does not carry token.
+func RequireBearerToken(token string) gin.HandlerFunc {
+	expected := []byte(token)
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// Lookups of bearers that are neither the admin token nor a known key are bounded so that guessing
+// cannot turn into load on the key store: rejected secrets are remembered for rejectedKeyTTL, and
+// once keyLookupBurst lookups failed faster than keyLookupRate per second across all clients,
+// unknown bearers are turned away without a lookup until the budget refills. The admin token
+// keeps working throughout.
+const (
+	rejectedKeyTTL     = time.Minute
+	rejectedKeyLimit   = 10000
+	keyLookupRate      = 10
+	keyLookupBurst     = 20
+	retryAfterRejected = "1"
+)
+
+// rejectedKeys remembers the hashes of secrets recently found unknown or revoked.
+type rejectedKeys struct {
+	mu       sync.Mutex
+	expires  map[[sha256.Size]byte]time.Time
+	failures *rate.Limiter
+}
+
+func newRejectedKeys() *rejectedKeys {
+	return &rejectedKeys{
+		expires:  make(map[[sha256.Size]byte]time.Time),
+		failures: rate.NewLimiter(keyLookupRate, keyLookupBurst),
+	}
+}
+
+// known reports whether secret was rejected within rejectedKeyTTL.
+func (r *rejectedKeys) known(secret string) bool {
+	sum := sha256.Sum256([]byte(secret))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	expires, ok := r.expires[sum]
+	if ok && time.Now().After(expires) {
+		delete(r.expires, sum)
+		return false
+	}
+	return ok
+}
+
+// add remembers secret as rejected. The cache is emptied rather than grown past rejectedKeyLimit.
+func (r *rejectedKeys) add(secret string) {
+	sum := sha256.Sum256([]byte(secret))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.expires) >= rejectedKeyLimit {
+		clear(r.expires)
+	}
+	r.expires[sum] = time.Now().Add(rejectedKeyTTL)
+}
+
+// AdminAuth authenticates admin requests by the admin token, which grants every scope, or by an
+// active API key, which grants the scopes it was issued with.
+type AdminAuth struct {
+	token    []byte
+	keys     *usecase.ManageAPIKeys
+	rejected *rejectedKeys
+}
+
+// NewAdminAuth constructs an AdminAuth accepting token and the keys managed by keys.
+func NewAdminAuth(token string, keys *usecase.ManageAPIKeys) *AdminAuth {
+	return &AdminAuth{token: []byte(token), keys: keys, rejected: newRejectedKeys()}
+}
+
+// Require rejects requests whose Authorization header carries neither the admin token nor an
+// active API key granting scope.
+func (a *AdminAuth) Require(scope domain.APIKeyScope) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || provided == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(provided), a.token) == 1 {
+			c.Next()
+			return
+		}
+		if a.rejected.known(provided) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		if a.rejected.failures.Tokens() < 1 {
+			c.Header("Retry-After", retryAfterRejected)
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "too many failed authentications"})
+			return
+		}
+		key, err := a.keys.Authenticate(c.Request.Context(), provided)
+		if err != nil {
+			if errors.Is(err, usecase.ErrNotFound) {
+				a.rejected.add(provided)
+				a.rejected.failures.Allow()
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+				return
+			}
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "authentication unavailable"})
+			return
+		}
+		if !key.Allows(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "api key lacks the " + string(scope) + " scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequestRecorder records served requests. route is the matched route pattern, never the raw path.
+type RequestRecorder interface {
+	ObserveHTTPRequest(method, route string, status int, elapsed time.Duration)
+}
+
+// RecordMetrics records the count and latency of every request by method, route pattern and status.
+func RecordMetrics(recorder RequestRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		started := time.Now()
+		c.Next()
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		recorder.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(started))
+	}
+}
@@ -0,0 +1,31 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RedactionAuditor reports how many metadata values each redaction rule has rewritten.
+type RedactionAuditor interface {
+	Counters() map[string]uint64
+}
+
+// RedactionHandler exposes redaction audit counters for operators.
+type RedactionHandler struct {
+	auditor RedactionAuditor
+}
+
+// NewRedactionHandler builds a RedactionHandler instance.
+func NewRedactionHandler(auditor RedactionAuditor) *RedactionHandler {
+	return &RedactionHandler{auditor: auditor}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *RedactionHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/redaction/stats", h.stats)
+}
+
+func (h *RedactionHandler) stats(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"rules": h.auditor.Counters()})
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// RollupHandler serves pre-aggregated event counts for dashboards.
+type RollupHandler struct {
+	usecase        *usecase.QueryRollups
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewRollupHandler builds a RollupHandler instance.
+func NewRollupHandler(uc *usecase.QueryRollups, timeout time.Duration, logger *slog.Logger) *RollupHandler {
+	return &RollupHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *RollupHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/rollups", h.query)
+}
+
+// query returns rollups for ?granularity=minute|hour|day&from=RFC3339&to=RFC3339 with optional
+// name and source filters. It defaults to hourly rollups of the last 24 hours.
+func (h *RollupHandler) query(c *gin.Context) {
+	query := usecase.RollupQuery{
+		Granularity: domain.RollupGranularity(c.DefaultQuery("granularity", string(domain.RollupHour))),
+		To:          time.Now().UTC(),
+		Name:        c.Query("name"),
+		Source:      c.Query("source"),
+	}
+	query.From = query.To.Add(-24 * time.Hour)
+
+	var err error
+	if v := c.Query("from"); v != "" {
+		if query.From, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from time"})
+			return
+		}
+	}
+	if v := c.Query("to"); v != "" {
+		if query.To, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to time"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	rollups, err := h.usecase.Execute(ctx, query)
+	if err != nil {
+		h.logger.Error("rollup query failed", "error", err)
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrValidation) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	var total int64
+	for _, rollup := range rollups {
+		total += rollup.Count
+	}
+	c.JSON(http.StatusOK, gin.H{"granularity": query.Granularity, "total": total, "rollups": rollups})
+}
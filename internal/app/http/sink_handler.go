@@ -0,0 +1,47 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// SinkHandler serves the health of the secondary event sinks as reported by the workers.
+type SinkHandler struct {
+	usecase        *usecase.GetSinkStatuses
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewSinkHandler builds a SinkHandler instance.
+func NewSinkHandler(uc *usecase.GetSinkStatuses, timeout time.Duration, logger *slog.Logger) *SinkHandler {
+	return &SinkHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *SinkHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/sinks", h.statuses)
+}
+
+// statuses returns the latest report of every worker and sink, and whether all are healthy.
+func (h *SinkHandler) statuses(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	statuses, err := h.usecase.Execute(ctx)
+	if err != nil {
+		h.logger.Error("sink status query failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	healthy := true
+	for _, status := range statuses {
+		healthy = healthy && status.Healthy
+	}
+	c.JSON(http.StatusOK, gin.H{"healthy": healthy, "sinks": statuses})
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// TrendingHandler serves the trending quotes leaderboard.
+type TrendingHandler struct {
+	usecase        *usecase.GetTrendingQuotes
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewTrendingHandler builds a TrendingHandler instance.
+func NewTrendingHandler(uc *usecase.GetTrendingQuotes, timeout time.Duration, logger *slog.Logger) *TrendingHandler {
+	return &TrendingHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *TrendingHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/trending/quotes", h.quotes)
+}
+
+// quotes returns the top quotes for ?window=1h&limit=50.
+func (h *TrendingHandler) quotes(c *gin.Context) {
+	window, err := time.ParseDuration(c.DefaultQuery("window", "1h"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid window"})
+		return
+	}
+	limit := usecase.TrendingDefaultLimit
+	if v := c.Query("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	quotes, err := h.usecase.Execute(ctx, window, limit)
+	if err != nil {
+		h.logger.Error("trending query failed", "error", err)
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrValidation) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"window": window.String(), "quotes": quotes})
+}
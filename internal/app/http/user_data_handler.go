@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// UserDataHandler exposes data subject access and erasure workflows to administrators.
+type UserDataHandler struct {
+	export         *usecase.ExportUserData
+	erasure        *usecase.RequestErasure
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewUserDataHandler builds a UserDataHandler instance.
+func NewUserDataHandler(export *usecase.ExportUserData, erasure *usecase.RequestErasure, timeout time.Duration, logger *slog.Logger) *UserDataHandler {
+	return &UserDataHandler{export: export, erasure: erasure, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *UserDataHandler) Register(rg *gin.RouterGroup) {
+	rg.GET("/users/:user_id/export", h.exportUser)
+	rg.POST("/users/:user_id/erasure", h.requestErasure)
+	rg.GET("/erasures/:id", h.erasureStatus)
+}
+
+type erasureRequest struct {
+	Mode domain.ErasureMode `json:"mode"`
+}
+
+// exportUser streams a JSON archive of the user's events followed by their profile and any
+// derived data. Export can take far longer than a regular request so it is bound only by the
+// client connection.
+func (h *UserDataHandler) exportUser(c *gin.Context) {
+	userID := c.Param("user_id")
+
+	// Lift the server-wide write timeout for this response only.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("failed to clear export write deadline", "error", err)
+	}
+
+	c.Header("Content-Type", "application/json")
+	c.Header("Content-Disposition", `attachment; filename="user-export.json"`)
+	c.Status(http.StatusOK)
+
+	w := c.Writer
+	encoder := json.NewEncoder(w)
+	header, _ := json.Marshal(map[string]any{"user_id": userID, "exported_at": time.Now().UTC()})
+	_, _ = w.Write(header[:len(header)-1])
+	_, _ = w.Write([]byte(`,"events":[`))
+
+	first := true
+	export, err := h.export.Execute(c.Request.Context(), userID, func(event domain.Event) error {
+		if !first {
+			if _, err := w.Write([]byte(",")); err != nil {
+				return err
+			}
+		}
+		first = false
+		return encoder.Encode(event)
+	})
+	if err != nil {
+		// Headers are already sent; stop here so the client receives an unterminated archive.
+		h.logger.Error("user export failed", "user_id", userID, "error", err)
+		return
+	}
+
+	_, _ = w.Write([]byte(`],"profile":`))
+	_ = encoder.Encode(export.Profile)
+	_, _ = w.Write([]byte(`,"sections":`))
+	_ = encoder.Encode(export.Sections)
+	_, _ = w.Write([]byte("}"))
+}
+
+func (h *UserDataHandler) requestErasure(c *gin.Context) {
+	var req erasureRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	request, err := h.erasure.Execute(ctx, c.Param("user_id"), req.Mode)
+	if err != nil {
+		h.logger.Error("erasure request failed", "error", err)
+		code := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrValidation) {
+			code = http.StatusBadRequest
+		}
+		c.JSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, request)
+}
+
+func (h *UserDataHandler) erasureStatus(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	request, err := h.erasure.Status(ctx, id)
+	if err != nil {
+		if errors.Is(err, usecase.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "erasure request not found"})
+			return
+		}
+		h.logger.Error("erasure status lookup failed", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, request)
+}
@@ -0,0 +1,192 @@
+package http
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// WebhookHandler lets administrators manage webhook subscriptions and their deliveries.
+type WebhookHandler struct {
+	usecase        *usecase.ManageWebhooks
+	requestTimeout time.Duration
+	logger         *slog.Logger
+}
+
+// NewWebhookHandler builds a WebhookHandler instance.
+func NewWebhookHandler(uc *usecase.ManageWebhooks, timeout time.Duration, logger *slog.Logger) *WebhookHandler {
+	return &WebhookHandler{usecase: uc, requestTimeout: timeout, logger: logger}
+}
+
+// Register attaches handler endpoints to the provided router group.
+func (h *WebhookHandler) Register(rg *gin.RouterGroup) {
+	rg.POST("/webhooks", h.create)
+	rg.GET("/webhooks", h.list)
+	rg.GET("/webhooks/:id", h.get)
+	rg.PUT("/webhooks/:id", h.update)
+	rg.DELETE("/webhooks/:id", h.delete)
+	rg.GET("/webhooks/:id/deliveries", h.deliveries)
+	rg.POST("/webhooks/deliveries/:id/redeliver", h.redeliver)
+}
+
+type webhookSubscriptionRequest struct {
+	EventName string `json:"event_name"`
+	Source    string `json:"source"`
+	URL       string `json:"url"`
+	Active    *bool  `json:"active"`
+}
+
+func (r webhookSubscriptionRequest) input() usecase.WebhookSubscriptionInput {
+	return usecase.WebhookSubscriptionInput{EventName: r.EventName, Source: r.Source, URL: r.URL, Active: r.Active}
+}
+
+// create registers a subscription and returns its signing secret, which is never shown again.
+func (h *WebhookHandler) create(c *gin.Context) {
+	var req webhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	sub, err := h.usecase.Create(ctx, req.input())
+	if err != nil {
+		h.fail(c, "webhook creation failed", err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub, "secret": sub.Secret})
+}
+
+func (h *WebhookHandler) list(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	subs, err := h.usecase.List(ctx)
+	if err != nil {
+		h.fail(c, "webhook listing failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subs})
+}
+
+func (h *WebhookHandler) get(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	sub, err := h.usecase.Get(ctx, id)
+	if err != nil {
+		h.fail(c, "webhook lookup failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) update(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+	var req webhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid payload"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	sub, err := h.usecase.Update(ctx, id, req.input())
+	if err != nil {
+		h.fail(c, "webhook update failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) delete(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	if err := h.usecase.Delete(ctx, id); err != nil {
+		h.fail(c, "webhook deletion failed", err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// deliveries lists the newest deliveries of a subscription, optionally by ?status=.
+func (h *WebhookHandler) deliveries(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	deliveries, err := h.usecase.Deliveries(ctx, id, domain.WebhookDeliveryStatus(c.Query("status")))
+	if err != nil {
+		h.fail(c, "webhook delivery listing failed", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"deliveries": deliveries})
+}
+
+func (h *WebhookHandler) redeliver(c *gin.Context) {
+	id, ok := parseID(c)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.requestTimeout)
+	defer cancel()
+
+	delivery, err := h.usecase.Redeliver(ctx, id)
+	if err != nil {
+		h.fail(c, "webhook redelivery failed", err)
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// fail maps use case errors to a status code and writes the error response.
+func (h *WebhookHandler) fail(c *gin.Context, msg string, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+	case errors.Is(err, usecase.ErrValidation):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		h.logger.Error(msg, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid id"})
+		return uuid.Nil, false
+	}
+	return id, true
+}
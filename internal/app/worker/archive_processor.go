@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+	queueinfra "quotesnap/internal/infra/queue/asynq"
+)
+
+// ArchiveProcessor consumes periodic archival tasks.
+type ArchiveProcessor struct {
+	usecase *usecase.ArchiveEvents
+	logger  *slog.Logger
+}
+
+// NewArchiveProcessor constructs an ArchiveProcessor instance.
+func NewArchiveProcessor(usecase *usecase.ArchiveEvents, logger *slog.Logger) *ArchiveProcessor {
+	return &ArchiveProcessor{usecase: usecase, logger: logger.With("component", "archive_processor")}
+}
+
+// Handler returns an Asynq handler function.
+func (p *ArchiveProcessor) Handler() asynq.Handler {
+	return asynq.HandlerFunc(p.ProcessTask)
+}
+
+// ProcessTask archives aged events. Batches completed before a failure stay archived and deleted.
+func (p *ArchiveProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != queueinfra.EventArchiveTaskType {
+		return errors.Errorf("unexpected task type: %s", task.Type())
+	}
+
+	result, err := p.usecase.Execute(ctx)
+	if err != nil {
+		p.logger.Error("event archival failed", "batches", result.Batches, "archived", result.Archived, "deleted", result.Deleted, "error", err)
+		return err
+	}
+
+	p.logger.Info("event archival completed", "batches", result.Batches, "archived", result.Archived, "deleted", result.Deleted)
+	return nil
+}
@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+	queueinfra "quotesnap/internal/infra/queue/asynq"
+)
+
+// ErasureProcessor consumes user erasure tasks.
+type ErasureProcessor struct {
+	usecase *usecase.EraseUserData
+	logger  *slog.Logger
+}
+
+// NewErasureProcessor constructs an ErasureProcessor instance.
+func NewErasureProcessor(usecase *usecase.EraseUserData, logger *slog.Logger) *ErasureProcessor {
+	return &ErasureProcessor{usecase: usecase, logger: logger.With("component", "erasure_processor")}
+}
+
+// Handler returns an Asynq handler function.
+func (p *ErasureProcessor) Handler() asynq.Handler {
+	return asynq.HandlerFunc(p.ProcessTask)
+}
+
+// ProcessTask executes the erasure request referenced by the task payload.
+func (p *ErasureProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != queueinfra.UserErasureTaskType {
+		return errors.Errorf("unexpected task type: %s", task.Type())
+	}
+
+	requestID, err := queueinfra.DecodeUserErasure(task)
+	if err != nil {
+		p.logger.Warn("failed to decode erasure payload", "error", err)
+		return errors.Wrap(err, "decode erasure payload")
+	}
+
+	if err := p.usecase.Execute(ctx, requestID); err != nil {
+		p.logger.Error("user erasure failed", "request_id", requestID, "error", err)
+		return err
+	}
+
+	p.logger.Info("user erasure completed", "request_id", requestID)
+	return nil
+}
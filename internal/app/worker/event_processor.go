@@ -2,24 +2,37 @@ package worker
 
 import (
 	"context"
+	"encoding/json"
 	"log/slog"
 
 	"github.com/hibiken/asynq"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 
+	"quotesnap/internal/core/domain"
 	"quotesnap/internal/core/usecase"
 	queueinfra "quotesnap/internal/infra/queue/asynq"
 )
 
-// EventProcessor consumes tracking event tasks and persists them via the provided use case.
+var tracer = otel.Tracer("quotesnap/internal/app/worker")
+
+// EventProcessor consumes tracking event tasks, runs them through the enrichment chain and
+// persists them via the provided use case. Permanent failures are not retried; their raw payload
+// is kept as a poison event instead.
 type EventProcessor struct {
-	usecase *usecase.PersistEvent
-	logger  *slog.Logger
+	usecase   *usecase.PersistEvent
+	poison    *usecase.RecordPoisonEvent
+	enrichers []usecase.EventEnricher
+	logger    *slog.Logger
 }
 
-// NewEventProcessor constructs an EventProcessor instance.
-func NewEventProcessor(usecase *usecase.PersistEvent, logger *slog.Logger) *EventProcessor {
-	return &EventProcessor{usecase: usecase, logger: logger.With("component", "event_processor")}
+// NewEventProcessor constructs an EventProcessor instance. Enrichers run in the given order.
+// poison may be nil when there is nowhere to keep poison events.
+func NewEventProcessor(usecase *usecase.PersistEvent, poison *usecase.RecordPoisonEvent, logger *slog.Logger, enrichers ...usecase.EventEnricher) *EventProcessor {
+	return &EventProcessor{usecase: usecase, poison: poison, enrichers: enrichers, logger: logger.With("component", "event_processor")}
 }
 
 // Handler returns an Asynq handler function.
@@ -27,22 +40,114 @@ func (p *EventProcessor) Handler() asynq.Handler {
 	return asynq.HandlerFunc(p.ProcessTask)
 }
 
-// ProcessTask persists the event contained in the task payload.
+// ProcessTask enriches and persists the event contained in the task payload. Replayed events
+// overwrite their stored copy instead of being inserted again.
 func (p *EventProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
-	if task.Type() != queueinfra.EventIngestTaskType {
+	replay := task.Type() == queueinfra.EventReplayTaskType
+	if task.Type() != queueinfra.EventIngestTaskType && !replay {
 		return errors.Errorf("unexpected task type: %s", task.Type())
 	}
 
-	event, err := queueinfra.DecodeEvent(task)
+	ctx, event, err := queueinfra.DecodeEvent(ctx, task)
 	if err != nil {
 		p.logger.Warn("failed to decode event payload", "error", err)
-		return errors.Wrap(err, "decode event payload")
+		return p.skipRetry(ctx, task, usecase.Permanent(errors.Wrap(err, "decode event payload")))
+	}
+
+	ctx, span := tracer.Start(ctx, "ProcessTask", trace.WithSpanKind(trace.SpanKindConsumer), trace.WithAttributes(
+		attribute.String("messaging.system", "asynq"),
+		attribute.String("messaging.operation.name", task.Type()),
+		attribute.String("event.id", event.ID.String()),
+		attribute.String("event.name", event.Name),
+	))
+	defer span.End()
+	if err := p.process(ctx, event, replay); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		if usecase.IsPermanent(err) {
+			return p.skipRetry(ctx, task, err)
+		}
+		// Asynq keeps only the message of the last error, so it carries the class dead letters
+		// are grouped by.
+		return errors.Wrap(err, domain.ErrorClassTag(usecase.ClassifyError(err)))
+	}
+	return nil
+}
+
+// ProcessEvent enriches and persists an ingested event delivered by a transport other than Asynq.
+// Permanent failures are recorded as poison events and returned for the transport to give up on.
+func (p *EventProcessor) ProcessEvent(ctx context.Context, event domain.Event) error {
+	err := p.process(ctx, event, false)
+	if err != nil && usecase.IsPermanent(err) {
+		payload, encodeErr := json.Marshal(event)
+		if encodeErr != nil {
+			return err
+		}
+		p.recordPoison(ctx, queueinfra.EventIngestTaskType, payload, err)
 	}
+	return err
+}
+
+// skipRetry records the task as a poison event and tells Asynq to archive it without retrying,
+// with the error message tagged by its class.
+func (p *EventProcessor) skipRetry(ctx context.Context, task *asynq.Task, err error) error {
+	p.recordPoison(ctx, task.Type(), task.Payload(), err)
+	return errors.Wrap(asynq.SkipRetry, domain.ErrorClassTag(usecase.ClassifyError(err))+": "+err.Error())
+}
 
+// recordPoison keeps payload for inspection. A failure is only logged: the task stays in the
+// transport's own dead-letter storage either way.
+func (p *EventProcessor) recordPoison(ctx context.Context, taskType string, payload []byte, cause error) {
+	p.logger.Error("event failed permanently", "type", taskType, "error", cause)
+	if p.poison == nil {
+		return
+	}
+	if err := p.poison.Execute(ctx, taskType, payload, cause); err != nil {
+		p.logger.Error("failed to record poison event", "type", taskType, "error", err)
+	}
+}
+
+func (p *EventProcessor) process(ctx context.Context, event domain.Event, replay bool) error {
+	if err := p.enrich(ctx, &event); err != nil {
+		if errors.Is(err, usecase.ErrEventDropped) {
+			p.logger.Info("event dropped during enrichment", "event_id", event.ID, "reason", err)
+			return nil
+		}
+		return err
+	}
+
+	if replay {
+		if err := p.usecase.Replay(ctx, event); err != nil {
+			p.logger.Error("failed to persist replayed event", "event_id", event.ID, "error", err)
+			return err
+		}
+		return nil
+	}
+
+	// A failed observer fails the task too, so a webhook delivery or sink write that could not be
+	// scheduled is retried; observers skip what they already did for the event.
 	if err := p.usecase.Execute(ctx, event); err != nil {
+		if errors.Is(err, usecase.ErrObserverFailed) {
+			p.logger.Warn("event persisted but observers failed", "event_id", event.ID, "error", err)
+			return err
+		}
 		p.logger.Error("failed to persist event", "event_id", event.ID, "error", err)
 		return err
 	}
 
 	return nil
 }
+
+// enrich applies every enricher in order. Failures other than ErrEventDropped are logged and
+// skipped so that a misbehaving enricher never blocks persistence.
+func (p *EventProcessor) enrich(ctx context.Context, event *domain.Event) error {
+	for _, enricher := range p.enrichers {
+		if err := enricher.Enrich(ctx, event); err != nil {
+			if errors.Is(err, usecase.ErrEventDropped) {
+				return err
+			}
+			p.logger.Warn("event enrichment failed", "event_id", event.ID, "error", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+	queueinfra "quotesnap/internal/infra/queue/asynq"
+)
+
+// RetentionProcessor consumes periodic retention purge tasks.
+type RetentionProcessor struct {
+	usecase *usecase.EnforceRetention
+	logger  *slog.Logger
+}
+
+// NewRetentionProcessor constructs a RetentionProcessor instance.
+func NewRetentionProcessor(usecase *usecase.EnforceRetention, logger *slog.Logger) *RetentionProcessor {
+	return &RetentionProcessor{usecase: usecase, logger: logger.With("component", "retention_processor")}
+}
+
+// Handler returns an Asynq handler function.
+func (p *RetentionProcessor) Handler() asynq.Handler {
+	return asynq.HandlerFunc(p.ProcessTask)
+}
+
+// ProcessTask purges expired events.
+func (p *RetentionProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != queueinfra.EventPurgeTaskType {
+		return errors.Errorf("unexpected task type: %s", task.Type())
+	}
+
+	deleted, err := p.usecase.Execute(ctx)
+	if err != nil {
+		p.logger.Error("retention purge failed", "deleted", deleted, "error", err)
+		return err
+	}
+
+	p.logger.Info("retention purge completed", "deleted", deleted)
+	return nil
+}
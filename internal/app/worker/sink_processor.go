@@ -0,0 +1,49 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+	queueinfra "quotesnap/internal/infra/queue/asynq"
+)
+
+// SinkProcessor consumes secondary sink write tasks.
+type SinkProcessor struct {
+	usecase *usecase.WriteToSink
+	logger  *slog.Logger
+}
+
+// NewSinkProcessor constructs a SinkProcessor instance.
+func NewSinkProcessor(usecase *usecase.WriteToSink, logger *slog.Logger) *SinkProcessor {
+	return &SinkProcessor{usecase: usecase, logger: logger.With("component", "sink_processor")}
+}
+
+// Handler returns an Asynq handler function.
+func (p *SinkProcessor) Handler() asynq.Handler {
+	return asynq.HandlerFunc(p.ProcessTask)
+}
+
+// ProcessTask writes the event to the sink named in the payload. Failures are returned so Asynq
+// retries this sink alone.
+func (p *SinkProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != queueinfra.SinkWriteTaskType {
+		return errors.Errorf("unexpected task type: %s", task.Type())
+	}
+
+	sink, event, err := queueinfra.DecodeSinkWrite(task)
+	if err != nil {
+		p.logger.Warn("failed to decode sink write payload", "error", err)
+		return errors.Wrap(err, "decode sink write payload")
+	}
+
+	if err := p.usecase.Execute(ctx, sink, event); err != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		p.logger.Warn("sink write failed", "sink", sink, "event_id", event.ID, "attempt", retried+1, "error", err)
+		return err
+	}
+	return nil
+}
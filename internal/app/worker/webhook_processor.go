@@ -0,0 +1,52 @@
+package worker
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+	queueinfra "quotesnap/internal/infra/queue/asynq"
+)
+
+// WebhookProcessor consumes webhook delivery tasks.
+type WebhookProcessor struct {
+	usecase *usecase.DeliverWebhook
+	logger  *slog.Logger
+}
+
+// NewWebhookProcessor constructs a WebhookProcessor instance.
+func NewWebhookProcessor(usecase *usecase.DeliverWebhook, logger *slog.Logger) *WebhookProcessor {
+	return &WebhookProcessor{usecase: usecase, logger: logger.With("component", "webhook_processor")}
+}
+
+// Handler returns an Asynq handler function.
+func (p *WebhookProcessor) Handler() asynq.Handler {
+	return asynq.HandlerFunc(p.ProcessTask)
+}
+
+// ProcessTask attempts the delivery referenced by the task payload. Failed attempts are returned
+// so Asynq retries them with backoff until the task runs out of retries.
+func (p *WebhookProcessor) ProcessTask(ctx context.Context, task *asynq.Task) error {
+	if task.Type() != queueinfra.WebhookDeliveryTaskType {
+		return errors.Errorf("unexpected task type: %s", task.Type())
+	}
+
+	deliveryID, err := queueinfra.DecodeWebhookDelivery(task)
+	if err != nil {
+		p.logger.Warn("failed to decode webhook delivery payload", "error", err)
+		return errors.Wrap(err, "decode webhook delivery payload")
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	final := retried >= maxRetry
+
+	if err := p.usecase.Execute(ctx, deliveryID, final); err != nil {
+		p.logger.Warn("webhook delivery attempt failed", "delivery_id", deliveryID, "attempt", retried+1, "final", final, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "time"
+
+const (
+	// ArchiveFormatNDJSON stores events as gzip-compressed newline-delimited JSON.
+	ArchiveFormatNDJSON = "ndjson.gz"
+	// ArchiveFormatParquet stores events as a zstd-compressed Parquet file.
+	ArchiveFormatParquet = "parquet"
+	// ArchivePartitionLayout names the daily partition an archived event belongs to.
+	ArchivePartitionLayout = "2006-01-02"
+)
+
+// ArchiveFile describes one file written for an archive batch.
+type ArchiveFile struct {
+	Key     string `json:"key"`
+	Format  string `json:"format"`
+	Size    int64  `json:"size"`
+	SHA256  string `json:"sha256"`
+	Records int    `json:"records"`
+}
+
+// ArchiveManifest records the content and checksums of an archive batch. A batch is only
+// considered durable once its manifest has been written.
+type ArchiveManifest struct {
+	BatchID       string        `json:"batch_id"`
+	Partition     string        `json:"partition"`
+	CreatedAt     time.Time     `json:"created_at"`
+	EventCount    int           `json:"event_count"`
+	MinReceivedAt time.Time     `json:"min_received_at"`
+	MaxReceivedAt time.Time     `json:"max_received_at"`
+	Files         []ArchiveFile `json:"files"`
+}
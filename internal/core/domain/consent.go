@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// AnonymousUserID replaces the user id of events anonymized for lack of consent.
+	AnonymousUserID = "anonymous"
+	// ConsentCategoryLimit bounds the number of consent categories attached to an event.
+	ConsentCategoryLimit = 16
+)
+
+// ConsentAction selects what happens to events whose categories the user has not consented to.
+type ConsentAction string
+
+const (
+	ConsentActionDrop      ConsentAction = "drop"
+	ConsentActionAnonymize ConsentAction = "anonymize"
+)
+
+// Consent captures the categories a user has granted or refused.
+type Consent struct {
+	UserID     string          `json:"user_id"`
+	Categories map[string]bool `json:"categories"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Allows reports whether category is granted, falling back to defaultGranted when the user
+// has not expressed a choice for it.
+func (c Consent) Allows(category string, defaultGranted bool) bool {
+	granted, ok := c.Categories[category]
+	if !ok {
+		return defaultGranted
+	}
+	return granted
+}
+
+// SuppressionCount reports how many events were suppressed for a category on a given day.
+type SuppressionCount struct {
+	Day      string        `json:"day"`
+	Category string        `json:"category"`
+	Action   ConsentAction `json:"action"`
+	Count    int64         `json:"count"`
+}
+
+// ValidateConsentCategories checks a list of consent category names.
+func ValidateConsentCategories(categories []string) error {
+	if len(categories) > ConsentCategoryLimit {
+		return errors.Errorf("at most %d consent categories are allowed", ConsentCategoryLimit)
+	}
+	for _, category := range categories {
+		if category == "" {
+			return errors.New("consent category must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// DeadLetter is an ingested or replayed event whose processing failed on every attempt. Queue
+// names the dead-letter queue holding it.
+type DeadLetter struct {
+	ID           string    `json:"id"`
+	Queue        string    `json:"queue"`
+	Event        Event     `json:"event"`
+	LastError    string    `json:"last_error"`
+	ErrorClass   string    `json:"error_class"`
+	Retried      int       `json:"retried"`
+	MaxRetry     int       `json:"max_retry"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+}
+
+// Error classes group dead letters that are likely to share a fix.
+const (
+	ErrorClassTimeout    = "timeout"
+	ErrorClassConnection = "connection"
+	ErrorClassDuplicate  = "duplicate"
+	ErrorClassDecode     = "decode"
+	ErrorClassValidation = "validation"
+	ErrorClassOther      = "other"
+)
+
+// errorClassTag prefixes failure messages with their class, for transports that only keep the
+// message of the last error.
+const errorClassTag = "error_class="
+
+// ErrorClassTag returns the prefix marking a failure message as belonging to class.
+func ErrorClassTag(class string) string {
+	return errorClassTag + class
+}
+
+// ErrorClassOf returns the class a message was tagged with by ErrorClassTag. Untagged messages,
+// such as those of failures recorded before classes were tagged, are ErrorClassOther.
+func ErrorClassOf(message string) string {
+	rest, ok := strings.CutPrefix(message, errorClassTag)
+	if !ok {
+		return ErrorClassOther
+	}
+	class, _, _ := strings.Cut(rest, ":")
+	return class
+}
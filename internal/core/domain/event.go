@@ -2,7 +2,9 @@ package domain
 
 import (
 	"encoding/json"
+	"net"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -11,6 +13,13 @@ import (
 const (
 	// EventMetadataLimit enforces an upper bound on metadata payload sizes (32KB).
 	EventMetadataLimit = 32 * 1024
+	// EventUserAgentLimit bounds the raw User-Agent header stored alongside an event.
+	EventUserAgentLimit = 512
+	// EventMaxAge bounds how long before its reception an event may have occurred, which covers
+	// clients buffering events offline.
+	EventMaxAge = 30 * 24 * time.Hour
+	// EventMaxClockSkew bounds how far after its reception an event may claim to have occurred.
+	EventMaxClockSkew = 24 * time.Hour
 )
 
 // Event captures the canonical representation of a tracking event within the domain.
@@ -22,6 +31,31 @@ type Event struct {
 	Metadata   json.RawMessage `json:"metadata"`
 	OccurredAt time.Time       `json:"occurred_at"`
 	ReceivedAt time.Time       `json:"received_at"`
+	UserAgent  string          `json:"user_agent,omitempty"`
+	IP         string          `json:"ip,omitempty"`
+	Device     *Device         `json:"device,omitempty"`
+	Geo        *Geo            `json:"geo,omitempty"`
+	Consent    []string        `json:"consent,omitempty"`
+	// Restored marks events replayed from the archive. They are already archived, so archival
+	// leaves them to retention.
+	Restored bool `json:"restored,omitempty"`
+}
+
+// Device describes the client derived from the raw User-Agent during enrichment.
+type Device struct {
+	Browser        string `json:"browser,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	OS             string `json:"os,omitempty"`
+	OSVersion      string `json:"os_version,omitempty"`
+	Type           string `json:"type,omitempty"`
+	Bot            bool   `json:"bot"`
+}
+
+// Geo describes the location resolved from the client IP during enrichment.
+type Geo struct {
+	Country string `json:"country,omitempty"`
+	Region  string `json:"region,omitempty"`
+	City    string `json:"city,omitempty"`
 }
 
 // NewEvent validates input parameters and returns a fully populated Event aggregate.
@@ -43,12 +77,14 @@ func NewEvent(name, userID, source string, metadata json.RawMessage, occurredAt
 		return Event{}, errors.Errorf("metadata must be <= %d bytes", EventMetadataLimit)
 	}
 
+	received := time.Now().UTC()
 	occurred := occurredAt.UTC()
 	if occurred.IsZero() {
-		occurred = time.Now().UTC()
+		occurred = received
+	}
+	if err := CheckOccurredAt(occurred, received); err != nil {
+		return Event{}, err
 	}
-
-	received := time.Now().UTC()
 
 	return Event{
 		ID:         uuid.New(),
@@ -60,3 +96,49 @@ func NewEvent(name, userID, source string, metadata json.RawMessage, occurredAt
 		ReceivedAt: received,
 	}, nil
 }
+
+// CheckOccurredAt rejects an occurrence time outside [received-EventMaxAge,
+// received+EventMaxClockSkew]. Stores partition, index and encode times assuming they are close to
+// the reception time, so client clocks must not pick arbitrary years.
+func CheckOccurredAt(occurred, received time.Time) error {
+	if occurred.Before(received.Add(-EventMaxAge)) {
+		return errors.Errorf("occurred_at must be at most %s before reception", EventMaxAge)
+	}
+	if occurred.After(received.Add(EventMaxClockSkew)) {
+		return errors.Errorf("occurred_at must be at most %s after reception", EventMaxClockSkew)
+	}
+	return nil
+}
+
+// SetUserAgent records the raw client User-Agent, truncated to at most EventUserAgentLimit bytes
+// without splitting a multi-byte character.
+func (e *Event) SetUserAgent(userAgent string) {
+	if len(userAgent) > EventUserAgentLimit {
+		cut := EventUserAgentLimit
+		for cut > 0 && !utf8.RuneStart(userAgent[cut]) {
+			cut--
+		}
+		userAgent = userAgent[:cut]
+	}
+	e.UserAgent = userAgent
+}
+
+// SetIP records the client IP address, ignoring values that do not parse as an IP.
+func (e *Event) SetIP(ip string) {
+	if net.ParseIP(ip) == nil {
+		return
+	}
+	e.IP = ip
+}
+
+// Anonymize removes every attribute that identifies the user behind the event. Metadata is
+// free-form and often carries identifiers, so it is cleared as well.
+func (e *Event) Anonymize() {
+	e.UserID = AnonymousUserID
+	e.Metadata = json.RawMessage("{}")
+	e.UserAgent = ""
+	e.IP = ""
+	if e.Geo != nil {
+		e.Geo = &Geo{Country: e.Geo.Country}
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// DependencyStatus reports whether one external dependency answered a readiness probe.
+type DependencyStatus struct {
+	Name      string  `json:"name"`
+	Healthy   bool    `json:"healthy"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PoisonEvent keeps the raw payload of a task that failed permanently, for later inspection. UserID
+// is read from the payload when it decodes, so erasure can find the payload.
+type PoisonEvent struct {
+	ID         uuid.UUID `json:"id"`
+	TaskType   string    `json:"task_type"`
+	UserID     string    `json:"user_id,omitempty"`
+	Payload    []byte    `json:"payload"`
+	Error      string    `json:"error"`
+	ErrorClass string    `json:"error_class"`
+	FailedAt   time.Time `json:"failed_at"`
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// RetentionFieldName scopes a retention rule to an event name.
+	RetentionFieldName = "name"
+	// RetentionFieldSource scopes a retention rule to an event source.
+	RetentionFieldSource = "source"
+)
+
+// RetentionRule overrides the default retention for events matching Field == Value.
+type RetentionRule struct {
+	Field string
+	Value string
+	TTL   time.Duration
+}
+
+// RetentionPolicy describes how long events are kept. A zero Default keeps events forever.
+// Name rules take precedence over source rules, which take precedence over Default.
+type RetentionPolicy struct {
+	Default   time.Duration
+	Overrides []RetentionRule
+}
+
+// Uniform reports whether every event shares the same retention, which lets storage rely on a
+// native TTL mechanism instead of a purge job.
+func (p RetentionPolicy) Uniform() bool {
+	return len(p.Overrides) == 0
+}
+
+// Enabled reports whether any event can ever expire.
+func (p RetentionPolicy) Enabled() bool {
+	return p.Default > 0 || len(p.Overrides) > 0
+}
+
+// Shortest returns the smallest non-zero retention of the policy, or zero when events never expire.
+func (p RetentionPolicy) Shortest() time.Duration {
+	shortest := p.Default
+	for _, rule := range p.Overrides {
+		if shortest == 0 || rule.TTL < shortest {
+			shortest = rule.TTL
+		}
+	}
+	return shortest
+}
+
+// Longest returns the largest retention of the policy. The boolean is false when some events never
+// expire.
+func (p RetentionPolicy) Longest() (time.Duration, bool) {
+	if p.Default <= 0 {
+		return 0, false
+	}
+	longest := p.Default
+	for _, rule := range p.Overrides {
+		if rule.TTL <= 0 {
+			return 0, false
+		}
+		if rule.TTL > longest {
+			longest = rule.TTL
+		}
+	}
+	return longest, true
+}
+
+// ParseRetentionOverrides parses a comma separated list of field:value=ttl entries, for example
+// "name:quote_viewed=30d,source:web=2160h". TTLs accept Go durations or a whole number of days.
+func ParseRetentionOverrides(spec string) ([]RetentionRule, error) {
+	var rules []RetentionRule
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		selector, ttlSpec, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, errors.Errorf("retention override %q: missing =ttl", entry)
+		}
+		field, value, ok := strings.Cut(selector, ":")
+		if !ok || value == "" {
+			return nil, errors.Errorf("retention override %q: expected field:value", entry)
+		}
+		if field != RetentionFieldName && field != RetentionFieldSource {
+			return nil, errors.Errorf("retention override %q: unknown field %q", entry, field)
+		}
+		ttl, err := ParseRetentionTTL(ttlSpec)
+		if err != nil {
+			return nil, errors.Wrapf(err, "retention override %q", entry)
+		}
+		if ttl <= 0 {
+			return nil, errors.Errorf("retention override %q: ttl must be positive", entry)
+		}
+		rules = append(rules, RetentionRule{Field: field, Value: value, TTL: ttl})
+	}
+	return rules, nil
+}
+
+// ParseRetentionTTL parses a Go duration or a whole number of days such as "90d".
+func ParseRetentionTTL(spec string) (time.Duration, error) {
+	spec = strings.TrimSpace(spec)
+	if days, ok := strings.CutSuffix(spec, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, errors.Errorf("invalid ttl %q", spec)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	ttl, err := time.ParseDuration(spec)
+	if err != nil {
+		return 0, errors.Errorf("invalid ttl %q", spec)
+	}
+	return ttl, nil
+}
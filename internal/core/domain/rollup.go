@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RollupGranularity is the width of a rollup bucket.
+type RollupGranularity string
+
+const (
+	RollupMinute RollupGranularity = "minute"
+	RollupHour   RollupGranularity = "hour"
+	RollupDay    RollupGranularity = "day"
+)
+
+// RollupGranularities lists every maintained granularity from finest to coarsest.
+var RollupGranularities = []RollupGranularity{RollupMinute, RollupHour, RollupDay}
+
+// ParseRollupGranularity validates a granularity name.
+func ParseRollupGranularity(value string) (RollupGranularity, error) {
+	for _, g := range RollupGranularities {
+		if string(g) == value {
+			return g, nil
+		}
+	}
+	return "", errors.Errorf("unknown rollup granularity %q", value)
+}
+
+// Duration returns the width of one bucket.
+func (g RollupGranularity) Duration() time.Duration {
+	switch g {
+	case RollupMinute:
+		return time.Minute
+	case RollupHour:
+		return time.Hour
+	default:
+		return 24 * time.Hour
+	}
+}
+
+// Truncate returns the start of the UTC bucket containing t.
+func (g RollupGranularity) Truncate(t time.Time) time.Time {
+	return t.UTC().Truncate(g.Duration())
+}
+
+// Rollup counts the events with a given name and source that occurred within one bucket.
+type Rollup struct {
+	Granularity RollupGranularity `json:"granularity"`
+	Bucket      time.Time         `json:"bucket"`
+	Name        string            `json:"name"`
+	Source      string            `json:"source"`
+	Count       int64             `json:"count"`
+}
+
+// EventRollups returns a single-event rollup per granularity, keyed by the event's occurrence time.
+func EventRollups(event Event) []Rollup {
+	rollups := make([]Rollup, 0, len(RollupGranularities))
+	for _, g := range RollupGranularities {
+		rollups = append(rollups, Rollup{
+			Granularity: g,
+			Bucket:      g.Truncate(event.OccurredAt),
+			Name:        event.Name,
+			Source:      event.Source,
+			Count:       1,
+		})
+	}
+	return rollups
+}
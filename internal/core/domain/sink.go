@@ -0,0 +1,19 @@
+package domain
+
+import "time"
+
+// SinkStatus reports the health of one secondary event sink as seen by one worker.
+type SinkStatus struct {
+	Sink                string    `json:"sink"`
+	Kind                string    `json:"kind"`
+	Worker              string    `json:"worker"`
+	Healthy             bool      `json:"healthy"`
+	CheckError          string    `json:"check_error,omitempty"`
+	Written             int64     `json:"written"`
+	Failed              int64     `json:"failed"`
+	ConsecutiveFailures int64     `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastFailureAt       time.Time `json:"last_failure_at"`
+	ReportedAt          time.Time `json:"reported_at"`
+}
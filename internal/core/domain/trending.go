@@ -0,0 +1,36 @@
+package domain
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// TrendingQuote is a quote ranked by its time-decayed activity score.
+type TrendingQuote struct {
+	QuoteID string  `json:"quote_id"`
+	Score   float64 `json:"score"`
+}
+
+// ParseTrendingWeights parses a comma separated list of event=weight entries, for example
+// "quote_viewed=1,quote_shared=5". Events without a weight do not affect trending scores.
+func ParseTrendingWeights(spec string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, errors.Errorf("trending weight %q: expected event=weight", entry)
+		}
+		weight, err := strconv.ParseFloat(value, 64)
+		if err != nil || weight <= 0 {
+			return nil, errors.Errorf("trending weight %q: weight must be a positive number", entry)
+		}
+		weights[name] = weight
+	}
+	return weights, nil
+}
@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// ErasureMode selects how a user's documents are removed.
+type ErasureMode string
+
+const (
+	// ErasureModeDelete removes every document belonging to the user.
+	ErasureModeDelete ErasureMode = "delete"
+	// ErasureModeAnonymize keeps documents for aggregate reporting but strips identifying fields.
+	ErasureModeAnonymize ErasureMode = "anonymize"
+)
+
+// ErasureStatus tracks the lifecycle of an erasure request.
+type ErasureStatus string
+
+const (
+	ErasureStatusPending   ErasureStatus = "pending"
+	ErasureStatusRunning   ErasureStatus = "running"
+	ErasureStatusCompleted ErasureStatus = "completed"
+	ErasureStatusFailed    ErasureStatus = "failed"
+)
+
+// ErasureRequest records a data subject erasure request and its progress.
+type ErasureRequest struct {
+	ID          uuid.UUID        `json:"id"`
+	UserID      string           `json:"user_id"`
+	Mode        ErasureMode      `json:"mode"`
+	Status      ErasureStatus    `json:"status"`
+	Affected    map[string]int64 `json:"affected,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	RequestedAt time.Time        `json:"requested_at"`
+	UpdatedAt   time.Time        `json:"updated_at"`
+}
+
+// NewErasureRequest validates input parameters and returns a pending ErasureRequest.
+func NewErasureRequest(userID string, mode ErasureMode) (ErasureRequest, error) {
+	if userID == "" {
+		return ErasureRequest{}, errors.New("user_id is required")
+	}
+	if mode == "" {
+		mode = ErasureModeDelete
+	}
+	if mode != ErasureModeDelete && mode != ErasureModeAnonymize {
+		return ErasureRequest{}, errors.Errorf("unknown erasure mode %q", mode)
+	}
+
+	now := time.Now().UTC()
+	return ErasureRequest{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Mode:        mode,
+		Status:      ErasureStatusPending,
+		RequestedAt: now,
+		UpdatedAt:   now,
+	}, nil
+}
+
+// UserProfile summarises what is stored about a user across their events.
+type UserProfile struct {
+	UserID     string    `json:"user_id"`
+	EventCount int64     `json:"event_count"`
+	FirstSeen  time.Time `json:"first_seen,omitempty"`
+	LastSeen   time.Time `json:"last_seen,omitempty"`
+	Sources    []string  `json:"sources"`
+	EventNames []string  `json:"event_names"`
+}
@@ -0,0 +1,211 @@
+package domain
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+)
+
+// WebhookSubscription pushes events matching EventName and Source to URL. An empty filter field
+// matches every value. Secret signs every delivery and is only revealed when the subscription
+// is created.
+type WebhookSubscription struct {
+	ID        uuid.UUID `json:"id"`
+	EventName string    `json:"event_name"`
+	Source    string    `json:"source,omitempty"`
+	URL       string    `json:"url"`
+	Secret    string    `json:"-"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NewWebhookSubscription validates input parameters and returns an active subscription with a
+// freshly generated secret.
+func NewWebhookSubscription(eventName, source, target string) (WebhookSubscription, error) {
+	sub := WebhookSubscription{ID: uuid.New(), Active: true}
+	if err := sub.SetFilter(eventName, source, target); err != nil {
+		return WebhookSubscription{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return WebhookSubscription{}, errors.Wrap(err, "generate webhook secret")
+	}
+	sub.Secret = hex.EncodeToString(secret)
+	sub.CreatedAt = time.Now().UTC()
+	sub.UpdatedAt = sub.CreatedAt
+	return sub, nil
+}
+
+// SetFilter validates and applies the event filter and target URL.
+func (s *WebhookSubscription) SetFilter(eventName, source, target string) error {
+	if eventName == "" {
+		return errors.New("event_name is required")
+	}
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	s.EventName, s.Source, s.URL = eventName, source, target
+	s.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// internalPrefixes are address ranges beyond those the netip predicates cover that never belong to
+// a public webhook endpoint: "this network", shared carrier-grade NAT, benchmarking, reserved and
+// site-local space.
+var internalPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("fec0::/10"),
+}
+
+// PublicWebhookAddr reports whether ip may receive webhook deliveries. Loopback, private,
+// link-local (which holds cloud metadata endpoints), multicast and reserved addresses are internal
+// to the deployment and refused.
+func PublicWebhookAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsValid() || ip.IsUnspecified() || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, prefix := range internalPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckPublicWebhookURL rejects a subscription URL whose host is a localhost name or an address
+// PublicWebhookAddr refuses. Names resolving to such addresses are refused when delivering.
+func CheckPublicWebhookURL(target string) error {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return errors.New("url must not point to localhost")
+	}
+	if ip, err := netip.ParseAddr(host); err == nil && !PublicWebhookAddr(ip.WithZone("")) {
+		return errors.New("url must not point to a loopback, private, link-local or reserved address")
+	}
+	return nil
+}
+
+// Matches reports whether event should be delivered to the subscription.
+func (s WebhookSubscription) Matches(event Event) bool {
+	return s.Active && s.EventName == event.Name && (s.Source == "" || s.Source == event.Source)
+}
+
+// WebhookDeliveryStatus tracks the lifecycle of a webhook delivery.
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryRetrying  WebhookDeliveryStatus = "retrying"
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed"
+)
+
+// WebhookPayload is the body of every delivery. It carries only what identifies the event and its
+// user: metadata, client details and location never leave the service. UserID is already the
+// anonymous id for events the consent filter anonymized.
+type WebhookPayload struct {
+	ID         uuid.UUID `json:"id"`
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	UserID     string    `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// WebhookDelivery records the attempts made to push one event to one subscription. Payload is
+// the exact body sent, kept so failed deliveries can be redelivered. UserID lets erasure find the
+// deliveries of a user.
+type WebhookDelivery struct {
+	ID             uuid.UUID             `json:"id"`
+	SubscriptionID uuid.UUID             `json:"subscription_id"`
+	EventID        uuid.UUID             `json:"event_id"`
+	EventName      string                `json:"event_name"`
+	UserID         string                `json:"-"`
+	Payload        json.RawMessage       `json:"-"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	Attempts       int                   `json:"attempts"`
+	LastStatusCode int                   `json:"last_status_code,omitempty"`
+	LastError      string                `json:"last_error,omitempty"`
+	RedeliveryOf   *uuid.UUID            `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// NewWebhookDelivery returns a pending delivery of event to sub, carrying its WebhookPayload. Its
+// id is derived from the subscription and event ids, so the same event always yields the same
+// delivery.
+func NewWebhookDelivery(sub WebhookSubscription, event Event) (WebhookDelivery, error) {
+	payload, err := json.Marshal(WebhookPayload{
+		ID:         event.ID,
+		Name:       event.Name,
+		Source:     event.Source,
+		UserID:     event.UserID,
+		OccurredAt: event.OccurredAt,
+		ReceivedAt: event.ReceivedAt,
+	})
+	if err != nil {
+		return WebhookDelivery{}, errors.Wrap(err, "marshal webhook payload")
+	}
+	now := time.Now().UTC()
+	return WebhookDelivery{
+		ID:             uuid.NewSHA1(sub.ID, event.ID[:]),
+		SubscriptionID: sub.ID,
+		EventID:        event.ID,
+		EventName:      event.Name,
+		UserID:         event.UserID,
+		Payload:        payload,
+		Status:         WebhookDeliveryPending,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}, nil
+}
+
+// Redeliver returns a new pending delivery carrying the same payload as d.
+func (d WebhookDelivery) Redeliver() WebhookDelivery {
+	now := time.Now().UTC()
+	original := d.ID
+	return WebhookDelivery{
+		ID:             uuid.New(),
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventName:      d.EventName,
+		UserID:         d.UserID,
+		Payload:        d.Payload,
+		Status:         WebhookDeliveryPending,
+		RedeliveryOf:   &original,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// SignWebhook returns the signature of a delivery body sent at timestamp: the hex HMAC-SHA256 of
+// "<unix timestamp>.<body>" keyed with the subscription secret, prefixed with "sha256=".
+func SignWebhook(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// APIKeyStore persists API keys. Lookups of unknown keys return ErrNotFound.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key domain.APIKey) error
+	ListAPIKeys(ctx context.Context) ([]domain.APIKey, error)
+	FindAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error
+}
+
+// ManageAPIKeys issues, lists, revokes and authenticates admin API keys.
+type ManageAPIKeys struct {
+	store APIKeyStore
+}
+
+// NewManageAPIKeys constructs a ManageAPIKeys use case instance.
+func NewManageAPIKeys(store APIKeyStore) *ManageAPIKeys {
+	return &ManageAPIKeys{store: store}
+}
+
+// Create issues a key named name granting scopes and returns it with its secret, which is never
+// shown again.
+func (uc *ManageAPIKeys) Create(ctx context.Context, name string, scopes []string) (domain.APIKey, string, error) {
+	granted := make([]domain.APIKeyScope, 0, len(scopes))
+	for _, value := range scopes {
+		scope, err := domain.ParseAPIKeyScope(strings.TrimSpace(value))
+		if err != nil {
+			return domain.APIKey{}, "", validationError(err.Error())
+		}
+		if !slices.Contains(granted, scope) {
+			granted = append(granted, scope)
+		}
+	}
+	key, secret, err := domain.NewAPIKey(strings.TrimSpace(name), granted)
+	if err != nil {
+		return domain.APIKey{}, "", validationError(err.Error())
+	}
+	if err := uc.store.CreateAPIKey(ctx, key); err != nil {
+		return domain.APIKey{}, "", errors.Wrap(err, "create api key")
+	}
+	return key, secret, nil
+}
+
+// List returns every key, revoked ones included.
+func (uc *ManageAPIKeys) List(ctx context.Context) ([]domain.APIKey, error) {
+	keys, err := uc.store.ListAPIKeys(ctx)
+	return keys, errors.Wrap(err, "list api keys")
+}
+
+// Revoke disables the key immediately. Revoking a revoked key keeps its original revocation time.
+func (uc *ManageAPIKeys) Revoke(ctx context.Context, id uuid.UUID) error {
+	return errors.Wrap(uc.store.RevokeAPIKey(ctx, id, time.Now().UTC()), "revoke api key")
+}
+
+// Authenticate returns the active key matching secret, or ErrNotFound.
+func (uc *ManageAPIKeys) Authenticate(ctx context.Context, secret string) (domain.APIKey, error) {
+	key, err := uc.store.FindAPIKeyByHash(ctx, domain.HashAPIKey(secret))
+	if err != nil {
+		return domain.APIKey{}, errors.Wrap(err, "find api key")
+	}
+	if !key.Active() {
+		return domain.APIKey{}, errors.Wrap(ErrNotFound, "api key revoked")
+	}
+	return key, nil
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// ArchivableEventStore exposes aged events for archival and deletes them once archived.
+type ArchivableEventStore interface {
+	EventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) ([]domain.Event, error)
+	DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+// EventArchiveWriter durably writes a batch of events belonging to one daily partition. It must
+// only return once every file and the manifest have been written and verified.
+type EventArchiveWriter interface {
+	WriteBatch(ctx context.Context, partition string, events []domain.Event) (domain.ArchiveManifest, error)
+}
+
+// ArchiveResult summarises an archival run.
+type ArchiveResult struct {
+	Batches  int
+	Archived int
+	Deleted  int64
+}
+
+// ArchiveEvents moves events older than a cutoff from primary storage to cold storage. Archived
+// events keep their personal data and are out of reach of user erasure, which only covers
+// primary storage; archives must expire through the lifecycle rules of their store.
+type ArchiveEvents struct {
+	store     ArchivableEventStore
+	writer    EventArchiveWriter
+	olderThan time.Duration
+	batchSize int
+}
+
+// NewArchiveEvents constructs an ArchiveEvents use case instance.
+func NewArchiveEvents(store ArchivableEventStore, writer EventArchiveWriter, olderThan time.Duration, batchSize int) *ArchiveEvents {
+	return &ArchiveEvents{store: store, writer: writer, olderThan: olderThan, batchSize: batchSize}
+}
+
+// Execute archives batches until no event older than the cutoff remains or ctx is done. Source
+// events are deleted only after their batch has been written and verified.
+func (uc *ArchiveEvents) Execute(ctx context.Context) (ArchiveResult, error) {
+	var result ArchiveResult
+	cutoff := time.Now().UTC().Add(-uc.olderThan)
+
+	for ctx.Err() == nil {
+		events, err := uc.store.EventsReceivedBefore(ctx, cutoff, uc.batchSize)
+		if err != nil {
+			return result, errors.Wrap(err, "load events to archive")
+		}
+		if len(events) == 0 {
+			return result, nil
+		}
+
+		for _, partition := range partitionByDay(events) {
+			manifest, err := uc.writer.WriteBatch(ctx, partition.day, partition.events)
+			if err != nil {
+				return result, errors.Wrapf(err, "archive partition %s", partition.day)
+			}
+
+			ids := make([]uuid.UUID, 0, len(partition.events))
+			for _, event := range partition.events {
+				ids = append(ids, event.ID)
+			}
+			deleted, err := uc.store.DeleteEvents(ctx, ids)
+			result.Deleted += deleted
+			if err != nil {
+				return result, errors.Wrapf(err, "delete archived batch %s", manifest.BatchID)
+			}
+			result.Batches++
+			result.Archived += manifest.EventCount
+		}
+
+		if len(events) < uc.batchSize {
+			return result, nil
+		}
+	}
+	return result, ctx.Err()
+}
+
+type dayPartition struct {
+	day    string
+	events []domain.Event
+}
+
+// partitionByDay groups events by the UTC day they were received, preserving order.
+func partitionByDay(events []domain.Event) []dayPartition {
+	var partitions []dayPartition
+	for _, event := range events {
+		day := event.ReceivedAt.UTC().Format(domain.ArchivePartitionLayout)
+		if n := len(partitions); n > 0 && partitions[n-1].day == day {
+			partitions[n-1].events = append(partitions[n-1].events, event)
+			continue
+		}
+		partitions = append(partitions, dayPartition{day: day, events: []domain.Event{event}})
+	}
+	return partitions
+}
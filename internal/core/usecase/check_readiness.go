@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"quotesnap/internal/core/domain"
+)
+
+// DependencyCheck probes one external dependency.
+type DependencyCheck interface {
+	Name() string
+	Ping(ctx context.Context) error
+}
+
+// CheckReadiness probes every dependency a process needs to do useful work.
+type CheckReadiness struct {
+	checks  []DependencyCheck
+	timeout time.Duration
+}
+
+// NewCheckReadiness constructs a CheckReadiness use case instance. Each probe is bounded by
+// timeout so one hung dependency cannot stall the others.
+func NewCheckReadiness(timeout time.Duration, checks ...DependencyCheck) *CheckReadiness {
+	return &CheckReadiness{checks: checks, timeout: timeout}
+}
+
+// Execute probes every dependency concurrently, returning their statuses in registration order
+// and whether all are healthy.
+func (uc *CheckReadiness) Execute(ctx context.Context) ([]domain.DependencyStatus, bool) {
+	statuses := make([]domain.DependencyStatus, len(uc.checks))
+	var wg sync.WaitGroup
+	for i, check := range uc.checks {
+		wg.Add(1)
+		go func(i int, check DependencyCheck) {
+			defer wg.Done()
+			checkCtx, cancel := context.WithTimeout(ctx, uc.timeout)
+			defer cancel()
+
+			started := time.Now()
+			err := check.Ping(checkCtx)
+			statuses[i] = domain.DependencyStatus{
+				Name:      check.Name(),
+				Healthy:   err == nil,
+				LatencyMS: float64(time.Since(started).Microseconds()) / 1000,
+			}
+			if err != nil {
+				statuses[i].Error = err.Error()
+			}
+		}(i, check)
+	}
+	wg.Wait()
+
+	ready := true
+	for _, status := range statuses {
+		ready = ready && status.Healthy
+	}
+	return statuses, ready
+}
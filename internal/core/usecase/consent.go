@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// ConsentStore persists per-user consent state.
+type ConsentStore interface {
+	GetConsent(ctx context.Context, userID string) (domain.Consent, bool, error)
+	SaveConsent(ctx context.Context, consent domain.Consent) error
+}
+
+// SuppressionRecorder counts events suppressed for lack of consent.
+type SuppressionRecorder interface {
+	RecordSuppression(ctx context.Context, category string, action domain.ConsentAction) error
+	SuppressionReport(ctx context.Context, from, to time.Time) ([]domain.SuppressionCount, error)
+}
+
+// ConsentFilter drops or anonymizes events whose consent categories the user has not granted.
+type ConsentFilter struct {
+	consents       ConsentStore
+	recorder       SuppressionRecorder
+	action         domain.ConsentAction
+	defaultGranted bool
+}
+
+// NewConsentFilter constructs a ConsentFilter. defaultGranted applies to categories the user has
+// never expressed a choice for.
+func NewConsentFilter(consents ConsentStore, recorder SuppressionRecorder, action domain.ConsentAction, defaultGranted bool) (*ConsentFilter, error) {
+	if action != domain.ConsentActionDrop && action != domain.ConsentActionAnonymize {
+		return nil, errors.Errorf("unknown consent action %q", action)
+	}
+	return &ConsentFilter{consents: consents, recorder: recorder, action: action, defaultGranted: defaultGranted}, nil
+}
+
+// Filter enforces consent for events that declare at least one category.
+func (f *ConsentFilter) Filter(ctx context.Context, event *domain.Event) error {
+	if len(event.Consent) == 0 {
+		return nil
+	}
+
+	consent, _, err := f.consents.GetConsent(ctx, event.UserID)
+	if err != nil {
+		return errors.Wrap(err, "load consent")
+	}
+
+	denied := ""
+	for _, category := range event.Consent {
+		if !consent.Allows(category, f.defaultGranted) {
+			denied = category
+			break
+		}
+	}
+	if denied == "" {
+		return nil
+	}
+
+	if err := f.recorder.RecordSuppression(ctx, denied, f.action); err != nil {
+		return errors.Wrap(err, "record suppression")
+	}
+	if f.action == domain.ConsentActionDrop {
+		return errors.Wrapf(ErrEventDropped, "no consent for %s", denied)
+	}
+	event.Anonymize()
+	return nil
+}
+
+// Ensure ConsentFilter satisfies the EventFilter dependency.
+var _ EventFilter = (*ConsentFilter)(nil)
+
+// UpdateConsent records consent choices made by a user.
+type UpdateConsent struct {
+	consents ConsentStore
+}
+
+// NewUpdateConsent constructs an UpdateConsent use case instance.
+func NewUpdateConsent(consents ConsentStore) *UpdateConsent {
+	return &UpdateConsent{consents: consents}
+}
+
+// Execute merges categories into the user's stored consent and returns the result.
+func (uc *UpdateConsent) Execute(ctx context.Context, userID string, categories map[string]bool) (domain.Consent, error) {
+	if userID == "" {
+		return domain.Consent{}, validationError("user_id is required")
+	}
+	if len(categories) == 0 {
+		return domain.Consent{}, validationError("categories are required")
+	}
+	names := make([]string, 0, len(categories))
+	for name := range categories {
+		names = append(names, name)
+	}
+	if err := domain.ValidateConsentCategories(names); err != nil {
+		return domain.Consent{}, validationError(err.Error())
+	}
+
+	consent, found, err := uc.consents.GetConsent(ctx, userID)
+	if err != nil {
+		return domain.Consent{}, errors.Wrap(err, "load consent")
+	}
+	if !found {
+		consent = domain.Consent{UserID: userID, Categories: make(map[string]bool, len(categories))}
+	}
+	for name, granted := range categories {
+		consent.Categories[name] = granted
+	}
+	consent.UpdatedAt = time.Now().UTC()
+
+	if err := uc.consents.SaveConsent(ctx, consent); err != nil {
+		return domain.Consent{}, errors.Wrap(err, "save consent")
+	}
+	return consent, nil
+}
+
+// Get returns the user's stored consent, which is empty when they never expressed a choice.
+func (uc *UpdateConsent) Get(ctx context.Context, userID string) (domain.Consent, error) {
+	consent, found, err := uc.consents.GetConsent(ctx, userID)
+	if err != nil {
+		return domain.Consent{}, errors.Wrap(err, "load consent")
+	}
+	if !found {
+		consent = domain.Consent{UserID: userID, Categories: map[string]bool{}}
+	}
+	return consent, nil
+}
+
+// ReportSuppressions summarises events suppressed for lack of consent.
+type ReportSuppressions struct {
+	recorder SuppressionRecorder
+}
+
+// NewReportSuppressions constructs a ReportSuppressions use case instance.
+func NewReportSuppressions(recorder SuppressionRecorder) *ReportSuppressions {
+	return &ReportSuppressions{recorder: recorder}
+}
+
+// Execute returns per-day suppression counts between from and to, inclusive.
+func (uc *ReportSuppressions) Execute(ctx context.Context, from, to time.Time) ([]domain.SuppressionCount, error) {
+	if to.Before(from) {
+		return nil, validationError("to must not be before from")
+	}
+	report, err := uc.recorder.SuppressionReport(ctx, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "load suppression report")
+	}
+	return report, nil
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// DeadLetterQueue holds events whose processing exhausted its retries.
+type DeadLetterQueue interface {
+	// Name identifies the queue in the dead letters it holds.
+	Name() string
+	EachDeadLetter(ctx context.Context, fn func(domain.DeadLetter) error) error
+	// RedriveDeadLetter queues the dead letter for processing again, unchanged.
+	RedriveDeadLetter(ctx context.Context, id string) error
+	// ReplaceDeadLetter queues event for processing in place of the dead letter.
+	ReplaceDeadLetter(ctx context.Context, id string, event domain.Event) error
+	DeleteDeadLetter(ctx context.Context, id string) error
+}
+
+// DeadLetterFilter selects dead letters by id, error class and queue. Bulk actions need an id or an
+// error class, or All to act on every dead letter; Queue alone narrows but does not select.
+type DeadLetterFilter struct {
+	IDs        []string
+	ErrorClass string
+	Queue      string
+	All        bool
+	// Limit caps how many dead letters are listed or acted upon. Zero means no limit.
+	Limit int
+}
+
+// Matches reports whether deadLetter satisfies the filter.
+func (f DeadLetterFilter) Matches(deadLetter domain.DeadLetter) bool {
+	if f.ErrorClass != "" && deadLetter.ErrorClass != f.ErrorClass {
+		return false
+	}
+	if f.Queue != "" && deadLetter.Queue != f.Queue {
+		return false
+	}
+	if len(f.IDs) == 0 {
+		return true
+	}
+	for _, id := range f.IDs {
+		if id == deadLetter.ID {
+			return true
+		}
+	}
+	return false
+}
+
+func (f DeadLetterFilter) empty() bool {
+	return len(f.IDs) == 0 && f.ErrorClass == ""
+}
+
+// DeadLetterPatch rewrites dead-lettered events before they are queued again. Nil fields are
+// left unchanged; Metadata keys are merged into the event metadata, a JSON null removing the key.
+type DeadLetterPatch struct {
+	Name     *string
+	UserID   *string
+	Source   *string
+	Metadata map[string]json.RawMessage
+}
+
+func (p DeadLetterPatch) empty() bool {
+	return p.Name == nil && p.UserID == nil && p.Source == nil && len(p.Metadata) == 0
+}
+
+// apply returns event with the patch applied.
+func (p DeadLetterPatch) apply(event domain.Event) (domain.Event, error) {
+	if p.Name != nil {
+		event.Name = *p.Name
+	}
+	if p.UserID != nil {
+		event.UserID = *p.UserID
+	}
+	if p.Source != nil {
+		event.Source = *p.Source
+	}
+	if len(p.Metadata) > 0 {
+		metadata := map[string]json.RawMessage{}
+		if len(event.Metadata) > 0 {
+			if err := json.Unmarshal(event.Metadata, &metadata); err != nil || metadata == nil {
+				return event, validationError("metadata of event " + event.ID.String() + " is not an object")
+			}
+		}
+		for key, value := range p.Metadata {
+			if string(value) == "null" {
+				delete(metadata, key)
+				continue
+			}
+			metadata[key] = value
+		}
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return event, validationError("invalid metadata patch")
+		}
+		event.Metadata = encoded
+	}
+	// Edited events get the checks ingestion applies to new ones, with the occurrence time bounded
+	// by the original reception rather than by now.
+	if _, err := domain.NewEvent(event.Name, event.UserID, event.Source, event.Metadata, time.Time{}); err != nil {
+		return event, validationError(err.Error())
+	}
+	if err := domain.CheckOccurredAt(event.OccurredAt, event.ReceivedAt); err != nil {
+		return event, validationError(err.Error())
+	}
+	return event, nil
+}
+
+// ManageDeadLetters lets operators inspect, redrive, edit and delete dead-lettered events across
+// every queue holding them.
+type ManageDeadLetters struct {
+	queues []DeadLetterQueue
+}
+
+// NewManageDeadLetters constructs a ManageDeadLetters use case instance. Queues are listed in the
+// given order.
+func NewManageDeadLetters(queues ...DeadLetterQueue) *ManageDeadLetters {
+	return &ManageDeadLetters{queues: queues}
+}
+
+// List returns the dead letters matching filter. Listing needs no criterion.
+func (uc *ManageDeadLetters) List(ctx context.Context, filter DeadLetterFilter) ([]domain.DeadLetter, error) {
+	deadLetters, err := uc.collect(ctx, filter)
+	return deadLetters, errors.Wrap(err, "list dead letters")
+}
+
+// Redrive queues the matching dead letters for processing again, returning how many were queued.
+func (uc *ManageDeadLetters) Redrive(ctx context.Context, filter DeadLetterFilter) (int, error) {
+	return uc.each(ctx, filter, "redrive", func(deadLetter domain.DeadLetter) error {
+		return uc.queue(deadLetter).RedriveDeadLetter(ctx, deadLetter.ID)
+	})
+}
+
+// Edit applies patch to the matching dead letters and queues the edited events for processing,
+// returning how many were queued. Every patched event is validated before any is queued.
+func (uc *ManageDeadLetters) Edit(ctx context.Context, filter DeadLetterFilter, patch DeadLetterPatch) (int, error) {
+	if patch.empty() {
+		return 0, validationError("patch changes nothing")
+	}
+	if err := uc.requireCriterion(filter); err != nil {
+		return 0, err
+	}
+	deadLetters, err := uc.collect(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrap(err, "edit dead letters")
+	}
+	events := make([]domain.Event, len(deadLetters))
+	for i, deadLetter := range deadLetters {
+		if events[i], err = patch.apply(deadLetter.Event); err != nil {
+			return 0, err
+		}
+	}
+	for i, deadLetter := range deadLetters {
+		if err := uc.queue(deadLetter).ReplaceDeadLetter(ctx, deadLetter.ID, events[i]); err != nil {
+			return i, errors.Wrapf(err, "edit dead letter %s", deadLetter.ID)
+		}
+	}
+	return len(deadLetters), nil
+}
+
+// Delete discards the matching dead letters, returning how many were deleted.
+func (uc *ManageDeadLetters) Delete(ctx context.Context, filter DeadLetterFilter) (int, error) {
+	return uc.each(ctx, filter, "delete", func(deadLetter domain.DeadLetter) error {
+		return uc.queue(deadLetter).DeleteDeadLetter(ctx, deadLetter.ID)
+	})
+}
+
+// each applies fn to every matching dead letter. Matches are collected first since acting on a
+// dead letter removes it from the queue being walked.
+func (uc *ManageDeadLetters) each(ctx context.Context, filter DeadLetterFilter, action string, fn func(domain.DeadLetter) error) (int, error) {
+	if err := uc.requireCriterion(filter); err != nil {
+		return 0, err
+	}
+	deadLetters, err := uc.collect(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrapf(err, "%s dead letters", action)
+	}
+	for i, deadLetter := range deadLetters {
+		if err := fn(deadLetter); err != nil {
+			return i, errors.Wrapf(err, "%s dead letter %s", action, deadLetter.ID)
+		}
+	}
+	return len(deadLetters), nil
+}
+
+func (uc *ManageDeadLetters) requireCriterion(filter DeadLetterFilter) error {
+	if filter.empty() && !filter.All {
+		return validationError("ids or error_class is required unless all is set")
+	}
+	return nil
+}
+
+var errLimitReached = errors.New("dead letter limit reached")
+
+func (uc *ManageDeadLetters) collect(ctx context.Context, filter DeadLetterFilter) ([]domain.DeadLetter, error) {
+	var deadLetters []domain.DeadLetter
+	for _, queue := range uc.queues {
+		if filter.Queue != "" && queue.Name() != filter.Queue {
+			continue
+		}
+		err := queue.EachDeadLetter(ctx, func(deadLetter domain.DeadLetter) error {
+			deadLetter.Queue = queue.Name()
+			if !filter.Matches(deadLetter) {
+				return nil
+			}
+			deadLetters = append(deadLetters, deadLetter)
+			if filter.Limit > 0 && len(deadLetters) >= filter.Limit {
+				return errLimitReached
+			}
+			return nil
+		})
+		if errors.Is(err, errLimitReached) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "queue %s", queue.Name())
+		}
+	}
+	return deadLetters, nil
+}
+
+// queue returns the queue holding deadLetter, which collect found there.
+func (uc *ManageDeadLetters) queue(deadLetter domain.DeadLetter) DeadLetterQueue {
+	for _, queue := range uc.queues {
+		if queue.Name() == deadLetter.Queue {
+			return queue
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// EventPurger deletes events whose retention has elapsed.
+type EventPurger interface {
+	PurgeExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+// EnforceRetention removes expired events when storage cannot expire them natively.
+type EnforceRetention struct {
+	purger EventPurger
+}
+
+// NewEnforceRetention constructs an EnforceRetention use case instance.
+func NewEnforceRetention(purger EventPurger) *EnforceRetention {
+	return &EnforceRetention{purger: purger}
+}
+
+// Execute purges expired events and returns how many were deleted.
+func (uc *EnforceRetention) Execute(ctx context.Context) (int64, error) {
+	deleted, err := uc.purger.PurgeExpired(ctx, time.Now().UTC())
+	if err != nil {
+		return deleted, errors.Wrap(err, "purge expired events")
+	}
+	return deleted, nil
+}
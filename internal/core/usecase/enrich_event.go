@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+var (
+	// ErrEventDropped signals that an event must be discarded without being persisted.
+	ErrEventDropped = errors.New("event dropped")
+)
+
+// EventEnricher augments an event with derived attributes before it is persisted.
+// Returning ErrEventDropped (optionally wrapped) discards the event.
+type EventEnricher interface {
+	Enrich(ctx context.Context, event *domain.Event) error
+}
+
+// EventEnricherFunc adapts a plain function into an EventEnricher.
+type EventEnricherFunc func(ctx context.Context, event *domain.Event) error
+
+// Enrich calls f(ctx, event).
+func (f EventEnricherFunc) Enrich(ctx context.Context, event *domain.Event) error {
+	return f(ctx, event)
+}
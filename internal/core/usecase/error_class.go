@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// ClassifyError maps a failure to a domain error class from the typed errors in its chain.
+func ClassifyError(err error) string {
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &syntaxErr) || errors.As(err, &typeErr):
+		return domain.ErrorClassDecode
+	case errors.Is(err, ErrValidation):
+		return domain.ErrorClassValidation
+	case errors.Is(err, ErrDuplicateEvent):
+		return domain.ErrorClassDuplicate
+	case errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()):
+		return domain.ErrorClassTimeout
+	case errors.As(err, &netErr) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF):
+		return domain.ErrorClassConnection
+	}
+	return domain.ErrorClassOther
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import "github.com/pkg/errors"
+
+// PermanentError marks a failure that retrying cannot fix, such as an undecodable payload or an
+// event the store rejects.
+type PermanentError struct {
+	Err error
+}
+
+// Permanent marks err as permanent. It returns nil for a nil err.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// TransientError marks a failure expected to clear on its own, such as a timeout or a dropped
+// connection.
+type TransientError struct {
+	Err error
+}
+
+// Transient marks err as transient. It returns nil for a nil err.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &TransientError{Err: err}
+}
+
+func (e *TransientError) Error() string { return e.Err.Error() }
+
+func (e *TransientError) Unwrap() error { return e.Err }
+
+// IsPermanent reports whether err is a PermanentError or ErrValidation, anywhere in its chain.
+// Unclassified errors are transient, so an unknown failure is retried rather than given up on.
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent) || errors.Is(err, ErrValidation)
+}
+
+// ErrDuplicateEvent is returned by EventRepository.Persist when an event with the same id is
+// already stored. Retries after a lost acknowledgement hit it, so it means the write succeeded.
+var ErrDuplicateEvent = errors.New("event already stored")
+
+// ErrDuplicateDelivery is returned by WebhookDeliveryRepository.CreateDelivery when a delivery with
+// the same id is already logged.
+var ErrDuplicateDelivery = errors.New("webhook delivery already logged")
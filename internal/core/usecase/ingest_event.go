@@ -13,6 +13,8 @@ import (
 var (
 	// ErrValidation indicates that the provided input cannot be processed.
 	ErrValidation = errors.New("validation error")
+	// ErrQueueFull indicates that the queue holds too large a backlog to accept more events.
+	ErrQueueFull = errors.New("event queue full")
 )
 
 // EventQueue defines the outbound dependency required to dispatch events for asynchronous processing.
@@ -20,14 +22,29 @@ type EventQueue interface {
 	Enqueue(ctx context.Context, event domain.Event) error
 }
 
+// EventFilter inspects or rewrites an event before it is enqueued. Returning ErrEventDropped
+// (optionally wrapped) discards the event; any other error aborts ingestion.
+type EventFilter interface {
+	Filter(ctx context.Context, event *domain.Event) error
+}
+
+// EventPublisher broadcasts accepted events to live subscribers. Publishing is best-effort:
+// implementations handle their own failures and must not block ingestion for long.
+type EventPublisher interface {
+	Publish(ctx context.Context, event domain.Event)
+}
+
 // IngestEvent orchestrates validation and dispatch of tracking events.
 type IngestEvent struct {
-	queue EventQueue
+	queue     EventQueue
+	publisher EventPublisher
+	filters   []EventFilter
 }
 
-// NewIngestEvent constructs an IngestEvent use case instance.
-func NewIngestEvent(queue EventQueue) *IngestEvent {
-	return &IngestEvent{queue: queue}
+// NewIngestEvent constructs an IngestEvent use case instance. Filters run in the given order.
+// publisher may be nil when no live tail is served.
+func NewIngestEvent(queue EventQueue, publisher EventPublisher, filters ...EventFilter) *IngestEvent {
+	return &IngestEvent{queue: queue, publisher: publisher, filters: filters}
 }
 
 // IngestEventInput models the information required to create a new event.
@@ -37,18 +54,43 @@ type IngestEventInput struct {
 	Source     string
 	Metadata   json.RawMessage
 	OccurredAt time.Time
+	UserAgent  string
+	IP         string
+	Consent    []string
 }
 
 // Execute validates the input, constructs a domain event, and enqueues it for processing.
+// Events discarded by a filter are returned without error but are never enqueued.
 func (uc *IngestEvent) Execute(ctx context.Context, input IngestEventInput) (domain.Event, error) {
 	event, err := domain.NewEvent(input.Name, input.UserID, input.Source, input.Metadata, input.OccurredAt)
 	if err != nil {
 		return domain.Event{}, validationError(err.Error())
 	}
+	event.SetUserAgent(input.UserAgent)
+	event.SetIP(input.IP)
+	if err := domain.ValidateConsentCategories(input.Consent); err != nil {
+		return domain.Event{}, validationError(err.Error())
+	}
+	event.Consent = input.Consent
+
+	for _, filter := range uc.filters {
+		if err := filter.Filter(ctx, &event); err != nil {
+			if errors.Is(err, ErrEventDropped) {
+				return event, nil
+			}
+			return domain.Event{}, errors.Wrap(err, "filter event")
+		}
+	}
+	if len(event.Metadata) > domain.EventMetadataLimit {
+		return domain.Event{}, validationError("metadata exceeds limit after filtering")
+	}
 
 	if err := uc.queue.Enqueue(ctx, event); err != nil {
 		return domain.Event{}, errors.Wrap(err, "enqueue event task")
 	}
+	if uc.publisher != nil {
+		uc.publisher.Publish(ctx, event)
+	}
 
 	return event, nil
 }
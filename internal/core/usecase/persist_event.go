@@ -2,7 +2,9 @@ package usecase
 
 import (
 	"context"
+	"strings"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 
 	"quotesnap/internal/core/domain"
@@ -10,23 +12,86 @@ import (
 
 // EventRepository defines persistence operations required by the domain.
 type EventRepository interface {
+	// Persist stores a new event, returning ErrDuplicateEvent when its id is already stored.
 	Persist(ctx context.Context, event domain.Event) error
+	Upsert(ctx context.Context, event domain.Event) error
+}
+
+// ErrObserverFailed indicates that an event was stored but at least one observer failed. Observers
+// skip the work they already did for an event, so retrying is safe.
+var ErrObserverFailed = errors.New("event observer failed")
+
+// EventObserver is notified after an event has been stored. An event is observed again when its
+// delivery is retried, so observers must be idempotent.
+type EventObserver interface {
+	Observe(ctx context.Context, event domain.Event) error
+}
+
+// CountedEvents remembers the events counting observers already counted, so an event delivered
+// again is not counted twice.
+type CountedEvents interface {
+	// MarkCounted records that counter counted eventID, reporting false when it already had.
+	MarkCounted(ctx context.Context, counter string, eventID uuid.UUID) (bool, error)
+	// UnmarkCounted forgets the mark after counting failed, so a retry counts the event.
+	UnmarkCounted(ctx context.Context, counter string, eventID uuid.UUID) error
+}
+
+// countOnce runs count unless counter already counted eventID.
+func countOnce(ctx context.Context, counted CountedEvents, counter string, eventID uuid.UUID, count func() error) error {
+	first, err := counted.MarkCounted(ctx, counter, eventID)
+	if err != nil {
+		return errors.Wrap(err, "mark event counted")
+	}
+	if !first {
+		return nil
+	}
+	if err := count(); err != nil {
+		if unmarkErr := counted.UnmarkCounted(ctx, counter, eventID); unmarkErr != nil {
+			return errors.Wrapf(err, "unmark event counted: %v", unmarkErr)
+		}
+		return err
+	}
+	return nil
 }
 
 // PersistEvent coordinates persisting events to durable storage.
 type PersistEvent struct {
-	repo EventRepository
+	repo      EventRepository
+	observers []EventObserver
 }
 
-// NewPersistEvent constructs a PersistEvent use case instance.
-func NewPersistEvent(repo EventRepository) *PersistEvent {
-	return &PersistEvent{repo: repo}
+// NewPersistEvent constructs a PersistEvent use case instance. Observers run in the given order.
+func NewPersistEvent(repo EventRepository, observers ...EventObserver) *PersistEvent {
+	return &PersistEvent{repo: repo, observers: observers}
 }
 
-// Execute stores the provided event using the underlying repository.
+// Execute stores the provided event using the underlying repository, then notifies every
+// observer. An event already stored by an earlier attempt counts as stored, so a retry after a
+// lost acknowledgement or a failed observer still reaches the observers, which skip what they
+// already did. Observer failures are reported together as ErrObserverFailed.
 func (uc *PersistEvent) Execute(ctx context.Context, event domain.Event) error {
-	if err := uc.repo.Persist(ctx, event); err != nil {
+	if err := uc.repo.Persist(ctx, event); err != nil && !errors.Is(err, ErrDuplicateEvent) {
 		return errors.Wrap(err, "persist event")
 	}
+
+	var failures []string
+	for _, observer := range uc.observers {
+		if err := observer.Observe(ctx, event); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.Wrap(ErrObserverFailed, strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Replay stores a reprocessed historical event, replacing any stored copy with the same id so
+// replays never duplicate events. Observers are not notified, which keeps counters they maintain
+// from counting the event twice; rollups of a store replayed into are rebuilt separately.
+func (uc *PersistEvent) Replay(ctx context.Context, event domain.Event) error {
+	if err := uc.repo.Upsert(ctx, event); err != nil {
+		return errors.Wrap(err, "upsert replayed event")
+	}
 	return nil
 }
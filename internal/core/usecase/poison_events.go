@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// PoisonEventStore keeps payloads that can never be processed.
+type PoisonEventStore interface {
+	SavePoisonEvent(ctx context.Context, event domain.PoisonEvent) error
+}
+
+// RecordPoisonEvent stores the raw payload of a task that failed permanently.
+type RecordPoisonEvent struct {
+	store PoisonEventStore
+}
+
+// NewRecordPoisonEvent constructs a RecordPoisonEvent use case instance.
+func NewRecordPoisonEvent(store PoisonEventStore) *RecordPoisonEvent {
+	return &RecordPoisonEvent{store: store}
+}
+
+// Execute stores payload of a taskType task together with the failure that condemned it.
+func (uc *RecordPoisonEvent) Execute(ctx context.Context, taskType string, payload []byte, cause error) error {
+	// Payloads failing to decode keep no user id; they expire with the rest.
+	var owner struct {
+		UserID string `json:"user_id"`
+	}
+	_ = json.Unmarshal(payload, &owner)
+
+	event := domain.PoisonEvent{
+		ID:         uuid.New(),
+		TaskType:   taskType,
+		UserID:     owner.UserID,
+		Payload:    payload,
+		Error:      cause.Error(),
+		ErrorClass: ClassifyError(cause),
+		FailedAt:   time.Now().UTC(),
+	}
+	return errors.Wrap(uc.store.SavePoisonEvent(ctx, event), "save poison event")
+}
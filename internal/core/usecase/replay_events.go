@@ -0,0 +1,128 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// EventQuery selects stored events by reception time and optional name and source. With
+// ByOccurrence set, Since and Until apply to the occurrence time instead. Metadata matches events
+// whose top-level metadata keys hold the given string values.
+type EventQuery struct {
+	Since        time.Time
+	Until        time.Time
+	Name         string
+	Source       string
+	Metadata     map[string]string
+	ByOccurrence bool
+}
+
+// Matches reports whether event satisfies the query.
+func (q EventQuery) Matches(event domain.Event) bool {
+	at := event.ReceivedAt
+	if q.ByOccurrence {
+		at = event.OccurredAt
+	}
+	if !q.Since.IsZero() && at.Before(q.Since) {
+		return false
+	}
+	if !q.Until.IsZero() && !at.Before(q.Until) {
+		return false
+	}
+	if q.Name != "" && event.Name != q.Name {
+		return false
+	}
+	if q.Source != "" && event.Source != q.Source {
+		return false
+	}
+	if len(q.Metadata) > 0 {
+		var metadata map[string]any
+		if err := json.Unmarshal(event.Metadata, &metadata); err != nil {
+			return false
+		}
+		for key, want := range q.Metadata {
+			if got, ok := metadata[key].(string); !ok || got != want {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// EventSource streams historical events matching a query.
+type EventSource interface {
+	EachEvent(ctx context.Context, query EventQuery, fn func(domain.Event) error) error
+}
+
+// ErrReplayQueued is returned by ReplayQueue.EnqueueReplay when the same replay already enqueued
+// the event.
+var ErrReplayQueued = errors.New("event already queued for replay")
+
+// ReplayQueue dispatches historical events for reprocessing.
+type ReplayQueue interface {
+	EnqueueReplay(ctx context.Context, event domain.Event) error
+}
+
+// ReplayResult counts the events of a replay.
+type ReplayResult struct {
+	Enqueued int
+	// Skipped counts events the source listed more than once.
+	Skipped int
+	// FirstOccurred and LastOccurred bound the occurrence times of the enqueued events.
+	FirstOccurred time.Time
+	LastOccurred  time.Time
+}
+
+// RateLimiter blocks until the next operation is allowed.
+type RateLimiter interface {
+	Wait(ctx context.Context) error
+}
+
+// ReplayEvents re-enqueues historical events so the worker pipeline processes them again.
+type ReplayEvents struct {
+	queue   ReplayQueue
+	limiter RateLimiter
+}
+
+// NewReplayEvents constructs a ReplayEvents use case instance.
+func NewReplayEvents(queue ReplayQueue, limiter RateLimiter) *ReplayEvents {
+	return &ReplayEvents{queue: queue, limiter: limiter}
+}
+
+// Execute streams events from source and enqueues those matching query, returning how many
+// were enqueued and skipped.
+func (uc *ReplayEvents) Execute(ctx context.Context, source EventSource, query EventQuery) (ReplayResult, error) {
+	var result ReplayResult
+	err := source.EachEvent(ctx, query, func(event domain.Event) error {
+		if !query.Matches(event) {
+			return nil
+		}
+		if err := uc.limiter.Wait(ctx); err != nil {
+			return err
+		}
+		if err := uc.queue.EnqueueReplay(ctx, event); err != nil {
+			if errors.Is(err, ErrReplayQueued) {
+				result.Skipped++
+				return nil
+			}
+			return errors.Wrapf(err, "enqueue replay of %s", event.ID)
+		}
+		result.Enqueued++
+		if result.FirstOccurred.IsZero() || event.OccurredAt.Before(result.FirstOccurred) {
+			result.FirstOccurred = event.OccurredAt
+		}
+		if event.OccurredAt.After(result.LastOccurred) {
+			result.LastOccurred = event.OccurredAt
+		}
+		return nil
+	})
+	if err != nil {
+		return result, errors.Wrap(err, "replay events")
+	}
+	return result, nil
+}
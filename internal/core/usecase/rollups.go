@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// RollupMaxBuckets bounds the number of buckets a single rollup query may span.
+const RollupMaxBuckets = 5000
+
+// RollupQuery selects rollups of one granularity whose bucket starts within [From, To).
+type RollupQuery struct {
+	Granularity domain.RollupGranularity
+	From        time.Time
+	To          time.Time
+	Name        string
+	Source      string
+}
+
+// RollupStore persists pre-aggregated event counters.
+type RollupStore interface {
+	IncrementRollups(ctx context.Context, rollups []domain.Rollup) error
+	QueryRollups(ctx context.Context, query RollupQuery) ([]domain.Rollup, error)
+	// ReplaceRollups drops every rollup with a bucket within [from, to) and stores rollups instead.
+	ReplaceRollups(ctx context.Context, from, to time.Time, rollups []domain.Rollup) error
+}
+
+// UpdateRollups is the EventObserver that counts persisted events into rollups.
+type UpdateRollups struct {
+	store   RollupStore
+	counted CountedEvents
+}
+
+// NewUpdateRollups constructs an UpdateRollups observer. counted keeps an event observed again
+// from being counted twice.
+func NewUpdateRollups(store RollupStore, counted CountedEvents) *UpdateRollups {
+	return &UpdateRollups{store: store, counted: counted}
+}
+
+// Observe increments the minute, hour and day rollups of event, once per event.
+func (uc *UpdateRollups) Observe(ctx context.Context, event domain.Event) error {
+	return countOnce(ctx, uc.counted, "rollups", event.ID, func() error {
+		return errors.Wrap(uc.store.IncrementRollups(ctx, domain.EventRollups(event)), "increment rollups")
+	})
+}
+
+// Ensure UpdateRollups satisfies the EventObserver dependency.
+var _ EventObserver = (*UpdateRollups)(nil)
+
+// QueryRollups reads pre-aggregated event counts.
+type QueryRollups struct {
+	store RollupStore
+}
+
+// NewQueryRollups constructs a QueryRollups use case instance.
+func NewQueryRollups(store RollupStore) *QueryRollups {
+	return &QueryRollups{store: store}
+}
+
+// Execute validates query and returns the matching rollups in bucket order.
+func (uc *QueryRollups) Execute(ctx context.Context, query RollupQuery) ([]domain.Rollup, error) {
+	if _, err := domain.ParseRollupGranularity(string(query.Granularity)); err != nil {
+		return nil, validationError(err.Error())
+	}
+	if !query.From.Before(query.To) {
+		return nil, validationError("from must be before to")
+	}
+	if query.To.Sub(query.From)/query.Granularity.Duration() > RollupMaxBuckets {
+		return nil, validationError("range spans too many buckets for the granularity")
+	}
+
+	rollups, err := uc.store.QueryRollups(ctx, query)
+	if err != nil {
+		return nil, errors.Wrap(err, "query rollups")
+	}
+	return rollups, nil
+}
+
+// RebuildRollups recomputes rollups from raw events, one UTC day at a time.
+type RebuildRollups struct {
+	events EventSource
+	store  RollupStore
+}
+
+// NewRebuildRollups constructs a RebuildRollups use case instance.
+func NewRebuildRollups(events EventSource, store RollupStore) *RebuildRollups {
+	return &RebuildRollups{events: events, store: store}
+}
+
+// Execute rebuilds every day overlapping [from, to) and returns the number of events counted.
+// Events persisted for a day while it is being rebuilt may be lost from its rollups, so recent
+// days are best rebuilt when ingestion is quiet.
+func (uc *RebuildRollups) Execute(ctx context.Context, from, to time.Time) (int64, error) {
+	if !from.Before(to) {
+		return 0, validationError("from must be before to")
+	}
+
+	var counted int64
+	for day := domain.RollupDay.Truncate(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		counts := make(map[domain.Rollup]int64)
+		query := EventQuery{Since: day, Until: next, ByOccurrence: true}
+		err := uc.events.EachEvent(ctx, query, func(event domain.Event) error {
+			for _, rollup := range domain.EventRollups(event) {
+				rollup.Count = 0
+				counts[rollup]++
+			}
+			counted++
+			return nil
+		})
+		if err != nil {
+			return counted, errors.Wrapf(err, "read events of %s", day.Format(time.DateOnly))
+		}
+
+		rollups := make([]domain.Rollup, 0, len(counts))
+		for rollup, count := range counts {
+			rollup.Count = count
+			rollups = append(rollups, rollup)
+		}
+		if err := uc.store.ReplaceRollups(ctx, day, next, rollups); err != nil {
+			return counted, errors.Wrapf(err, "replace rollups of %s", day.Format(time.DateOnly))
+		}
+	}
+	return counted, nil
+}
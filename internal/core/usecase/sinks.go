@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// SinkUnhealthyAfter is the number of consecutive write failures after which a sink is reported
+// unhealthy even if its health check passes.
+const SinkUnhealthyAfter = 3
+
+// EventSink is a secondary destination for persisted events.
+type EventSink interface {
+	Name() string
+	Kind() string
+	Write(ctx context.Context, event domain.Event) error
+	// Check probes the destination without writing an event.
+	Check(ctx context.Context) error
+}
+
+// SinkQueue schedules one independent write per sink so each sink retries on its own.
+type SinkQueue interface {
+	EnqueueSinkWrite(ctx context.Context, sink string, event domain.Event) error
+}
+
+// SinkStatusStore shares sink health reports between workers and the tracking service.
+type SinkStatusStore interface {
+	SaveSinkStatuses(ctx context.Context, statuses []domain.SinkStatus) error
+	SinkStatuses(ctx context.Context) ([]domain.SinkStatus, error)
+}
+
+// FanOutEvent is the EventObserver that schedules a write of every persisted event to each
+// secondary sink. Sinks receive whole events, personal data included, and erasure does not reach
+// them: copies held downstream must be erased by whoever operates the sink.
+type FanOutEvent struct {
+	sinks   []string
+	queue   SinkQueue
+	counted CountedEvents
+}
+
+// NewFanOutEvent constructs a FanOutEvent observer for the named sinks. counted keeps an event
+// observed again from being written to a sink twice.
+func NewFanOutEvent(sinks []string, queue SinkQueue, counted CountedEvents) *FanOutEvent {
+	return &FanOutEvent{sinks: sinks, queue: queue, counted: counted}
+}
+
+// Observe enqueues one write per sink that was not sent the event yet. A failed enqueue does not
+// keep the other sinks from getting the event; the failures are reported together, and failing
+// the event makes it retried, so the sink is not skipped.
+func (uc *FanOutEvent) Observe(ctx context.Context, event domain.Event) error {
+	var failures []string
+	for _, sink := range uc.sinks {
+		err := countOnce(ctx, uc.counted, "sink:"+sink, event.ID, func() error {
+			return uc.queue.EnqueueSinkWrite(ctx, sink, event)
+		})
+		if err != nil {
+			failures = append(failures, errors.Wrapf(err, "enqueue write to sink %s", sink).Error())
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// Ensure FanOutEvent satisfies the EventObserver dependency.
+var _ EventObserver = (*FanOutEvent)(nil)
+
+// sinkStats accumulates the write outcomes of one sink.
+type sinkStats struct {
+	written, failed, consecutive int64
+	lastError                    string
+	lastSuccess, lastFailure     time.Time
+}
+
+// WriteToSink performs scheduled sink writes and keeps per-sink statistics for health reports.
+type WriteToSink struct {
+	sinks  map[string]EventSink
+	worker string
+
+	mu    sync.Mutex
+	stats map[string]*sinkStats
+}
+
+// NewWriteToSink constructs a WriteToSink use case instance. worker identifies this process in
+// health reports.
+func NewWriteToSink(sinks []EventSink, worker string) *WriteToSink {
+	uc := &WriteToSink{sinks: make(map[string]EventSink), worker: worker, stats: make(map[string]*sinkStats)}
+	for _, sink := range sinks {
+		uc.sinks[sink.Name()] = sink
+		uc.stats[sink.Name()] = &sinkStats{}
+	}
+	return uc
+}
+
+// Execute writes event to the named sink and records the outcome.
+func (uc *WriteToSink) Execute(ctx context.Context, sinkName string, event domain.Event) error {
+	sink, ok := uc.sinks[sinkName]
+	if !ok {
+		return errors.Errorf("unknown sink %q", sinkName)
+	}
+
+	err := sink.Write(ctx, event)
+
+	uc.mu.Lock()
+	stats := uc.stats[sinkName]
+	if err != nil {
+		stats.failed++
+		stats.consecutive++
+		stats.lastError = err.Error()
+		stats.lastFailure = time.Now().UTC()
+	} else {
+		stats.written++
+		stats.consecutive = 0
+		stats.lastSuccess = time.Now().UTC()
+	}
+	uc.mu.Unlock()
+
+	return errors.Wrapf(err, "write to sink %s", sinkName)
+}
+
+// Statuses probes every sink and combines the probe with the write statistics gathered so far.
+func (uc *WriteToSink) Statuses(ctx context.Context) []domain.SinkStatus {
+	now := time.Now().UTC()
+	statuses := make([]domain.SinkStatus, 0, len(uc.sinks))
+	for name, sink := range uc.sinks {
+		checkErr := sink.Check(ctx)
+
+		uc.mu.Lock()
+		stats := *uc.stats[name]
+		uc.mu.Unlock()
+
+		status := domain.SinkStatus{
+			Sink:                name,
+			Kind:                sink.Kind(),
+			Worker:              uc.worker,
+			Healthy:             checkErr == nil && stats.consecutive < SinkUnhealthyAfter,
+			Written:             stats.written,
+			Failed:              stats.failed,
+			ConsecutiveFailures: stats.consecutive,
+			LastError:           stats.lastError,
+			LastSuccessAt:       stats.lastSuccess,
+			LastFailureAt:       stats.lastFailure,
+			ReportedAt:          now,
+		}
+		if checkErr != nil {
+			status.CheckError = checkErr.Error()
+		}
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Sink < statuses[j].Sink })
+	return statuses
+}
+
+// Report publishes the current statuses to store.
+func (uc *WriteToSink) Report(ctx context.Context, store SinkStatusStore) error {
+	if err := store.SaveSinkStatuses(ctx, uc.Statuses(ctx)); err != nil {
+		return errors.Wrap(err, "save sink statuses")
+	}
+	return nil
+}
+
+// GetSinkStatuses returns the latest sink health reports of every worker.
+type GetSinkStatuses struct {
+	store  SinkStatusStore
+	maxAge time.Duration
+}
+
+// NewGetSinkStatuses constructs a GetSinkStatuses use case instance. Reports older than maxAge
+// belong to workers that stopped reporting and are left out.
+func NewGetSinkStatuses(store SinkStatusStore, maxAge time.Duration) *GetSinkStatuses {
+	return &GetSinkStatuses{store: store, maxAge: maxAge}
+}
+
+// Execute returns the fresh reports ordered by sink and worker.
+func (uc *GetSinkStatuses) Execute(ctx context.Context) ([]domain.SinkStatus, error) {
+	statuses, err := uc.store.SinkStatuses(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "load sink statuses")
+	}
+	cutoff := time.Now().Add(-uc.maxAge)
+	fresh := make([]domain.SinkStatus, 0, len(statuses))
+	for _, status := range statuses {
+		if status.ReportedAt.After(cutoff) {
+			fresh = append(fresh, status)
+		}
+	}
+	sort.Slice(fresh, func(i, j int) bool {
+		if fresh[i].Sink != fresh[j].Sink {
+			return fresh[i].Sink < fresh[j].Sink
+		}
+		return fresh[i].Worker < fresh[j].Worker
+	})
+	return fresh, nil
+}
@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// EventSubscriber delivers events published by any replica until ctx is cancelled, at which point
+// the returned channel is closed.
+type EventSubscriber interface {
+	Subscribe(ctx context.Context) (<-chan domain.Event, error)
+}
+
+// TailFilter narrows a live tail. Empty fields match every event.
+type TailFilter struct {
+	Name   string
+	Source string
+	UserID string
+}
+
+// Matches reports whether event passes the filter.
+func (f TailFilter) Matches(event domain.Event) bool {
+	return (f.Name == "" || event.Name == f.Name) &&
+		(f.Source == "" || event.Source == f.Source) &&
+		(f.UserID == "" || event.UserID == f.UserID)
+}
+
+// TailEvents streams freshly ingested events for debugging integrations.
+type TailEvents struct {
+	subscriber EventSubscriber
+}
+
+// NewTailEvents constructs a TailEvents use case instance.
+func NewTailEvents(subscriber EventSubscriber) *TailEvents {
+	return &TailEvents{subscriber: subscriber}
+}
+
+// Execute subscribes to ingested events and returns those matching filter until ctx is cancelled.
+func (uc *TailEvents) Execute(ctx context.Context, filter TailFilter) (<-chan domain.Event, error) {
+	events, err := uc.subscriber.Subscribe(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "subscribe to events")
+	}
+
+	out := make(chan domain.Event)
+	go func() {
+		defer close(out)
+		for event := range events {
+			if !filter.Matches(event) {
+				continue
+			}
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+const (
+	// TrendingDefaultLimit is the number of quotes returned when no limit is requested.
+	TrendingDefaultLimit = 50
+	// TrendingMaxLimit bounds the number of quotes returned by a single query.
+	TrendingMaxLimit = 200
+)
+
+// TrendingStore keeps time-decayed activity scores per quote.
+type TrendingStore interface {
+	RecordQuoteActivity(ctx context.Context, quoteID string, weight float64, at time.Time) error
+	TopQuotes(ctx context.Context, window time.Duration, limit int) ([]domain.TrendingQuote, error)
+}
+
+// UpdateTrending is the EventObserver that scores quote activity. Only events with a configured
+// weight and a quote_id in their metadata are counted.
+type UpdateTrending struct {
+	store   TrendingStore
+	counted CountedEvents
+	weights map[string]float64
+}
+
+// NewUpdateTrending constructs an UpdateTrending observer. counted keeps an event observed again
+// from being counted twice.
+func NewUpdateTrending(store TrendingStore, counted CountedEvents, weights map[string]float64) *UpdateTrending {
+	return &UpdateTrending{store: store, counted: counted, weights: weights}
+}
+
+// Observe adds the event's weight to the score of the quote it references, once per event.
+func (uc *UpdateTrending) Observe(ctx context.Context, event domain.Event) error {
+	weight, ok := uc.weights[event.Name]
+	if !ok {
+		return nil
+	}
+	quoteID := metadataQuoteID(event.Metadata)
+	if quoteID == "" {
+		return nil
+	}
+	return countOnce(ctx, uc.counted, "trending", event.ID, func() error {
+		return errors.Wrap(uc.store.RecordQuoteActivity(ctx, quoteID, weight, event.ReceivedAt), "record quote activity")
+	})
+}
+
+// metadataQuoteID extracts metadata.quote_id as a string, accepting string and numeric ids.
+func metadataQuoteID(metadata json.RawMessage) string {
+	var fields struct {
+		QuoteID any `json:"quote_id"`
+	}
+	if err := json.Unmarshal(metadata, &fields); err != nil {
+		return ""
+	}
+	switch id := fields.QuoteID.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return ""
+}
+
+// Ensure UpdateTrending satisfies the EventObserver dependency.
+var _ EventObserver = (*UpdateTrending)(nil)
+
+// GetTrendingQuotes returns the hottest quotes over a recent window.
+type GetTrendingQuotes struct {
+	store     TrendingStore
+	maxWindow time.Duration
+}
+
+// NewGetTrendingQuotes constructs a GetTrendingQuotes use case instance. maxWindow is the longest
+// window the store keeps activity for.
+func NewGetTrendingQuotes(store TrendingStore, maxWindow time.Duration) *GetTrendingQuotes {
+	return &GetTrendingQuotes{store: store, maxWindow: maxWindow}
+}
+
+// Execute returns up to limit quotes ranked by their decayed score over window.
+func (uc *GetTrendingQuotes) Execute(ctx context.Context, window time.Duration, limit int) ([]domain.TrendingQuote, error) {
+	if window <= 0 || window > uc.maxWindow {
+		return nil, validationError(fmt.Sprintf("window must be positive and at most %s", uc.maxWindow))
+	}
+	if limit <= 0 || limit > TrendingMaxLimit {
+		return nil, validationError(fmt.Sprintf("limit must be between 1 and %d", TrendingMaxLimit))
+	}
+
+	quotes, err := uc.store.TopQuotes(ctx, window, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "load trending quotes")
+	}
+	return quotes, nil
+}
@@ -0,0 +1,234 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+var (
+	// ErrNotFound indicates that the requested resource does not exist.
+	ErrNotFound = errors.New("not found")
+)
+
+// UserEventStore defines the per-user operations required on raw event storage.
+type UserEventStore interface {
+	UserProfile(ctx context.Context, userID string) (domain.UserProfile, error)
+	EachUserEvent(ctx context.Context, userID string, fn func(domain.Event) error) error
+	EraseUser(ctx context.Context, userID string, mode domain.ErasureMode) (int64, error)
+}
+
+// UserDataSection is a derived store holding per-user documents that must be included in
+// exports and erased alongside raw events.
+type UserDataSection interface {
+	Name() string
+	ExportUser(ctx context.Context, userID string) (any, error)
+	EraseUser(ctx context.Context, userID string, mode domain.ErasureMode) (int64, error)
+}
+
+// TombstoneStore records users whose data has been erased so late-arriving events are dropped.
+type TombstoneStore interface {
+	CreateTombstone(ctx context.Context, userID string, requestID uuid.UUID) error
+	IsTombstoned(ctx context.Context, userID string) (bool, error)
+}
+
+// ErasureRepository persists erasure requests and their status.
+type ErasureRepository interface {
+	CreateErasure(ctx context.Context, request domain.ErasureRequest) error
+	GetErasure(ctx context.Context, id uuid.UUID) (domain.ErasureRequest, error)
+	UpdateErasure(ctx context.Context, request domain.ErasureRequest) error
+}
+
+// ErasureQueue schedules erasure requests for asynchronous execution.
+type ErasureQueue interface {
+	EnqueueErasure(ctx context.Context, requestID uuid.UUID) error
+}
+
+// UserExport is the non-streamed part of a user data export.
+type UserExport struct {
+	Profile  domain.UserProfile `json:"profile"`
+	Sections map[string]any     `json:"sections,omitempty"`
+}
+
+// ExportUserData gathers everything stored about a user.
+type ExportUserData struct {
+	events   UserEventStore
+	sections []UserDataSection
+}
+
+// NewExportUserData constructs an ExportUserData use case instance.
+func NewExportUserData(events UserEventStore, sections ...UserDataSection) *ExportUserData {
+	return &ExportUserData{events: events, sections: sections}
+}
+
+// Execute streams the user's events to onEvent and returns their profile and derived data.
+func (uc *ExportUserData) Execute(ctx context.Context, userID string, onEvent func(domain.Event) error) (UserExport, error) {
+	if userID == "" {
+		return UserExport{}, validationError("user_id is required")
+	}
+
+	if err := uc.events.EachUserEvent(ctx, userID, onEvent); err != nil {
+		return UserExport{}, errors.Wrap(err, "export user events")
+	}
+
+	profile, err := uc.events.UserProfile(ctx, userID)
+	if err != nil {
+		return UserExport{}, errors.Wrap(err, "load user profile")
+	}
+
+	export := UserExport{Profile: profile, Sections: make(map[string]any, len(uc.sections))}
+	for _, section := range uc.sections {
+		data, err := section.ExportUser(ctx, userID)
+		if err != nil {
+			return UserExport{}, errors.Wrapf(err, "export %s", section.Name())
+		}
+		export.Sections[section.Name()] = data
+	}
+	return export, nil
+}
+
+// RequestErasure registers an erasure request, tombstones the user and schedules the deletion job.
+type RequestErasure struct {
+	requests   ErasureRepository
+	tombstones TombstoneStore
+	queue      ErasureQueue
+}
+
+// NewRequestErasure constructs a RequestErasure use case instance.
+func NewRequestErasure(requests ErasureRepository, tombstones TombstoneStore, queue ErasureQueue) *RequestErasure {
+	return &RequestErasure{requests: requests, tombstones: tombstones, queue: queue}
+}
+
+// Execute tombstones the user first so that new events are rejected while the job is pending.
+func (uc *RequestErasure) Execute(ctx context.Context, userID string, mode domain.ErasureMode) (domain.ErasureRequest, error) {
+	request, err := domain.NewErasureRequest(userID, mode)
+	if err != nil {
+		return domain.ErasureRequest{}, validationError(err.Error())
+	}
+
+	if err := uc.tombstones.CreateTombstone(ctx, userID, request.ID); err != nil {
+		return domain.ErasureRequest{}, errors.Wrap(err, "create tombstone")
+	}
+	if err := uc.requests.CreateErasure(ctx, request); err != nil {
+		return domain.ErasureRequest{}, errors.Wrap(err, "create erasure request")
+	}
+	if err := uc.queue.EnqueueErasure(ctx, request.ID); err != nil {
+		return domain.ErasureRequest{}, errors.Wrap(err, "enqueue erasure task")
+	}
+	return request, nil
+}
+
+// Status returns the current state of an erasure request.
+func (uc *RequestErasure) Status(ctx context.Context, id uuid.UUID) (domain.ErasureRequest, error) {
+	request, err := uc.requests.GetErasure(ctx, id)
+	if err != nil {
+		return domain.ErasureRequest{}, errors.Wrap(err, "load erasure request")
+	}
+	return request, nil
+}
+
+// EraseUserData executes a pending erasure request across events and derived stores.
+type EraseUserData struct {
+	requests ErasureRepository
+	events   UserEventStore
+	sections []UserDataSection
+}
+
+// NewEraseUserData constructs an EraseUserData use case instance.
+func NewEraseUserData(requests ErasureRepository, events UserEventStore, sections ...UserDataSection) *EraseUserData {
+	return &EraseUserData{requests: requests, events: events, sections: sections}
+}
+
+// Execute runs the erasure and records its outcome on the request.
+func (uc *EraseUserData) Execute(ctx context.Context, requestID uuid.UUID) error {
+	request, err := uc.requests.GetErasure(ctx, requestID)
+	if err != nil {
+		return errors.Wrap(err, "load erasure request")
+	}
+	if request.Status == domain.ErasureStatusCompleted {
+		return nil
+	}
+
+	request.Status = domain.ErasureStatusRunning
+	request.Error = ""
+	if err := uc.update(ctx, &request); err != nil {
+		return err
+	}
+
+	affected, eraseErr := uc.erase(ctx, request)
+	request.Affected = affected
+	request.Status = domain.ErasureStatusCompleted
+	if eraseErr != nil {
+		request.Status = domain.ErasureStatusFailed
+		request.Error = eraseErr.Error()
+	}
+	if err := uc.update(ctx, &request); err != nil {
+		return err
+	}
+	return eraseErr
+}
+
+func (uc *EraseUserData) erase(ctx context.Context, request domain.ErasureRequest) (map[string]int64, error) {
+	affected := make(map[string]int64, len(uc.sections)+1)
+
+	count, err := uc.events.EraseUser(ctx, request.UserID, request.Mode)
+	if err != nil {
+		return affected, errors.Wrap(err, "erase events")
+	}
+	affected["events"] = count
+
+	for _, section := range uc.sections {
+		count, err := section.EraseUser(ctx, request.UserID, request.Mode)
+		if err != nil {
+			return affected, errors.Wrapf(err, "erase %s", section.Name())
+		}
+		affected[section.Name()] = count
+	}
+	return affected, nil
+}
+
+func (uc *EraseUserData) update(ctx context.Context, request *domain.ErasureRequest) error {
+	request.UpdatedAt = time.Now().UTC()
+	if err := uc.requests.UpdateErasure(ctx, *request); err != nil {
+		return errors.Wrap(err, "update erasure request")
+	}
+	return nil
+}
+
+// TombstoneFilter drops events belonging to erased users. It is used both at ingest and in
+// the worker so that events already queued when the erasure was requested are discarded too.
+type TombstoneFilter struct {
+	tombstones TombstoneStore
+}
+
+// NewTombstoneFilter constructs a TombstoneFilter instance.
+func NewTombstoneFilter(tombstones TombstoneStore) *TombstoneFilter {
+	return &TombstoneFilter{tombstones: tombstones}
+}
+
+// Filter rejects events for tombstoned users before they are enqueued.
+func (f *TombstoneFilter) Filter(ctx context.Context, event *domain.Event) error {
+	tombstoned, err := f.tombstones.IsTombstoned(ctx, event.UserID)
+	if err != nil {
+		return errors.Wrap(err, "check tombstone")
+	}
+	if tombstoned {
+		return errors.Wrap(ErrEventDropped, "user erased")
+	}
+	return nil
+}
+
+// Enrich rejects events for tombstoned users before they are persisted.
+func (f *TombstoneFilter) Enrich(ctx context.Context, event *domain.Event) error {
+	return f.Filter(ctx, event)
+}
+
+// Ensure TombstoneFilter satisfies both pipeline dependencies.
+var (
+	_ EventFilter   = (*TombstoneFilter)(nil)
+	_ EventEnricher = (*TombstoneFilter)(nil)
+)
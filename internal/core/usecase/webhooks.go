@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// WebhookDeliveryListLimit bounds the number of deliveries returned by a single listing.
+const WebhookDeliveryListLimit = 100
+
+// WebhookSubscriptionRepository stores webhook subscriptions.
+type WebhookSubscriptionRepository interface {
+	CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id uuid.UUID) (domain.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub domain.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id uuid.UUID) error
+}
+
+// WebhookSubscriptionSource lists the active subscriptions consulted for every persisted event.
+type WebhookSubscriptionSource interface {
+	ActiveSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error)
+}
+
+// WebhookDeliveryRepository stores the webhook delivery log.
+type WebhookDeliveryRepository interface {
+	CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uuid.UUID) (domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	// ListDeliveries returns the newest deliveries of a subscription, optionally by status.
+	ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, limit int) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookQueue schedules webhook deliveries for asynchronous execution.
+type WebhookQueue interface {
+	EnqueueWebhookDelivery(ctx context.Context, deliveryID uuid.UUID) error
+}
+
+// WebhookSender performs one signed delivery attempt and returns the response status code.
+// Responses outside 2xx are reported as errors.
+type WebhookSender interface {
+	Send(ctx context.Context, sub domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error)
+}
+
+// WebhookSubscriptionInput carries the mutable fields of a subscription. A nil Active leaves the
+// current state unchanged.
+type WebhookSubscriptionInput struct {
+	EventName string
+	Source    string
+	URL       string
+	Active    *bool
+}
+
+// ManageWebhooks administers webhook subscriptions and their delivery log.
+type ManageWebhooks struct {
+	subscriptions WebhookSubscriptionRepository
+	deliveries    WebhookDeliveryRepository
+	queue         WebhookQueue
+	allowPrivate  bool
+}
+
+// NewManageWebhooks constructs a ManageWebhooks use case instance. Unless allowPrivate is set,
+// subscriptions may not target localhost or internal addresses.
+func NewManageWebhooks(subscriptions WebhookSubscriptionRepository, deliveries WebhookDeliveryRepository, queue WebhookQueue, allowPrivate bool) *ManageWebhooks {
+	return &ManageWebhooks{subscriptions: subscriptions, deliveries: deliveries, queue: queue, allowPrivate: allowPrivate}
+}
+
+// checkTarget refuses internal subscription URLs unless they are allowed.
+func (uc *ManageWebhooks) checkTarget(target string) error {
+	if uc.allowPrivate {
+		return nil
+	}
+	if err := domain.CheckPublicWebhookURL(target); err != nil {
+		return validationError(err.Error())
+	}
+	return nil
+}
+
+// Create registers a new subscription. The returned subscription carries its signing secret.
+func (uc *ManageWebhooks) Create(ctx context.Context, input WebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	sub, err := domain.NewWebhookSubscription(input.EventName, input.Source, input.URL)
+	if err != nil {
+		return domain.WebhookSubscription{}, validationError(err.Error())
+	}
+	if err := uc.checkTarget(sub.URL); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	if err := uc.subscriptions.CreateSubscription(ctx, sub); err != nil {
+		return domain.WebhookSubscription{}, errors.Wrap(err, "create webhook subscription")
+	}
+	return sub, nil
+}
+
+// List returns every subscription.
+func (uc *ManageWebhooks) List(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	subs, err := uc.subscriptions.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "list webhook subscriptions")
+	}
+	return subs, nil
+}
+
+// Get returns a single subscription.
+func (uc *ManageWebhooks) Get(ctx context.Context, id uuid.UUID) (domain.WebhookSubscription, error) {
+	sub, err := uc.subscriptions.GetSubscription(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, errors.Wrap(err, "load webhook subscription")
+	}
+	return sub, nil
+}
+
+// Update replaces the filter and target of a subscription and optionally (de)activates it.
+func (uc *ManageWebhooks) Update(ctx context.Context, id uuid.UUID, input WebhookSubscriptionInput) (domain.WebhookSubscription, error) {
+	sub, err := uc.subscriptions.GetSubscription(ctx, id)
+	if err != nil {
+		return domain.WebhookSubscription{}, errors.Wrap(err, "load webhook subscription")
+	}
+	if err := sub.SetFilter(input.EventName, input.Source, input.URL); err != nil {
+		return domain.WebhookSubscription{}, validationError(err.Error())
+	}
+	if err := uc.checkTarget(sub.URL); err != nil {
+		return domain.WebhookSubscription{}, err
+	}
+	if input.Active != nil {
+		sub.Active = *input.Active
+	}
+	if err := uc.subscriptions.UpdateSubscription(ctx, sub); err != nil {
+		return domain.WebhookSubscription{}, errors.Wrap(err, "update webhook subscription")
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription. Its delivery log is kept until it expires.
+func (uc *ManageWebhooks) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := uc.subscriptions.DeleteSubscription(ctx, id); err != nil {
+		return errors.Wrap(err, "delete webhook subscription")
+	}
+	return nil
+}
+
+// Deliveries returns the newest deliveries of a subscription, optionally filtered by status.
+func (uc *ManageWebhooks) Deliveries(ctx context.Context, id uuid.UUID, status domain.WebhookDeliveryStatus) ([]domain.WebhookDelivery, error) {
+	switch status {
+	case "", domain.WebhookDeliveryPending, domain.WebhookDeliveryRetrying, domain.WebhookDeliverySucceeded, domain.WebhookDeliveryFailed:
+	default:
+		return nil, validationError(fmt.Sprintf("unknown delivery status %q", status))
+	}
+	if _, err := uc.subscriptions.GetSubscription(ctx, id); err != nil {
+		return nil, errors.Wrap(err, "load webhook subscription")
+	}
+	deliveries, err := uc.deliveries.ListDeliveries(ctx, id, status, WebhookDeliveryListLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "list webhook deliveries")
+	}
+	return deliveries, nil
+}
+
+// Redeliver schedules a new delivery of a failed delivery's payload.
+func (uc *ManageWebhooks) Redeliver(ctx context.Context, deliveryID uuid.UUID) (domain.WebhookDelivery, error) {
+	original, err := uc.deliveries.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, errors.Wrap(err, "load webhook delivery")
+	}
+	if original.Status != domain.WebhookDeliveryFailed {
+		return domain.WebhookDelivery{}, validationError("only failed deliveries can be redelivered")
+	}
+
+	delivery := original.Redeliver()
+	if err := uc.deliveries.CreateDelivery(ctx, delivery); err != nil {
+		return domain.WebhookDelivery{}, errors.Wrap(err, "create webhook delivery")
+	}
+	if err := uc.queue.EnqueueWebhookDelivery(ctx, delivery.ID); err != nil {
+		return domain.WebhookDelivery{}, errors.Wrap(err, "enqueue webhook delivery")
+	}
+	return delivery, nil
+}
+
+// DispatchWebhooks is the EventObserver that schedules a delivery for every subscription matching
+// a persisted event.
+type DispatchWebhooks struct {
+	subscriptions WebhookSubscriptionSource
+	deliveries    WebhookDeliveryRepository
+	queue         WebhookQueue
+}
+
+// NewDispatchWebhooks constructs a DispatchWebhooks observer.
+func NewDispatchWebhooks(subscriptions WebhookSubscriptionSource, deliveries WebhookDeliveryRepository, queue WebhookQueue) *DispatchWebhooks {
+	return &DispatchWebhooks{subscriptions: subscriptions, deliveries: deliveries, queue: queue}
+}
+
+// Observe records and enqueues one delivery per matching subscription. Delivery ids are derived
+// from the subscription and the event, so observing an event again sends nothing twice.
+func (uc *DispatchWebhooks) Observe(ctx context.Context, event domain.Event) error {
+	subs, err := uc.subscriptions.ActiveSubscriptions(ctx)
+	if err != nil {
+		return errors.Wrap(err, "load webhook subscriptions")
+	}
+
+	var failures []string
+	for _, sub := range subs {
+		if !sub.Matches(event) {
+			continue
+		}
+		if err := uc.dispatch(ctx, sub, event); err != nil {
+			failures = append(failures, fmt.Sprintf("subscription %s: %v", sub.ID, err))
+		}
+	}
+	if len(failures) > 0 {
+		return errors.New(strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (uc *DispatchWebhooks) dispatch(ctx context.Context, sub domain.WebhookSubscription, event domain.Event) error {
+	delivery, err := domain.NewWebhookDelivery(sub, event)
+	if err != nil {
+		return err
+	}
+	if err := uc.deliveries.CreateDelivery(ctx, delivery); err != nil {
+		if !errors.Is(err, ErrDuplicateDelivery) {
+			return errors.Wrap(err, "create webhook delivery")
+		}
+		// A delivery that was attempted was enqueued; one that was not may have failed to be.
+		logged, err := uc.deliveries.GetDelivery(ctx, delivery.ID)
+		if err != nil {
+			return errors.Wrap(err, "load webhook delivery")
+		}
+		if logged.Status != domain.WebhookDeliveryPending || logged.Attempts > 0 {
+			return nil
+		}
+	}
+	return errors.Wrap(uc.queue.EnqueueWebhookDelivery(ctx, delivery.ID), "enqueue webhook delivery")
+}
+
+// Ensure DispatchWebhooks satisfies the EventObserver dependency.
+var _ EventObserver = (*DispatchWebhooks)(nil)
+
+// DeliverWebhook performs delivery attempts and records their outcome in the delivery log.
+type DeliverWebhook struct {
+	subscriptions WebhookSubscriptionRepository
+	deliveries    WebhookDeliveryRepository
+	sender        WebhookSender
+}
+
+// NewDeliverWebhook constructs a DeliverWebhook use case instance.
+func NewDeliverWebhook(subscriptions WebhookSubscriptionRepository, deliveries WebhookDeliveryRepository, sender WebhookSender) *DeliverWebhook {
+	return &DeliverWebhook{subscriptions: subscriptions, deliveries: deliveries, sender: sender}
+}
+
+// Execute attempts the delivery once. A failed attempt is returned as an error so the caller can
+// retry it; final marks the last attempt, after which the delivery is recorded as failed.
+// Deliveries whose subscription was removed or deactivated fail without an attempt.
+func (uc *DeliverWebhook) Execute(ctx context.Context, deliveryID uuid.UUID, final bool) error {
+	delivery, err := uc.deliveries.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return errors.Wrap(err, "load webhook delivery")
+	}
+	if delivery.Status == domain.WebhookDeliverySucceeded {
+		return nil
+	}
+
+	sub, err := uc.subscriptions.GetSubscription(ctx, delivery.SubscriptionID)
+	if errors.Is(err, ErrNotFound) || (err == nil && !sub.Active) {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = "subscription removed or inactive"
+		delivery.UpdatedAt = time.Now().UTC()
+		return errors.Wrap(uc.deliveries.UpdateDelivery(ctx, delivery), "update webhook delivery")
+	}
+	if err != nil {
+		return errors.Wrap(err, "load webhook subscription")
+	}
+
+	code, sendErr := uc.sender.Send(ctx, sub, delivery)
+	delivery.Attempts++
+	delivery.LastStatusCode = code
+	delivery.LastError = ""
+	delivery.UpdatedAt = time.Now().UTC()
+	switch {
+	case sendErr == nil:
+		delivery.Status = domain.WebhookDeliverySucceeded
+	case final:
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.LastError = sendErr.Error()
+	default:
+		delivery.Status = domain.WebhookDeliveryRetrying
+		delivery.LastError = sendErr.Error()
+	}
+	if err := uc.deliveries.UpdateDelivery(ctx, delivery); err != nil {
+		return errors.Wrap(err, "update webhook delivery")
+	}
+	return errors.Wrap(sendErr, "deliver webhook")
+}
@@ -0,0 +1,90 @@
+package archive
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// encoder serialises a batch of events into w.
+type encoder func(w io.Writer, events []domain.Event) error
+
+var encoders = map[string]encoder{
+	domain.ArchiveFormatNDJSON:  encodeNDJSON,
+	domain.ArchiveFormatParquet: encodeParquet,
+}
+
+func encodeNDJSON(w io.Writer, events []domain.Event) error {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+	for _, event := range events {
+		if err := enc.Encode(event); err != nil {
+			gz.Close()
+			return errors.Wrap(err, "encode ndjson event")
+		}
+	}
+	return errors.Wrap(gz.Close(), "close gzip stream")
+}
+
+// parquetEvent is the flattened Parquet row layout of an event. Metadata keeps its raw JSON form.
+type parquetEvent struct {
+	ID             string    `parquet:"id"`
+	Name           string    `parquet:"name,dict"`
+	UserID         string    `parquet:"user_id"`
+	Source         string    `parquet:"source,dict"`
+	Metadata       string    `parquet:"metadata,json"`
+	OccurredAt     time.Time `parquet:"occurred_at,timestamp(millisecond)"`
+	ReceivedAt     time.Time `parquet:"received_at,timestamp(millisecond)"`
+	UserAgent      string    `parquet:"user_agent,optional"`
+	IP             string    `parquet:"ip,optional"`
+	Browser        string    `parquet:"browser,optional,dict"`
+	BrowserVersion string    `parquet:"browser_version,optional"`
+	OS             string    `parquet:"os,optional,dict"`
+	OSVersion      string    `parquet:"os_version,optional"`
+	DeviceType     string    `parquet:"device_type,optional,dict"`
+	Bot            bool      `parquet:"bot"`
+	Country        string    `parquet:"country,optional,dict"`
+	Region         string    `parquet:"region,optional"`
+	City           string    `parquet:"city,optional"`
+	Consent        []string  `parquet:"consent,list"`
+}
+
+func encodeParquet(w io.Writer, events []domain.Event) error {
+	rows := make([]parquetEvent, 0, len(events))
+	for _, event := range events {
+		row := parquetEvent{
+			ID:         event.ID.String(),
+			Name:       event.Name,
+			UserID:     event.UserID,
+			Source:     event.Source,
+			Metadata:   string(event.Metadata),
+			OccurredAt: event.OccurredAt,
+			ReceivedAt: event.ReceivedAt,
+			UserAgent:  event.UserAgent,
+			IP:         event.IP,
+			Consent:    event.Consent,
+		}
+		if d := event.Device; d != nil {
+			row.Browser, row.BrowserVersion = d.Browser, d.BrowserVersion
+			row.OS, row.OSVersion = d.OS, d.OSVersion
+			row.DeviceType, row.Bot = d.Type, d.Bot
+		}
+		if g := event.Geo; g != nil {
+			row.Country, row.Region, row.City = g.Country, g.Region, g.City
+		}
+		rows = append(rows, row)
+	}
+
+	writer := parquet.NewGenericWriter[parquetEvent](w, parquet.Compression(&parquet.Zstd))
+	if _, err := writer.Write(rows); err != nil {
+		writer.Close()
+		return errors.Wrap(err, "write parquet rows")
+	}
+	return errors.Wrap(writer.Close(), "close parquet writer")
+}
@@ -0,0 +1,119 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Store is the object storage used for archive files. Keys are slash separated.
+type Store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	List(ctx context.Context, prefix string) ([]string, error)
+}
+
+// LocalStore keeps archive files below a root directory.
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore creates root if needed and returns a LocalStore writing below it.
+func NewLocalStore(root string) (*LocalStore, error) {
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, errors.Wrap(err, "create archive directory")
+	}
+	return &LocalStore{root: root}, nil
+}
+
+// Put writes r to key atomically by renaming a fully synced temporary file into place.
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader, _ int64) error {
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return errors.Wrap(err, "create archive partition")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return errors.Wrap(err, "create archive temp file")
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "write archive file")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "sync archive file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "close archive file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), path), "rename archive file")
+}
+
+// Open returns a reader for key.
+func (s *LocalStore) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(key))
+	return f, errors.Wrap(err, "open archive file")
+}
+
+// List returns every key below prefix in lexical order.
+func (s *LocalStore) List(_ context.Context, prefix string) ([]string, error) {
+	var keys []string
+	err := filepath.WalkDir(s.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, path)
+		if err != nil {
+			return err
+		}
+		key := filepath.ToSlash(rel)
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list archive files")
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+func (s *LocalStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+// Ensure LocalStore satisfies the Store dependency.
+var _ Store = (*LocalStore)(nil)
+
+// NewStore builds the Store selected by kind ("local" or "s3").
+func NewStore(ctx context.Context, kind, localDir string, s3 S3Config) (Store, error) {
+	switch kind {
+	case "local":
+		store, err := NewLocalStore(localDir)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	case "s3":
+		store, err := NewS3Store(ctx, s3)
+		if err != nil {
+			return nil, err
+		}
+		return store, nil
+	}
+	return nil, errors.Errorf("unknown archive store %q", kind)
+}
@@ -0,0 +1,157 @@
+package archive
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// Writer encodes event batches into every configured format, uploads them to a Store, verifies
+// the stored bytes against local checksums and finally writes the batch manifest.
+//
+// Files are laid out as events/dt=YYYY-MM-DD/part-<batch>.<format> next to
+// events/dt=YYYY-MM-DD/manifest-<batch>.json.
+type Writer struct {
+	store   Store
+	formats []string
+}
+
+// NewWriter constructs a Writer for the given formats.
+func NewWriter(store Store, formats []string) (*Writer, error) {
+	if len(formats) == 0 {
+		return nil, errors.New("at least one archive format is required")
+	}
+	for _, format := range formats {
+		if _, ok := encoders[format]; !ok {
+			return nil, errors.Errorf("unknown archive format %q", format)
+		}
+	}
+	return &Writer{store: store, formats: formats}, nil
+}
+
+// PartitionPrefix returns the key prefix of a daily partition.
+func PartitionPrefix(partition string) string {
+	return "events/dt=" + partition + "/"
+}
+
+// WriteBatch archives events belonging to partition and returns the verified manifest.
+func (w *Writer) WriteBatch(ctx context.Context, partition string, events []domain.Event) (domain.ArchiveManifest, error) {
+	now := time.Now().UTC()
+	batchID := now.Format("20060102T150405Z") + "-" + uuid.NewString()[:8]
+	prefix := PartitionPrefix(partition)
+
+	manifest := domain.ArchiveManifest{
+		BatchID:       batchID,
+		Partition:     partition,
+		CreatedAt:     now,
+		EventCount:    len(events),
+		MinReceivedAt: events[0].ReceivedAt,
+		MaxReceivedAt: events[0].ReceivedAt,
+	}
+	for _, event := range events {
+		if event.ReceivedAt.Before(manifest.MinReceivedAt) {
+			manifest.MinReceivedAt = event.ReceivedAt
+		}
+		if event.ReceivedAt.After(manifest.MaxReceivedAt) {
+			manifest.MaxReceivedAt = event.ReceivedAt
+		}
+	}
+
+	for _, format := range w.formats {
+		file, err := w.writeFile(ctx, prefix+"part-"+batchID+"."+format, format, events)
+		if err != nil {
+			return domain.ArchiveManifest{}, errors.Wrapf(err, "write %s file", format)
+		}
+		manifest.Files = append(manifest.Files, file)
+	}
+
+	payload, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return domain.ArchiveManifest{}, errors.Wrap(err, "marshal manifest")
+	}
+	manifestKey := prefix + "manifest-" + batchID + ".json"
+	if err := w.store.Put(ctx, manifestKey, bytes.NewReader(payload), int64(len(payload))); err != nil {
+		return domain.ArchiveManifest{}, errors.Wrap(err, "write manifest")
+	}
+	sum := sha256.Sum256(payload)
+	if err := w.verify(ctx, manifestKey, int64(len(payload)), hex.EncodeToString(sum[:])); err != nil {
+		return domain.ArchiveManifest{}, err
+	}
+	return manifest, nil
+}
+
+// writeFile encodes events into a local temporary file, uploads it and verifies the upload.
+func (w *Writer) writeFile(ctx context.Context, key, format string, events []domain.Event) (domain.ArchiveFile, error) {
+	tmp, err := os.CreateTemp("", "quotesnap-archive-*")
+	if err != nil {
+		return domain.ArchiveFile{}, errors.Wrap(err, "create temp file")
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hasher := sha256.New()
+	counter := &countingWriter{}
+	if err := encoders[format](io.MultiWriter(tmp, hasher, counter), events); err != nil {
+		return domain.ArchiveFile{}, err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return domain.ArchiveFile{}, errors.Wrap(err, "rewind temp file")
+	}
+
+	file := domain.ArchiveFile{
+		Key:     key,
+		Format:  format,
+		Size:    counter.n,
+		SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+		Records: len(events),
+	}
+	if err := w.store.Put(ctx, key, tmp, file.Size); err != nil {
+		return domain.ArchiveFile{}, err
+	}
+	if err := w.verify(ctx, key, file.Size, file.SHA256); err != nil {
+		return domain.ArchiveFile{}, err
+	}
+	return file, nil
+}
+
+// verify reads key back from the store and compares its size and checksum.
+func (w *Writer) verify(ctx context.Context, key string, size int64, checksum string) error {
+	rc, err := w.store.Open(ctx, key)
+	if err != nil {
+		return errors.Wrapf(err, "verify %s", key)
+	}
+	defer rc.Close()
+
+	hasher := sha256.New()
+	n, err := io.Copy(hasher, rc)
+	if err != nil {
+		return errors.Wrapf(err, "verify %s", key)
+	}
+	if n != size || hex.EncodeToString(hasher.Sum(nil)) != checksum {
+		return errors.Errorf("verify %s: stored object does not match local checksum", key)
+	}
+	return nil
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.n += int64(len(p))
+	return len(p), nil
+}
+
+// Ensure Writer satisfies the EventArchiveWriter dependency.
+var _ usecase.EventArchiveWriter = (*Writer)(nil)
@@ -0,0 +1,337 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// secretFileSuffix marks a setting holding the path of a file that contains a secret, following
+// the Docker and Kubernetes convention of mounting secrets as files.
+const secretFileSuffix = "_file"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// Errors lists every problem found while loading a configuration.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Flags holds the command-line flags that select and override the configuration.
+type Flags struct {
+	// Path is the configuration file; CONFIG_FILE names it when empty.
+	Path string
+	// PrintConfig asks the command to print the effective configuration.
+	PrintConfig bool
+
+	overrides []string
+}
+
+// RegisterFlags adds -config, -set and -print-config to fs.
+func RegisterFlags(fs *flag.FlagSet) *Flags {
+	f := &Flags{}
+	fs.StringVar(&f.Path, "config", "", "YAML or TOML configuration file (default $CONFIG_FILE)")
+	fs.Func("set", "override a setting as key=value, after the file and the environment; repeatable", func(value string) error {
+		if !strings.Contains(value, "=") {
+			return errors.New("expected key=value")
+		}
+		f.overrides = append(f.overrides, value)
+		return nil
+	})
+	fs.BoolVar(&f.PrintConfig, "print-config", false, "print the effective configuration with secrets redacted")
+	return f
+}
+
+// Load builds the configuration from the defaults, the configuration file, the environment and
+// the -set flags, each layer overriding the previous one. Every setting accepts the key of its
+// config tag in the file and -set, or the upper-cased key in the environment; secrets may instead
+// name a file holding the value, through the key suffixed by _file or _FILE. Empty environment
+// variables are ignored.
+//
+// Every unknown setting, unparsable value and failed validation is reported at once in Errors.
+// The returned Config holds whatever did parse, so it can still be printed.
+func (f *Flags) Load() (Config, error) {
+	cfg := defaults()
+	l := newLoader(&cfg)
+
+	path := f.Path
+	if path == "" {
+		path = os.Getenv("CONFIG_FILE")
+	}
+	if path != "" {
+		l.loadFile(path)
+	}
+	l.loadEnv()
+	l.loadOverrides(f.overrides)
+
+	l.errs = append(l.errs, cfg.problems()...)
+	if len(l.errs) > 0 {
+		return cfg, l.errs
+	}
+	return cfg, nil
+}
+
+// setting is one Config field addressed by its config tag.
+type setting struct {
+	key    string
+	secret string
+	value  reflect.Value
+}
+
+// settings lists the fields of cfg in declaration order.
+func settings(cfg *Config) []setting {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	out := make([]setting, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		out = append(out, setting{key: field.Tag.Get("config"), secret: field.Tag.Get("secret"), value: v.Field(i)})
+	}
+	return out
+}
+
+type loader struct {
+	ordered  []setting
+	settings map[string]setting
+	errs     Errors
+}
+
+func newLoader(cfg *Config) *loader {
+	l := &loader{ordered: settings(cfg), settings: make(map[string]setting)}
+	for _, s := range l.ordered {
+		l.settings[s.key] = s
+	}
+	return l
+}
+
+func (l *loader) fail(format string, args ...any) {
+	l.errs = append(l.errs, errors.Errorf(format, args...))
+}
+
+// loadFile applies a YAML or TOML file holding a flat table of settings.
+func (l *loader) loadFile(path string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		l.errs = append(l.errs, errors.Wrap(err, "read configuration file"))
+		return
+	}
+
+	values := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(raw, &values)
+	case ".toml":
+		err = toml.Unmarshal(raw, &values)
+	default:
+		l.fail("%s: unknown configuration format, expected .yaml, .yml or .toml", path)
+		return
+	}
+	if err != nil {
+		l.errs = append(l.errs, errors.Wrapf(err, "decode %s", path))
+		return
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		l.apply(path+": "+key, key, values[key], values)
+	}
+}
+
+// envAliases maps settings to the environment variables they were read from before being renamed.
+// An alias only applies when the current name is unset.
+var envAliases = map[string]string{
+	"worker_http_addr": "METRICS_ADDR",
+}
+
+// loadEnv applies the environment variables named after the settings.
+func (l *loader) loadEnv() {
+	for _, s := range l.ordered {
+		name := strings.ToUpper(s.key)
+		if value := os.Getenv(name); value != "" {
+			l.set(name, s, value)
+		} else if alias, ok := envAliases[s.key]; ok && os.Getenv(alias) != "" {
+			l.set(alias, s, os.Getenv(alias))
+		}
+		if s.secret == "" {
+			continue
+		}
+		if path := os.Getenv(name + strings.ToUpper(secretFileSuffix)); path != "" {
+			if os.Getenv(name) != "" {
+				l.fail("%s: set either %s or %s%s", name, name, name, strings.ToUpper(secretFileSuffix))
+				continue
+			}
+			l.setFromFile(name+strings.ToUpper(secretFileSuffix), s, path)
+		}
+	}
+}
+
+// loadOverrides applies the -set flags in order.
+func (l *loader) loadOverrides(overrides []string) {
+	for _, override := range overrides {
+		key, value, _ := strings.Cut(override, "=")
+		l.apply("-set "+key, strings.TrimSpace(key), value, nil)
+	}
+}
+
+// apply sets key, or reads the secret file of a _file key. layer holds the other values of the
+// same layer, to reject a secret given both directly and as a file.
+func (l *loader) apply(source, key string, value any, layer map[string]any) {
+	if s, ok := l.settings[key]; ok {
+		l.set(source, s, value)
+		return
+	}
+	base := strings.TrimSuffix(key, secretFileSuffix)
+	if s, ok := l.settings[base]; ok && base != key && s.secret != "" {
+		if _, both := layer[base]; both {
+			l.fail("%s: set either %s or %s", source, base, key)
+			return
+		}
+		path, ok := value.(string)
+		if !ok {
+			l.fail("%s: expected a file path", source)
+			return
+		}
+		l.setFromFile(source, s, path)
+		return
+	}
+	l.fail("%s: unknown setting", source)
+}
+
+func (l *loader) setFromFile(source string, s setting, path string) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		l.errs = append(l.errs, errors.Wrapf(err, "%s: read secret", source))
+		return
+	}
+	l.set(source, s, strings.TrimRight(string(raw), "\r\n"))
+}
+
+// set parses value into the setting. Values come as strings from the environment and flags, and
+// as decoded scalars or lists from files.
+func (l *loader) set(source string, s setting, value any) {
+	field := s.value
+	switch {
+	case field.Type() == durationType:
+		text, ok := value.(string)
+		if !ok {
+			l.fail("%s: expected a duration with a unit, such as 30s", source)
+			return
+		}
+		parsed, err := time.ParseDuration(strings.TrimSpace(text))
+		if err != nil {
+			l.fail("%s: invalid duration %q", source, text)
+			return
+		}
+		field.SetInt(int64(parsed))
+	case field.Kind() == reflect.String:
+		text, ok := scalar(value)
+		if !ok {
+			l.fail("%s: expected a single value", source)
+			return
+		}
+		field.SetString(text)
+	case field.Kind() == reflect.Int:
+		var parsed int64
+		var err error
+		switch v := value.(type) {
+		case int:
+			parsed = int64(v)
+		case int64:
+			parsed = v
+		case string:
+			parsed, err = strconv.ParseInt(strings.TrimSpace(v), 10, 0)
+		default:
+			err = errors.New("not an integer")
+		}
+		if err != nil {
+			l.fail("%s: invalid integer %v", source, quote(value))
+			return
+		}
+		field.SetInt(parsed)
+	case field.Kind() == reflect.Bool:
+		var parsed bool
+		var err error
+		switch v := value.(type) {
+		case bool:
+			parsed = v
+		case string:
+			parsed, err = strconv.ParseBool(strings.TrimSpace(v))
+		default:
+			err = errors.New("not a boolean")
+		}
+		if err != nil {
+			l.fail("%s: invalid boolean %v", source, quote(value))
+			return
+		}
+		field.SetBool(parsed)
+	case field.Kind() == reflect.Slice:
+		var items []string
+		switch v := value.(type) {
+		case string:
+			items = splitList(v)
+		case []any:
+			for _, item := range v {
+				text, ok := scalar(item)
+				if !ok {
+					l.fail("%s: expected a list of values", source)
+					return
+				}
+				items = append(items, text)
+			}
+		default:
+			l.fail("%s: expected a list or a comma-separated string", source)
+			return
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		panic(fmt.Sprintf("config: unsupported type %s for %s", field.Type(), s.key))
+	}
+}
+
+// scalar formats a single decoded value; tables and lists are rejected.
+func scalar(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int, int64, float64, bool:
+		return fmt.Sprint(v), true
+	}
+	return "", false
+}
+
+func quote(value any) string {
+	if text, ok := value.(string); ok {
+		return strconv.Quote(text)
+	}
+	return fmt.Sprint(value)
+}
+
+func splitList(value string) []string {
+	var out []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
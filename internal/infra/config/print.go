@@ -0,0 +1,59 @@
+package config
+
+import (
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// redacted replaces secret values in printed configurations.
+const redacted = "REDACTED"
+
+// Print writes the configuration as a YAML file Load accepts, with secrets redacted.
+func (c Config) Print(w io.Writer) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settings(&c) {
+		var value any
+		switch v := s.value.Interface().(type) {
+		case time.Duration:
+			value = v.String()
+		case string:
+			value = redact(s.secret, v)
+		default:
+			value = v
+		}
+		node := &yaml.Node{}
+		if err := node.Encode(value); err != nil {
+			return errors.Wrapf(err, "encode %s", s.key)
+		}
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: s.key}, node)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(root); err != nil {
+		return errors.Wrap(err, "write configuration")
+	}
+	return encoder.Close()
+}
+
+// redact hides a secret value. URL secrets keep everything but their password, which is enough
+// to tell which server a deployment talks to.
+func redact(secret, value string) string {
+	if secret == "" || value == "" {
+		return value
+	}
+	if secret == "url" {
+		if u, err := url.Parse(value); err == nil && u.Scheme != "" {
+			if _, ok := u.User.Password(); !ok {
+				return value
+			}
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+	}
+	return redacted
+}
@@ -0,0 +1,188 @@
+package config
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// Validate checks every setting and reports all problems at once in Errors. Load already
+// validates; Validate serves configurations built or changed in code.
+func (c Config) Validate() error {
+	if errs := c.problems(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (c Config) problems() Errors {
+	v := &validator{}
+
+	v.required("app_name", c.AppName)
+	v.required("mongo_uri", c.MongoURI)
+	v.required("mongo_database", c.MongoDatabase)
+	v.required("redis_addr", c.RedisAddr)
+	v.required("asynq_queue", c.AsynqQueue)
+	v.required("asynq_replay_queue", c.AsynqReplayQueue)
+	// Dead letters are told apart by the queue holding them, so replays need a queue of their own.
+	if c.AsynqReplayQueue != "" && c.AsynqReplayQueue == c.AsynqQueue {
+		v.fail("asynq_replay_queue", "must differ from asynq_queue (%s)", c.AsynqQueue)
+	}
+	v.required("event_stream_channel", c.EventStreamChannel)
+	if port, err := strconv.Atoi(c.HTTPPort); err != nil || port < 1 || port > 65535 {
+		v.fail("http_port", "must be a port number, got %q", c.HTTPPort)
+	}
+
+	// Values mirror the constants of the adapters that consume them.
+	v.oneOf("bot_action", c.BotAction, "flag", "drop")
+	v.oneOf("geoip_ip_mode", c.GeoIPIPMode, "keep", "truncate", "drop")
+	v.oneOf("consent_action", c.ConsentAction, string(domain.ConsentActionDrop), string(domain.ConsentActionAnonymize))
+	v.oneOf("consent_default", c.ConsentDefault, "granted", "denied")
+	v.oneOf("archive_store", c.ArchiveStore, "local", "s3")
+	v.oneOf("mongo_event_storage", c.MongoEventStorage, "collection", "timeseries")
+	v.oneOf("mongo_timeseries_granularity", c.MongoTimeSeriesGranularity, "seconds", "minutes", "hours")
+	v.oneOf("queue_backend", c.QueueBackend, QueueBackendAsynq, QueueBackendRedisStream)
+	v.oneOf("event_store", c.EventStore, EventStoreMongo, EventStorePostgres, EventStoreSQLite)
+	if len(c.ArchiveFormats) == 0 {
+		v.fail("archive_formats", "at least one format is required")
+	}
+	for _, format := range c.ArchiveFormats {
+		v.oneOf("archive_formats", format, domain.ArchiveFormatNDJSON, domain.ArchiveFormatParquet)
+	}
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			v.fail("trusted_proxies", "must list IP addresses or CIDR ranges, got %q", proxy)
+		}
+	}
+
+	retention, retentionErr := c.RetentionPolicy()
+	if retentionErr != nil {
+		v.errs = append(v.errs, retentionErr)
+	}
+	archiveAge, archiveErr := c.ArchiveAge()
+	if archiveErr != nil {
+		v.errs = append(v.errs, archiveErr)
+	}
+	// Events expiring before they are archived would be lost instead of moved to cold storage.
+	if retentionErr == nil && archiveErr == nil && archiveAge > 0 {
+		if shortest := retention.Shortest(); shortest > 0 && shortest <= archiveAge {
+			v.fail("archive_after", "must be shorter than the shortest retention (%s), got %s", shortest, archiveAge)
+		}
+	}
+	if _, err := domain.ParseTrendingWeights(c.TrendingWeights); err != nil {
+		v.fail("trending_weights", "%v", err)
+	}
+
+	switch {
+	case c.ArchiveStore == "s3":
+		v.required("archive_s3_endpoint", c.ArchiveS3Endpoint)
+		v.required("archive_s3_bucket", c.ArchiveS3Bucket)
+	case c.ArchiveStore == "local":
+		v.required("archive_local_dir", c.ArchiveLocalDir)
+	}
+	switch c.EventStore {
+	case EventStorePostgres:
+		v.required("postgres_url", c.PostgresURL)
+	case EventStoreSQLite:
+		v.required("sqlite_path", c.SQLitePath)
+	}
+	if c.QueueBackend == QueueBackendRedisStream {
+		v.required("redis_stream_key", c.RedisStreamKey)
+		v.required("redis_stream_group", c.RedisStreamGroup)
+	}
+
+	for key, value := range map[string]int{
+		"asynq_concurrency":         c.AsynqConcurrency,
+		"archive_batch_size":        c.ArchiveBatchSize,
+		"replay_rate":               c.ReplayRate,
+		"event_stream_max_clients":  c.EventStreamMaxClients,
+		"webhook_max_attempts":      c.WebhookMaxAttempts,
+		"redis_stream_concurrency":  c.RedisStreamConcurrency,
+		"redis_stream_batch":        c.RedisStreamBatch,
+		"redis_stream_max_attempts": c.RedisStreamMaxAttempts,
+		"memory_queue_size":         c.MemoryQueueSize,
+		"memory_queue_workers":      c.MemoryQueueWorkers,
+		"memory_queue_attempts":     c.MemoryQueueAttempts,
+		"sqlite_batch_size":         c.SQLiteBatchSize,
+	} {
+		if value <= 0 {
+			v.fail(key, "must be positive, got %d", value)
+		}
+	}
+	if c.RedisStreamMaxLen < 0 {
+		v.fail("redis_stream_max_len", "must not be negative, got %d", c.RedisStreamMaxLen)
+	}
+
+	for key, value := range map[string]time.Duration{
+		"shutdown_timeout":           c.ShutdownTimeout,
+		"request_timeout":            c.RequestTimeout,
+		"geoip_reload_interval":      c.GeoIPReloadInterval,
+		"tombstone_refresh_interval": c.TombstoneRefreshInterval,
+		"retention_purge_interval":   c.RetentionPurgeInterval,
+		"archive_interval":           c.ArchiveInterval,
+		"trending_bucket":            c.TrendingBucket,
+		"trending_half_life":         c.TrendingHalfLife,
+		"trending_max_window":        c.TrendingMaxWindow,
+		"webhook_timeout":            c.WebhookTimeout,
+		"webhook_refresh_interval":   c.WebhookRefreshInterval,
+		"sink_health_interval":       c.SinkHealthInterval,
+		"redis_stream_claim_idle":    c.RedisStreamClaimIdle,
+		"memory_snapshot_interval":   c.MemorySnapshotInterval,
+		"sqlite_flush_interval":      c.SQLiteFlushInterval,
+		"readiness_timeout":          c.ReadinessTimeout,
+		"counted_event_retention":    c.CountedEventRetention,
+	} {
+		if value <= 0 {
+			v.fail(key, "must be positive, got %s", value)
+		}
+	}
+	// TTL indexes count whole seconds, so a shorter retention would expire documents at once.
+	for key, value := range map[string]time.Duration{
+		"poison_event_retention":     c.PoisonEventRetention,
+		"webhook_delivery_retention": c.WebhookDeliveryRetention,
+	} {
+		if value < time.Second {
+			v.fail(key, "must be at least 1s, got %s", value)
+		}
+	}
+	if c.ErasureDelay < 0 {
+		v.fail("erasure_delay", "must not be negative, got %s", c.ErasureDelay)
+	}
+	if c.TrendingMaxWindow < c.TrendingBucket {
+		v.fail("trending_max_window", "must be at least trending_bucket (%s)", c.TrendingBucket)
+	}
+
+	// The maps above iterate in random order; sorting by message, which starts with the key,
+	// keeps reports stable.
+	sort.Slice(v.errs, func(i, j int) bool { return v.errs[i].Error() < v.errs[j].Error() })
+	return v.errs
+}
+
+type validator struct {
+	errs Errors
+}
+
+func (v *validator) fail(key, format string, args ...any) {
+	v.errs = append(v.errs, errors.Errorf(key+": "+format, args...))
+}
+
+func (v *validator) required(key, value string) {
+	if strings.TrimSpace(value) == "" {
+		v.fail(key, "is required")
+	}
+}
+
+func (v *validator) oneOf(key, value string, allowed ...string) {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return
+		}
+	}
+	v.fail(key, "must be one of %s, got %q", strings.Join(allowed, ", "), value)
+}
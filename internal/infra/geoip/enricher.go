@@ -0,0 +1,78 @@
+package geoip
+
+import (
+	"context"
+	"net"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// IPModeKeep stores the client IP unchanged.
+	IPModeKeep = "keep"
+	// IPModeTruncate zeroes the host part of the IP (/24 for IPv4, /48 for IPv6) after lookup.
+	IPModeTruncate = "truncate"
+	// IPModeDrop removes the client IP after lookup.
+	IPModeDrop = "drop"
+)
+
+var (
+	ipv4Mask = net.CIDRMask(24, 32)
+	ipv6Mask = net.CIDRMask(48, 128)
+)
+
+// Enricher resolves event.IP into event.Geo and then applies the configured IP retention mode.
+type Enricher struct {
+	reader *Reader
+	ipMode string
+}
+
+// NewEnricher constructs an Enricher. A nil reader skips the lookup but still applies ipMode.
+func NewEnricher(reader *Reader, ipMode string) (*Enricher, error) {
+	switch ipMode {
+	case IPModeKeep, IPModeTruncate, IPModeDrop:
+	default:
+		return nil, errors.Errorf("unknown ip mode %q", ipMode)
+	}
+	return &Enricher{reader: reader, ipMode: ipMode}, nil
+}
+
+// Enrich attaches location details to the event.
+func (e *Enricher) Enrich(_ context.Context, event *domain.Event) error {
+	ip := net.ParseIP(event.IP)
+	if ip == nil {
+		return nil
+	}
+	defer e.scrub(event, ip)
+
+	if e.reader == nil {
+		return nil
+	}
+	geo, found, err := e.reader.Lookup(ip)
+	if err != nil {
+		return err
+	}
+	if found {
+		event.Geo = &geo
+	}
+	return nil
+}
+
+func (e *Enricher) scrub(event *domain.Event, ip net.IP) {
+	switch e.ipMode {
+	case IPModeDrop:
+		event.IP = ""
+	case IPModeTruncate:
+		if v4 := ip.To4(); v4 != nil {
+			event.IP = v4.Mask(ipv4Mask).String()
+			return
+		}
+		event.IP = ip.Mask(ipv6Mask).String()
+	}
+}
+
+// Ensure Enricher satisfies the EventEnricher dependency.
+var _ usecase.EventEnricher = (*Enricher)(nil)
@@ -0,0 +1,147 @@
+package geoip
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oschwald/maxminddb-golang"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+)
+
+// cityRecord mirrors the subset of the GeoIP2/GeoLite2 City schema used for enrichment.
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		ISOCode string            `maxminddb:"iso_code"`
+		Names   map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+}
+
+// Reader resolves IP addresses against a local MaxMind-format database and transparently
+// reloads it when the file on disk changes.
+type Reader struct {
+	path   string
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	db      *maxminddb.Reader
+	modTime time.Time
+	size    int64
+}
+
+// Open loads the database at path.
+func Open(path string, logger *slog.Logger) (*Reader, error) {
+	r := &Reader{path: path, logger: logger.With("component", "geoip")}
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Lookup resolves ip into a Geo record. The boolean is false when the address is unknown.
+func (r *Reader) Lookup(ip net.IP) (domain.Geo, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var record cityRecord
+	_, found, err := r.db.LookupNetwork(ip, &record)
+	if err != nil {
+		return domain.Geo{}, false, errors.Wrap(err, "lookup ip")
+	}
+	if !found {
+		return domain.Geo{}, false, nil
+	}
+
+	geo := domain.Geo{
+		Country: record.Country.ISOCode,
+		City:    record.City.Names["en"],
+	}
+	if len(record.Subdivisions) > 0 {
+		geo.Region = record.Subdivisions[0].Names["en"]
+		if geo.Region == "" {
+			geo.Region = record.Subdivisions[0].ISOCode
+		}
+	}
+	return geo, true, nil
+}
+
+// Watch polls the database file every interval and reloads it when its size or modification
+// time changes. It blocks until ctx is cancelled.
+func (r *Reader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := r.changed()
+			if err != nil {
+				r.logger.Warn("geoip database stat failed", "path", r.path, "error", err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			if err := r.load(); err != nil {
+				r.logger.Error("geoip database reload failed", "path", r.path, "error", err)
+				continue
+			}
+			r.logger.Info("geoip database reloaded", "path", r.path)
+		}
+	}
+}
+
+// Close releases the underlying database.
+func (r *Reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.db.Close()
+}
+
+func (r *Reader) changed() (bool, error) {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return !info.ModTime().Equal(r.modTime) || info.Size() != r.size, nil
+}
+
+func (r *Reader) load() error {
+	info, err := os.Stat(r.path)
+	if err != nil {
+		return errors.Wrap(err, "stat geoip database")
+	}
+	db, err := maxminddb.Open(r.path)
+	if err != nil {
+		return errors.Wrap(err, "open geoip database")
+	}
+
+	r.mu.Lock()
+	previous := r.db
+	r.db = db
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	r.mu.Unlock()
+
+	if previous != nil {
+		if err := previous.Close(); err != nil {
+			r.logger.Warn("geoip database close failed", "error", err)
+		}
+	}
+	return nil
+}
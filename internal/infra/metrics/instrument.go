@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// instrumentedQueue records enqueue latency and errors of an EventQueue.
+type instrumentedQueue struct {
+	queue   usecase.EventQueue
+	backend string
+}
+
+// InstrumentQueue wraps queue so every Enqueue is measured under the backend label.
+func InstrumentQueue(queue usecase.EventQueue, backend string) usecase.EventQueue {
+	return &instrumentedQueue{queue: queue, backend: backend}
+}
+
+// Enqueue delegates to the wrapped queue.
+func (q *instrumentedQueue) Enqueue(ctx context.Context, event domain.Event) error {
+	started := time.Now()
+	err := q.queue.Enqueue(ctx, event)
+	enqueueDuration.WithLabelValues(q.backend).Observe(time.Since(started).Seconds())
+	if err != nil {
+		enqueueErrors.WithLabelValues(q.backend).Inc()
+	}
+	return err
+}
+
+// instrumentedObserver counts failures of an EventObserver.
+type instrumentedObserver struct {
+	observer usecase.EventObserver
+	name     string
+}
+
+// InstrumentObserver wraps observer so its failures are counted under the observer label.
+func InstrumentObserver(observer usecase.EventObserver, name string) usecase.EventObserver {
+	return &instrumentedObserver{observer: observer, name: name}
+}
+
+// Observe delegates to the wrapped observer.
+func (o *instrumentedObserver) Observe(ctx context.Context, event domain.Event) error {
+	err := o.observer.Observe(ctx, event)
+	if err != nil {
+		observerErrors.WithLabelValues(o.name).Inc()
+	}
+	return err
+}
+
+// instrumentedRepository records write latency of an EventRepository.
+type instrumentedRepository struct {
+	repo  usecase.EventRepository
+	store string
+}
+
+// InstrumentEventRepository wraps repo so every write is measured under the store label.
+func InstrumentEventRepository(repo usecase.EventRepository, store string) usecase.EventRepository {
+	return &instrumentedRepository{repo: repo, store: store}
+}
+
+// Persist delegates to the wrapped repository. A duplicate counts as a success, since the event is
+// stored.
+func (r *instrumentedRepository) Persist(ctx context.Context, event domain.Event) error {
+	started := time.Now()
+	err := r.repo.Persist(ctx, event)
+	result := err
+	if errors.Is(err, usecase.ErrDuplicateEvent) {
+		result = nil
+	}
+	storeWriteDuration.WithLabelValues(r.store, "persist", outcome(result)).Observe(time.Since(started).Seconds())
+	return err
+}
+
+// Upsert delegates to the wrapped repository.
+func (r *instrumentedRepository) Upsert(ctx context.Context, event domain.Event) error {
+	started := time.Now()
+	err := r.repo.Upsert(ctx, event)
+	storeWriteDuration.WithLabelValues(r.store, "upsert", outcome(err)).Observe(time.Since(started).Seconds())
+	return err
+}
+
+// AsynqMiddleware measures the processing time and outcome of every Asynq task.
+func AsynqMiddleware(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		started := time.Now()
+		err := next.ProcessTask(ctx, task)
+		taskDuration.WithLabelValues(task.Type(), outcome(err)).Observe(time.Since(started).Seconds())
+		return err
+	})
+}
+
+// InstrumentEventHandler measures an event handler fed by a transport other than Asynq, reporting
+// it under taskType like the equivalent Asynq task.
+func InstrumentEventHandler(taskType string, handler func(context.Context, domain.Event) error) func(context.Context, domain.Event) error {
+	return func(ctx context.Context, event domain.Event) error {
+		started := time.Now()
+		err := handler(ctx, event)
+		taskDuration.WithLabelValues(taskType, outcome(err)).Observe(time.Since(started).Seconds())
+		return err
+	}
+}
+
+// PersistDelay is the EventObserver recording how long events took from reception to storage.
+// It should be the first observer so other observers do not inflate the delay.
+type PersistDelay struct{}
+
+// NewPersistDelay constructs a PersistDelay observer.
+func NewPersistDelay() *PersistDelay {
+	return &PersistDelay{}
+}
+
+// Observe records the delay of event.
+func (PersistDelay) Observe(_ context.Context, event domain.Event) error {
+	if !event.ReceivedAt.IsZero() {
+		persistDelay.Observe(time.Since(event.ReceivedAt).Seconds())
+	}
+	return nil
+}
+
+// Ensure the decorators satisfy the dependencies they wrap.
+var (
+	_ usecase.EventQueue      = (*instrumentedQueue)(nil)
+	_ usecase.EventRepository = (*instrumentedRepository)(nil)
+	_ usecase.EventObserver   = (*PersistDelay)(nil)
+)
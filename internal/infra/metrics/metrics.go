@@ -0,0 +1,118 @@
+// Package metrics exposes Prometheus metrics for the ingest and worker pipelines.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "quotesnap"
+
+// Outcome label values.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+var registry = prometheus.NewRegistry()
+
+var (
+	httpRequests = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests by method, route and status.",
+	}, []string{"method", "route", "status"})
+
+	httpDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by method, route and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	enqueueDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "queue_enqueue_duration_seconds",
+		Help:      "Latency of enqueueing ingested events by queue backend.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"backend"})
+
+	enqueueErrors = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "queue_enqueue_errors_total",
+		Help:      "Failed enqueues of ingested events by queue backend.",
+	}, []string{"backend"})
+
+	taskDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "task_duration_seconds",
+		Help:      "Worker task processing time by task type and outcome.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"type", "outcome"})
+
+	storeWriteDuration = promauto.With(registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_store_write_duration_seconds",
+		Help:      "Event store write latency by store, operation and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"store", "operation", "outcome"})
+
+	observerErrors = promauto.With(registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "event_observer_errors_total",
+		Help:      "Failed observer notifications after an event was stored, by observer. They are not retried.",
+	}, []string{"observer"})
+
+	persistDelay = promauto.With(registry).NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "event_persist_delay_seconds",
+		Help:      "Delay between an event being received and being persisted.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 2, 20),
+	})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves every registered metric in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry})
+}
+
+// Register adds a custom collector, such as a QueueCollector, to the exported metrics.
+func Register(collector prometheus.Collector) error {
+	return registry.Register(collector)
+}
+
+// HTTPRecorder records served HTTP requests.
+type HTTPRecorder struct{}
+
+// NewHTTPRecorder constructs an HTTPRecorder.
+func NewHTTPRecorder() HTTPRecorder {
+	return HTTPRecorder{}
+}
+
+// ObserveHTTPRequest records one served request. route is the matched route pattern, never the
+// raw path, to keep label cardinality bounded.
+func (HTTPRecorder) ObserveHTTPRequest(method, route string, status int, elapsed time.Duration) {
+	code := strconv.Itoa(status)
+	httpRequests.WithLabelValues(method, route, code).Inc()
+	httpDuration.WithLabelValues(method, route, code).Observe(elapsed.Seconds())
+}
+
+func outcome(err error) string {
+	if err != nil {
+		return OutcomeFailure
+	}
+	return OutcomeSuccess
+}
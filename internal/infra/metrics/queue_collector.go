@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	queueTasksDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "asynq", "queue_tasks"),
+		"Tasks in an Asynq queue by state.", []string{"queue", "state"}, nil)
+	queueLatencyDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "asynq", "queue_latency_seconds"),
+		"Age of the oldest pending task in an Asynq queue.", []string{"queue"}, nil)
+	queueScrapeErrorDesc = prometheus.NewDesc(prometheus.BuildFQName(namespace, "asynq", "queue_scrape_error"),
+		"Whether reading the queue state from Redis failed during the last scrape.", []string{"queue"}, nil)
+)
+
+// QueueCollector reports Asynq queue depth and lag, read through the Inspector at scrape time.
+type QueueCollector struct {
+	inspector *asynq.Inspector
+	queues    []string
+}
+
+// NewQueueCollector constructs a QueueCollector for queues.
+func NewQueueCollector(inspector *asynq.Inspector, queues ...string) *QueueCollector {
+	return &QueueCollector{inspector: inspector, queues: queues}
+}
+
+// Describe implements prometheus.Collector.
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueTasksDesc
+	ch <- queueLatencyDesc
+	ch <- queueScrapeErrorDesc
+}
+
+// Collect implements prometheus.Collector. A queue that has never held a task does not exist yet
+// and is reported as empty.
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, queue := range c.queues {
+		info, err := c.inspector.GetQueueInfo(queue)
+		if err != nil && !isQueueNotFound(err) {
+			ch <- prometheus.MustNewConstMetric(queueScrapeErrorDesc, prometheus.GaugeValue, 1, queue)
+			continue
+		}
+		ch <- prometheus.MustNewConstMetric(queueScrapeErrorDesc, prometheus.GaugeValue, 0, queue)
+		if info == nil {
+			info = &asynq.QueueInfo{}
+		}
+
+		for state, count := range map[string]int{
+			"pending":   info.Pending,
+			"active":    info.Active,
+			"scheduled": info.Scheduled,
+			"retry":     info.Retry,
+			"archived":  info.Archived,
+			"completed": info.Completed,
+		} {
+			ch <- prometheus.MustNewConstMetric(queueTasksDesc, prometheus.GaugeValue, float64(count), queue, state)
+		}
+		ch <- prometheus.MustNewConstMetric(queueLatencyDesc, prometheus.GaugeValue, info.Latency.Seconds(), queue)
+	}
+}
+
+func isQueueNotFound(err error) bool {
+	return errors.Is(err, asynq.ErrQueueNotFound)
+}
+
+// Ensure QueueCollector satisfies prometheus.Collector.
+var _ prometheus.Collector = (*QueueCollector)(nil)
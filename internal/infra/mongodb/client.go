@@ -6,14 +6,17 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
 )
 
-// Connect initialises a MongoDB client with sensible defaults for connection pooling.
+// Connect initialises a MongoDB client with sensible defaults for connection pooling. Every command
+// is traced through the global tracer provider.
 func Connect(ctx context.Context, uri string) (*mongo.Client, error) {
 	clientOpts := options.Client().ApplyURI(uri)
 	clientOpts.SetServerSelectionTimeout(5 * time.Second)
 	clientOpts.SetMaxPoolSize(200)
 	clientOpts.SetMinPoolSize(10)
+	clientOpts.SetMonitor(otelmongo.NewMonitor())
 
 	return mongo.Connect(ctx, clientOpts)
 }
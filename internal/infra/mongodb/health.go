@@ -0,0 +1,34 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// HealthCheck probes MongoDB for readiness.
+type HealthCheck struct {
+	client *mongo.Client
+}
+
+// NewHealthCheck constructs a HealthCheck for client.
+func NewHealthCheck(client *mongo.Client) *HealthCheck {
+	return &HealthCheck{client: client}
+}
+
+// Name identifies the dependency in readiness reports.
+func (h *HealthCheck) Name() string {
+	return "mongo"
+}
+
+// Ping checks that the primary answers, since every write goes there.
+func (h *HealthCheck) Ping(ctx context.Context) error {
+	return errors.Wrap(h.client.Ping(ctx, readpref.Primary()), "ping mongodb")
+}
+
+// Ensure HealthCheck satisfies the DependencyCheck dependency.
+var _ usecase.DependencyCheck = (*HealthCheck)(nil)
@@ -0,0 +1,130 @@
+package asynq
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// deadLetterPageSize is the number of archived tasks read from Redis per request.
+const deadLetterPageSize = 100
+
+// DeadLetterQueue exposes the event tasks of one type Asynq archived after their last retry.
+type DeadLetterQueue struct {
+	inspector *asynq.Inspector
+	client    *asynq.Client
+	queue     string
+	taskType  string
+}
+
+// NewDeadLetterQueue constructs a DeadLetterQueue over the archived event ingest tasks of queue.
+func NewDeadLetterQueue(inspector *asynq.Inspector, client *asynq.Client, queue string) *DeadLetterQueue {
+	return &DeadLetterQueue{inspector: inspector, client: client, queue: queue, taskType: EventIngestTaskType}
+}
+
+// NewReplayDeadLetterQueue constructs a DeadLetterQueue over the archived event replay tasks of
+// queue.
+func NewReplayDeadLetterQueue(inspector *asynq.Inspector, client *asynq.Client, queue string) *DeadLetterQueue {
+	return &DeadLetterQueue{inspector: inspector, client: client, queue: queue, taskType: EventReplayTaskType}
+}
+
+// Name returns the name of the Asynq queue.
+func (q *DeadLetterQueue) Name() string {
+	return q.queue
+}
+
+// EachDeadLetter walks the archived event tasks, most recently failed first. Archived tasks of
+// other types sharing the queue are skipped.
+func (q *DeadLetterQueue) EachDeadLetter(ctx context.Context, fn func(domain.DeadLetter) error) error {
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		tasks, err := q.inspector.ListArchivedTasks(q.queue, asynq.Page(page), asynq.PageSize(deadLetterPageSize))
+		if errors.Is(err, asynq.ErrQueueNotFound) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "list archived tasks")
+		}
+		for _, info := range tasks {
+			if info.Type != q.taskType {
+				continue
+			}
+			lastError := info.LastErr
+			_, event, err := DecodeEvent(ctx, asynq.NewTask(info.Type, info.Payload))
+			if err != nil {
+				// An undecodable payload is still listed so it can be deleted.
+				lastError = err.Error()
+			}
+			if err := fn(domain.DeadLetter{
+				ID:           info.ID,
+				Event:        event,
+				LastError:    lastError,
+				ErrorClass:   domain.ErrorClassOf(lastError),
+				Retried:      info.Retried,
+				MaxRetry:     info.MaxRetry,
+				LastFailedAt: info.LastFailedAt,
+			}); err != nil {
+				return err
+			}
+		}
+		if len(tasks) < deadLetterPageSize {
+			return nil
+		}
+	}
+}
+
+// RedriveDeadLetter moves the archived task back to the pending state.
+func (q *DeadLetterQueue) RedriveDeadLetter(_ context.Context, id string) error {
+	return q.wrap(q.inspector.RunTask(q.queue, id), "run archived task")
+}
+
+// ReplaceDeadLetter enqueues event as a new task of the same type, in the trace of the archived one,
+// then deletes the archived task. The new task is enqueued first so a failure never loses the
+// event; a replay task gets a fresh id since the archived one still holds the event's.
+func (q *DeadLetterQueue) ReplaceDeadLetter(ctx context.Context, id string, event domain.Event) error {
+	info, err := q.inspector.GetTaskInfo(q.queue, id)
+	if err != nil {
+		return q.wrap(err, "get archived task")
+	}
+	traceCtx, _, err := DecodeEvent(ctx, asynq.NewTask(info.Type, info.Payload))
+	if err != nil {
+		traceCtx = ctx
+	}
+	var task *asynq.Task
+	opts := []asynq.Option{asynq.Queue(q.queue)}
+	if q.taskType == EventReplayTaskType {
+		task, err = NewEventReplayTask(event, uuid.NewString())
+	} else {
+		task, err = NewEventTask(traceCtx, event)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := q.client.EnqueueContext(ctx, task, opts...); err != nil {
+		return errors.Wrap(err, "enqueue edited event task")
+	}
+	return q.wrap(q.inspector.DeleteTask(q.queue, id), "delete archived task")
+}
+
+// DeleteDeadLetter discards the archived task.
+func (q *DeadLetterQueue) DeleteDeadLetter(_ context.Context, id string) error {
+	return q.wrap(q.inspector.DeleteTask(q.queue, id), "delete archived task")
+}
+
+// wrap maps a missing task to usecase.ErrNotFound.
+func (q *DeadLetterQueue) wrap(err error, msg string) error {
+	if errors.Is(err, asynq.ErrTaskNotFound) || errors.Is(err, asynq.ErrQueueNotFound) {
+		return errors.Wrap(usecase.ErrNotFound, msg)
+	}
+	return errors.Wrap(err, msg)
+}
+
+// Ensure DeadLetterQueue satisfies the DeadLetterQueue dependency.
+var _ usecase.DeadLetterQueue = (*DeadLetterQueue)(nil)
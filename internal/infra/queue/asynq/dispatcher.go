@@ -23,7 +23,7 @@ func NewDispatcher(client *asynq.Client, queue string) *Dispatcher {
 
 // Enqueue pushes the event onto the configured Asynq queue.
 func (d *Dispatcher) Enqueue(ctx context.Context, event domain.Event) error {
-	task, err := NewEventTask(event)
+	task, err := NewEventTask(ctx, event)
 	if err != nil {
 		return err
 	}
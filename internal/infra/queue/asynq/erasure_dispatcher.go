@@ -0,0 +1,40 @@
+package asynq
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// ErasureDispatcher schedules erasure requests on the configured Asynq queue.
+type ErasureDispatcher struct {
+	client *asynq.Client
+	queue  string
+	delay  time.Duration
+}
+
+// NewErasureDispatcher constructs a new ErasureDispatcher instance. Tasks are delayed by delay so
+// that every replica has picked up the user's tombstone before the erasure runs.
+func NewErasureDispatcher(client *asynq.Client, queue string, delay time.Duration) *ErasureDispatcher {
+	return &ErasureDispatcher{client: client, queue: queue, delay: delay}
+}
+
+// EnqueueErasure pushes the erasure task onto the queue.
+func (d *ErasureDispatcher) EnqueueErasure(ctx context.Context, requestID uuid.UUID) error {
+	task, err := NewUserErasureTask(requestID)
+	if err != nil {
+		return err
+	}
+	if _, err := d.client.EnqueueContext(ctx, task, asynq.Queue(d.queue), asynq.ProcessIn(d.delay)); err != nil {
+		return errors.Wrap(err, "enqueue erasure task")
+	}
+	return nil
+}
+
+// Ensure ErasureDispatcher satisfies the ErasureQueue dependency.
+var _ usecase.ErasureQueue = (*ErasureDispatcher)(nil)
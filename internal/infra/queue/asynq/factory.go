@@ -3,6 +3,8 @@ package asynq
 import (
 	"context"
 	"log/slog"
+	"math/rand"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
@@ -12,15 +14,52 @@ func NewClient(addr, password string) *asynq.Client {
 	return asynq.NewClient(asynq.RedisClientOpt{Addr: addr, Password: password})
 }
 
-// NewServer builds an Asynq server tuned for bursty event ingestion workloads.
-func NewServer(addr, password, queue string, concurrency int, logger *slog.Logger) *asynq.Server {
+// NewServer builds an Asynq server tuned for bursty event ingestion workloads. queues maps each
+// queue name to its priority weight.
+func NewServer(addr, password string, queues map[string]int, concurrency int, logger *slog.Logger) *asynq.Server {
 	redisOpt := asynq.RedisClientOpt{Addr: addr, Password: password}
 	config := asynq.Config{
-		Concurrency: concurrency,
-		Queues:      map[string]int{queue: 1},
+		Concurrency:    concurrency,
+		Queues:         queues,
+		RetryDelayFunc: retryDelay,
 		ErrorHandler: asynq.ErrorHandlerFunc(func(_ context.Context, task *asynq.Task, err error) {
 			logger.Error("asynq task failed", "type", task.Type(), "error", err)
 		}),
 	}
 	return asynq.NewServer(redisOpt, config)
 }
+
+// retryDelay backs webhook deliveries off exponentially from 10s, doubling per attempt up to an
+// hour, with up to 10% jitter. Other tasks back off from 5s, doubling up to ten minutes, with the
+// delay drawn from its upper half so replicas failing together do not retry in lockstep. Only
+// transient failures get here: tasks returning SkipRetry are archived straight away.
+func retryDelay(n int, _ error, task *asynq.Task) time.Duration {
+	if task.Type() == WebhookDeliveryTaskType {
+		delay := 10 * time.Second << min(n, 9)
+		if delay > time.Hour {
+			delay = time.Hour
+		}
+		return delay + time.Duration(rand.Int63n(int64(delay/10)+1))
+	}
+	delay := 5 * time.Second << min(n, 7)
+	if delay > 10*time.Minute {
+		delay = 10 * time.Minute
+	}
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// NewScheduler builds an Asynq scheduler used to enqueue periodic maintenance tasks.
+func NewScheduler(addr, password string, logger *slog.Logger) *asynq.Scheduler {
+	redisOpt := asynq.RedisClientOpt{Addr: addr, Password: password}
+	return asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		Location: time.UTC,
+		EnqueueErrorHandler: func(task *asynq.Task, _ []asynq.Option, err error) {
+			logger.Error("asynq scheduler enqueue failed", "type", task.Type(), "error", err)
+		},
+	})
+}
+
+// NewInspector builds an Asynq inspector used to read queue state.
+func NewInspector(addr, password string) *asynq.Inspector {
+	return asynq.NewInspector(asynq.RedisClientOpt{Addr: addr, Password: password})
+}
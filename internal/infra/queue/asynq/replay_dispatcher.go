@@ -0,0 +1,42 @@
+package asynq
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// ReplayDispatcher enqueues historical events on the dedicated replay queue. Every dispatcher is
+// one replay run.
+type ReplayDispatcher struct {
+	client *asynq.Client
+	queue  string
+	runID  string
+}
+
+// NewReplayDispatcher constructs a new ReplayDispatcher instance for a new replay run.
+func NewReplayDispatcher(client *asynq.Client, queue string) *ReplayDispatcher {
+	return &ReplayDispatcher{client: client, queue: queue, runID: uuid.NewString()}
+}
+
+// EnqueueReplay pushes the replay task onto the queue, returning usecase.ErrReplayQueued when this
+// run already enqueued the event.
+func (d *ReplayDispatcher) EnqueueReplay(ctx context.Context, event domain.Event) error {
+	task, err := NewEventReplayTask(event, d.runID)
+	if err != nil {
+		return err
+	}
+	_, err = d.client.EnqueueContext(ctx, task, asynq.Queue(d.queue))
+	if errors.Is(err, asynq.ErrTaskIDConflict) {
+		return errors.Wrapf(usecase.ErrReplayQueued, "event %s", event.ID)
+	}
+	return errors.Wrap(err, "enqueue replay task")
+}
+
+// Ensure ReplayDispatcher satisfies the ReplayQueue dependency.
+var _ usecase.ReplayQueue = (*ReplayDispatcher)(nil)
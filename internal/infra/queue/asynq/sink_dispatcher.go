@@ -0,0 +1,45 @@
+package asynq
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// SinkDispatcher schedules secondary sink writes on the configured Asynq queue.
+type SinkDispatcher struct {
+	client   *asynq.Client
+	queue    string
+	attempts map[string]int
+}
+
+// NewSinkDispatcher constructs a new SinkDispatcher instance. attempts holds the maximum number
+// of write attempts of each sink.
+func NewSinkDispatcher(client *asynq.Client, queue string, attempts map[string]int) *SinkDispatcher {
+	return &SinkDispatcher{client: client, queue: queue, attempts: attempts}
+}
+
+// EnqueueSinkWrite pushes the write task onto the queue. A write that is already pending, for
+// example because the event is being reprocessed, is skipped.
+func (d *SinkDispatcher) EnqueueSinkWrite(ctx context.Context, sink string, event domain.Event) error {
+	attempts, ok := d.attempts[sink]
+	if !ok {
+		return errors.Errorf("unknown sink %q", sink)
+	}
+	task, err := NewSinkWriteTask(sink, event, attempts)
+	if err != nil {
+		return err
+	}
+	_, err = d.client.EnqueueContext(ctx, task, asynq.Queue(d.queue))
+	if err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return errors.Wrap(err, "enqueue sink write task")
+	}
+	return nil
+}
+
+// Ensure SinkDispatcher satisfies the SinkQueue dependency.
+var _ usecase.SinkQueue = (*SinkDispatcher)(nil)
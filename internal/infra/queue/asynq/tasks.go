@@ -1,12 +1,16 @@
 package asynq
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/pkg/errors"
 
 	"quotesnap/internal/core/domain"
+	"quotesnap/internal/infra/tracing"
 )
 
 const (
@@ -14,20 +18,135 @@ const (
 	EventIngestTaskType = "tracking:event:ingest"
 )
 
-// NewEventTask transforms a domain event into an Asynq task.
-func NewEventTask(event domain.Event) (*asynq.Task, error) {
-	payload, err := json.Marshal(event)
+// eventPayload is the event alongside the trace context of the request that enqueued it. The event
+// fields stay at the top level so payloads enqueued before tracing existed still decode.
+type eventPayload struct {
+	domain.Event
+	TraceContext map[string]string `json:"trace_context,omitempty"`
+}
+
+// NewEventTask transforms a domain event into an Asynq task carrying the trace context of ctx.
+func NewEventTask(ctx context.Context, event domain.Event) (*asynq.Task, error) {
+	payload, err := json.Marshal(eventPayload{Event: event, TraceContext: tracing.Inject(ctx)})
 	if err != nil {
 		return nil, errors.Wrap(err, "marshal event payload")
 	}
 	return asynq.NewTask(EventIngestTaskType, payload, asynq.MaxRetry(5)), nil
 }
 
-// DecodeEvent recovers a domain event from an Asynq task payload.
-func DecodeEvent(task *asynq.Task) (domain.Event, error) {
-	var event domain.Event
-	if err := json.Unmarshal(task.Payload(), &event); err != nil {
-		return domain.Event{}, errors.Wrap(err, "unmarshal event payload")
+// DecodeEvent recovers a domain event from an Asynq task payload, returning ctx joined to the
+// trace the event was enqueued in.
+func DecodeEvent(ctx context.Context, task *asynq.Task) (context.Context, domain.Event, error) {
+	var payload eventPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return ctx, domain.Event{}, errors.Wrap(err, "unmarshal event payload")
+	}
+	return tracing.Extract(ctx, payload.TraceContext), payload.Event, nil
+}
+
+// UserErasureTaskType identifies tasks that erase a user's data.
+const UserErasureTaskType = "tracking:user:erase"
+
+type userErasurePayload struct {
+	RequestID uuid.UUID `json:"request_id"`
+}
+
+// NewUserErasureTask builds the task that executes the given erasure request.
+func NewUserErasureTask(requestID uuid.UUID) (*asynq.Task, error) {
+	payload, err := json.Marshal(userErasurePayload{RequestID: requestID})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal erasure payload")
+	}
+	return asynq.NewTask(UserErasureTaskType, payload, asynq.MaxRetry(10), asynq.TaskID("erase:"+requestID.String())), nil
+}
+
+// DecodeUserErasure recovers the erasure request id from an Asynq task payload.
+func DecodeUserErasure(task *asynq.Task) (uuid.UUID, error) {
+	var payload userErasurePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return uuid.Nil, errors.Wrap(err, "unmarshal erasure payload")
+	}
+	return payload.RequestID, nil
+}
+
+// EventPurgeTaskType identifies periodic tasks that delete expired events.
+const EventPurgeTaskType = "tracking:events:purge"
+
+// NewEventPurgeTask builds the periodic retention purge task.
+func NewEventPurgeTask() *asynq.Task {
+	return asynq.NewTask(EventPurgeTaskType, nil, asynq.MaxRetry(3))
+}
+
+// EventArchiveTaskType identifies periodic tasks that move aged events to cold storage.
+const EventArchiveTaskType = "tracking:events:archive"
+
+// NewEventArchiveTask builds the periodic archival task. timeout bounds a single run.
+func NewEventArchiveTask(timeout time.Duration) *asynq.Task {
+	return asynq.NewTask(EventArchiveTaskType, nil, asynq.MaxRetry(3), asynq.Timeout(timeout))
+}
+
+// EventReplayTaskType identifies tasks that reprocess a historical event.
+const EventReplayTaskType = "tracking:event:replay"
+
+// NewEventReplayTask builds the replay task for event within the replay run runID. The task id is
+// derived from both, so a run enqueues an event at most once while later runs replay it again.
+func NewEventReplayTask(event domain.Event, runID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal event payload")
+	}
+	return asynq.NewTask(EventReplayTaskType, payload, asynq.MaxRetry(5), asynq.TaskID("replay:"+runID+":"+event.ID.String())), nil
+}
+
+// WebhookDeliveryTaskType identifies tasks that deliver a webhook.
+const WebhookDeliveryTaskType = "tracking:webhook:deliver"
+
+type webhookDeliveryPayload struct {
+	DeliveryID uuid.UUID `json:"delivery_id"`
+}
+
+// NewWebhookDeliveryTask builds the task that performs the given delivery, attempting it at most
+// maxAttempts times.
+func NewWebhookDeliveryTask(deliveryID uuid.UUID, maxAttempts int) (*asynq.Task, error) {
+	payload, err := json.Marshal(webhookDeliveryPayload{DeliveryID: deliveryID})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal webhook delivery payload")
+	}
+	return asynq.NewTask(WebhookDeliveryTaskType, payload, asynq.MaxRetry(maxAttempts-1), asynq.TaskID("webhook:"+deliveryID.String())), nil
+}
+
+// DecodeWebhookDelivery recovers the delivery id from an Asynq task payload.
+func DecodeWebhookDelivery(task *asynq.Task) (uuid.UUID, error) {
+	var payload webhookDeliveryPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return uuid.Nil, errors.Wrap(err, "unmarshal webhook delivery payload")
+	}
+	return payload.DeliveryID, nil
+}
+
+// SinkWriteTaskType identifies tasks that write a persisted event to one secondary sink.
+const SinkWriteTaskType = "tracking:sink:write"
+
+type sinkWritePayload struct {
+	Sink  string       `json:"sink"`
+	Event domain.Event `json:"event"`
+}
+
+// NewSinkWriteTask builds the task that writes event to sink, attempting it at most maxAttempts
+// times. The task id is per sink and event so each sink retries independently.
+func NewSinkWriteTask(sink string, event domain.Event, maxAttempts int) (*asynq.Task, error) {
+	payload, err := json.Marshal(sinkWritePayload{Sink: sink, Event: event})
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal sink write payload")
+	}
+	return asynq.NewTask(SinkWriteTaskType, payload, asynq.MaxRetry(maxAttempts-1), asynq.TaskID("sink:"+sink+":"+event.ID.String())), nil
+}
+
+// DecodeSinkWrite recovers the sink name and event from an Asynq task payload.
+func DecodeSinkWrite(task *asynq.Task) (string, domain.Event, error) {
+	var payload sinkWritePayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return "", domain.Event{}, errors.Wrap(err, "unmarshal sink write payload")
 	}
-	return event, nil
+	return payload.Sink, payload.Event, nil
 }
@@ -0,0 +1,39 @@
+package asynq
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// WebhookDispatcher schedules webhook deliveries on the configured Asynq queue.
+type WebhookDispatcher struct {
+	client      *asynq.Client
+	queue       string
+	maxAttempts int
+}
+
+// NewWebhookDispatcher constructs a new WebhookDispatcher instance.
+func NewWebhookDispatcher(client *asynq.Client, queue string, maxAttempts int) *WebhookDispatcher {
+	return &WebhookDispatcher{client: client, queue: queue, maxAttempts: maxAttempts}
+}
+
+// EnqueueWebhookDelivery pushes the delivery task onto the queue. A delivery whose task is already
+// queued is skipped.
+func (d *WebhookDispatcher) EnqueueWebhookDelivery(ctx context.Context, deliveryID uuid.UUID) error {
+	task, err := NewWebhookDeliveryTask(deliveryID, d.maxAttempts)
+	if err != nil {
+		return err
+	}
+	if _, err := d.client.EnqueueContext(ctx, task, asynq.Queue(d.queue)); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		return errors.Wrap(err, "enqueue webhook delivery task")
+	}
+	return nil
+}
+
+// Ensure WebhookDispatcher satisfies the WebhookQueue dependency.
+var _ usecase.WebhookQueue = (*WebhookDispatcher)(nil)
@@ -0,0 +1,110 @@
+package memory
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// ErrQueueClosed is returned by Enqueue once the queue has been closed.
+var ErrQueueClosed = errors.New("queue closed")
+
+// Handler processes one dequeued event.
+type Handler func(ctx context.Context, event domain.Event) error
+
+// Queue is an in-process EventQueue backed by a bounded channel drained by a pool of workers. It
+// is meant for single-binary deployments: queued events are lost if the process dies.
+type Queue struct {
+	events   chan domain.Event
+	handler  Handler
+	attempts int
+	logger   *slog.Logger
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewQueue starts workers goroutines draining a queue of at most capacity events. Failed events are
+// retried up to attempts times in total before being dropped.
+func NewQueue(capacity, workers, attempts int, handler Handler, logger *slog.Logger) *Queue {
+	q := &Queue{
+		events:   make(chan domain.Event, capacity),
+		handler:  handler,
+		attempts: max(attempts, 1),
+		logger:   logger.With("component", "memory_queue"),
+	}
+	for i := 0; i < max(workers, 1); i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+	return q
+}
+
+// Enqueue adds event to the queue, waiting for room until ctx is done when the queue is full.
+func (q *Queue) Enqueue(ctx context.Context, event domain.Event) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		return ErrQueueClosed
+	}
+	select {
+	case q.events <- event:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "queue full")
+	}
+}
+
+// Len returns the number of events waiting to be processed.
+func (q *Queue) Len() int {
+	return len(q.events)
+}
+
+// Close stops accepting events and waits until the queued ones have been processed.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.events)
+	}
+	q.mu.Unlock()
+	q.wg.Wait()
+}
+
+func (q *Queue) work() {
+	defer q.wg.Done()
+	for event := range q.events {
+		q.process(event)
+	}
+}
+
+// process runs the handler, backing off linearly between attempts. Permanent failures are not
+// retried.
+func (q *Queue) process(event domain.Event) {
+	for attempt := 1; ; attempt++ {
+		err := q.handler(context.Background(), event)
+		if err == nil {
+			return
+		}
+		if usecase.IsPermanent(err) {
+			q.logger.Error("dropping event after permanent failure", "event_id", event.ID, "error", err)
+			return
+		}
+		if attempt >= q.attempts {
+			q.logger.Error("dropping event after failed attempts", "event_id", event.ID, "attempts", attempt, "error", err)
+			return
+		}
+		q.logger.Warn("event processing failed, retrying", "event_id", event.ID, "attempt", attempt, "error", err)
+		time.Sleep(time.Duration(attempt) * 100 * time.Millisecond)
+	}
+}
+
+// Ensure Queue satisfies the EventQueue dependency.
+var _ usecase.EventQueue = (*Queue)(nil)
@@ -0,0 +1,305 @@
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// Handler processes one event read from the stream. Returning an error leaves the entry pending so
+// it is claimed and retried later, unless the error is permanent.
+type Handler func(ctx context.Context, event domain.Event) error
+
+// ConsumerConfig tunes a Consumer.
+type ConsumerConfig struct {
+	Stream string
+	Group  string
+	// Name identifies this consumer within the group and must be unique per process.
+	Name        string
+	Concurrency int
+	// Batch is the number of entries fetched per read or claim.
+	Batch int64
+	// ClaimIdle is how long an entry stays pending before another read claims it, which is both
+	// the retry delay of failed entries and the recovery delay of entries held by dead consumers.
+	ClaimIdle time.Duration
+	// MaxAttempts bounds deliveries of an entry before it moves to the dead-letter stream.
+	MaxAttempts int
+	// HandlerTimeout bounds a single Handler call.
+	HandlerTimeout time.Duration
+}
+
+// readBlock bounds each blocking read so shutdown is noticed promptly.
+const readBlock = 2 * time.Second
+
+// Consumer reads events from a Redis stream through a consumer group. Entries are acknowledged
+// once handled; failed entries stay pending and are reclaimed with XAUTOCLAIM after ClaimIdle,
+// which also recovers entries of consumers that died mid-batch. Entries every group has
+// acknowledged are trimmed at the same pace. Requires Redis 6.2 or newer.
+type Consumer struct {
+	client  *redis.Client
+	config  ConsumerConfig
+	handler Handler
+	logger  *slog.Logger
+}
+
+// NewConsumer constructs a Consumer.
+func NewConsumer(client *redis.Client, config ConsumerConfig, handler Handler, logger *slog.Logger) *Consumer {
+	return &Consumer{client: client, config: config, handler: handler, logger: logger.With("component", "stream_consumer")}
+}
+
+// DeadLetterStream returns the stream receiving entries that ran out of attempts.
+func (c *Consumer) DeadLetterStream() string {
+	return deadLetterStream(c.config.Stream)
+}
+
+// deadLetterStream names the dead-letter stream of stream.
+func deadLetterStream(stream string) string {
+	return stream + ":dead"
+}
+
+// Run consumes the stream until ctx is cancelled and in-flight entries are handled.
+func (c *Consumer) Run(ctx context.Context) error {
+	if c.config.Concurrency <= 0 || c.config.Batch <= 0 || c.config.ClaimIdle <= 0 || c.config.MaxAttempts <= 0 {
+		return errors.New("stream consumer concurrency, batch, claim idle and max attempts must be positive")
+	}
+	err := c.client.XGroupCreateMkStream(ctx, c.config.Stream, c.config.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return errors.Wrap(err, "create consumer group")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.config.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.read(ctx)
+		}()
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.claim(ctx)
+	}()
+	wg.Wait()
+	return nil
+}
+
+// read handles new entries delivered to this consumer.
+func (c *Consumer) read(ctx context.Context) {
+	for ctx.Err() == nil {
+		streams, err := c.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    c.config.Group,
+			Consumer: c.config.Name,
+			Streams:  []string{c.config.Stream, ">"},
+			Count:    c.config.Batch,
+			Block:    readBlock,
+		}).Result()
+		if err != nil {
+			if !errors.Is(err, redis.Nil) && ctx.Err() == nil {
+				c.logger.Warn("stream read failed", "error", err)
+				sleep(ctx, time.Second)
+			}
+			continue
+		}
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				c.handle(ctx, message, 1)
+			}
+		}
+	}
+}
+
+// claim periodically takes over entries pending longer than ClaimIdle and handles them again.
+func (c *Consumer) claim(ctx context.Context) {
+	ticker := time.NewTicker(c.config.ClaimIdle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		start := "0-0"
+		for ctx.Err() == nil {
+			messages, next, err := c.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+				Stream:   c.config.Stream,
+				Group:    c.config.Group,
+				Consumer: c.config.Name,
+				MinIdle:  c.config.ClaimIdle,
+				Start:    start,
+				Count:    c.config.Batch,
+			}).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("stream claim failed", "error", err)
+				}
+				break
+			}
+			if len(messages) > 0 {
+				deliveries, err := c.deliveries(ctx, messages)
+				if err != nil {
+					c.logger.Warn("failed to read delivery counts", "error", err)
+					break
+				}
+				for _, message := range messages {
+					c.handle(ctx, message, deliveries[message.ID])
+				}
+			}
+			if next == "0-0" {
+				break
+			}
+			start = next
+		}
+		c.trim(ctx)
+	}
+}
+
+// trim removes the entries older than the oldest one any group still needs: its oldest pending
+// entry, or its last delivered one when nothing is pending. Trimming is approximate, so Redis may
+// keep a few more entries but never removes a newer one.
+func (c *Consumer) trim(ctx context.Context) {
+	groups, err := c.client.XInfoGroups(ctx, c.config.Stream).Result()
+	if err != nil {
+		if ctx.Err() == nil {
+			c.logger.Warn("failed to read consumer groups", "error", err)
+		}
+		return
+	}
+	minID := ""
+	for _, group := range groups {
+		needed := group.LastDeliveredID
+		if group.Pending > 0 {
+			pending, err := c.client.XPending(ctx, c.config.Stream, group.Name).Result()
+			if err != nil {
+				if ctx.Err() == nil {
+					c.logger.Warn("failed to read pending entries", "group", group.Name, "error", err)
+				}
+				return
+			}
+			needed = pending.Lower
+		}
+		if minID == "" || entryIDLess(needed, minID) {
+			minID = needed
+		}
+	}
+	if minID == "" || minID == "0-0" {
+		return
+	}
+	if err := c.client.XTrimMinIDApprox(ctx, c.config.Stream, minID, 0).Err(); err != nil && ctx.Err() == nil {
+		c.logger.Warn("failed to trim stream", "error", err)
+	}
+}
+
+// entryIDLess orders stream entry ids of the form <milliseconds>-<sequence>.
+func entryIDLess(a, b string) bool {
+	aMillis, aSeq := splitEntryID(a)
+	bMillis, bSeq := splitEntryID(b)
+	if aMillis != bMillis {
+		return aMillis < bMillis
+	}
+	return aSeq < bSeq
+}
+
+func splitEntryID(id string) (uint64, uint64) {
+	millis, seq, _ := strings.Cut(id, "-")
+	m, _ := strconv.ParseUint(millis, 10, 64)
+	s, _ := strconv.ParseUint(seq, 10, 64)
+	return m, s
+}
+
+// deliveries returns how many times each claimed entry has been delivered, this claim included.
+func (c *Consumer) deliveries(ctx context.Context, messages []redis.XMessage) (map[string]int64, error) {
+	pending, err := c.client.XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream:   c.config.Stream,
+		Group:    c.config.Group,
+		Start:    messages[0].ID,
+		End:      messages[len(messages)-1].ID,
+		Count:    int64(len(messages)),
+		Consumer: c.config.Name,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(pending))
+	for _, entry := range pending {
+		counts[entry.ID] = entry.RetryCount
+	}
+	return counts, nil
+}
+
+// handle processes one entry delivered for the given time. Handled entries are acknowledged;
+// entries that cannot be decoded, failed permanently or ran out of attempts are dead-lettered.
+func (c *Consumer) handle(ctx context.Context, message redis.XMessage, delivery int64) {
+	// Redis 6.2 claims entries deleted from the stream as ids without values; nothing is left to
+	// handle or dead-letter.
+	if message.Values == nil {
+		c.logger.Warn("claimed stream entry no longer exists", "entry_id", message.ID)
+		if err := c.client.XAck(context.WithoutCancel(ctx), c.config.Stream, c.config.Group, message.ID).Err(); err != nil {
+			c.logger.Warn("failed to acknowledge stream entry", "entry_id", message.ID, "error", err)
+		}
+		return
+	}
+	raw, _ := message.Values[eventField].(string)
+	var event domain.Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		c.logger.Warn("failed to decode stream entry", "entry_id", message.ID, "error", err)
+		c.deadLetter(ctx, message.ID, raw, err)
+		return
+	}
+
+	// Shutdown waits for in-flight entries instead of abandoning them half-written.
+	handlerCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), c.config.HandlerTimeout)
+	err := c.handler(handlerCtx, event)
+	cancel()
+	if err != nil {
+		if usecase.IsPermanent(err) {
+			c.logger.Error("stream entry failed permanently", "entry_id", message.ID, "event_id", event.ID, "error", err)
+			c.deadLetter(ctx, message.ID, raw, err)
+			return
+		}
+		if delivery >= int64(c.config.MaxAttempts) {
+			c.logger.Error("stream entry exhausted its attempts", "entry_id", message.ID, "event_id", event.ID, "attempts", delivery, "error", err)
+			c.deadLetter(ctx, message.ID, raw, err)
+			return
+		}
+		c.logger.Warn("stream entry failed, it will be retried", "entry_id", message.ID, "event_id", event.ID, "attempt", delivery, "error", err)
+		return
+	}
+
+	if err := c.client.XAck(context.WithoutCancel(ctx), c.config.Stream, c.config.Group, message.ID).Err(); err != nil {
+		c.logger.Warn("failed to acknowledge stream entry", "entry_id", message.ID, "error", err)
+	}
+}
+
+// deadLetter copies the entry, its last error and the class of that error to the dead-letter stream
+// and acknowledges it.
+func (c *Consumer) deadLetter(ctx context.Context, entryID, raw string, cause error) {
+	ctx = context.WithoutCancel(ctx)
+	pipe := c.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: c.DeadLetterStream(),
+		Values: []any{eventField, raw, entryIDField, entryID, errorField, cause.Error(), errorClassField, usecase.ClassifyError(cause)},
+	})
+	pipe.XAck(ctx, c.config.Stream, c.config.Group, entryID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		c.logger.Error("failed to dead-letter stream entry", "entry_id", entryID, "error", err)
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
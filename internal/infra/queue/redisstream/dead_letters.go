@@ -0,0 +1,134 @@
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// Fields of dead-letter stream entries besides eventField.
+const (
+	entryIDField    = "entry_id"
+	errorField      = "error"
+	errorClassField = "error_class"
+)
+
+// deadLetterPageSize is the number of dead-letter entries read from Redis per request.
+const deadLetterPageSize = 100
+
+// DeadLetterQueue exposes the entries a Consumer moved to the dead-letter stream of a stream.
+// Redriven and edited entries are appended to the stream again.
+type DeadLetterQueue struct {
+	client *redis.Client
+	stream string
+}
+
+// NewDeadLetterQueue constructs a DeadLetterQueue over the dead-letter stream of stream.
+func NewDeadLetterQueue(client *redis.Client, stream string) *DeadLetterQueue {
+	return &DeadLetterQueue{client: client, stream: stream}
+}
+
+// Name returns the name of the dead-letter stream.
+func (q *DeadLetterQueue) Name() string {
+	return deadLetterStream(q.stream)
+}
+
+// EachDeadLetter walks the dead-lettered entries, most recently failed first.
+func (q *DeadLetterQueue) EachDeadLetter(ctx context.Context, fn func(domain.DeadLetter) error) error {
+	end := "+"
+	for {
+		messages, err := q.client.XRevRangeN(ctx, q.Name(), end, "-", deadLetterPageSize).Result()
+		if err != nil {
+			return errors.Wrap(err, "list dead-letter entries")
+		}
+		for _, message := range messages {
+			if err := fn(decodeDeadLetter(message)); err != nil {
+				return err
+			}
+		}
+		if len(messages) < deadLetterPageSize {
+			return nil
+		}
+		end = "(" + messages[len(messages)-1].ID
+	}
+}
+
+// decodeDeadLetter converts a dead-letter entry. An undecodable event is still listed, with the
+// decoding error, so it can be deleted.
+func decodeDeadLetter(message redis.XMessage) domain.DeadLetter {
+	raw, _ := message.Values[eventField].(string)
+	lastError, _ := message.Values[errorField].(string)
+	class, _ := message.Values[errorClassField].(string)
+	if class == "" {
+		class = domain.ErrorClassOther
+	}
+	var event domain.Event
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		lastError, class = errors.Wrap(err, "unmarshal event").Error(), domain.ErrorClassDecode
+	}
+	millis, _ := splitEntryID(message.ID)
+	return domain.DeadLetter{
+		ID:           message.ID,
+		Event:        event,
+		LastError:    lastError,
+		ErrorClass:   class,
+		LastFailedAt: time.UnixMilli(int64(millis)).UTC(),
+	}
+}
+
+// RedriveDeadLetter appends the entry's event to the stream again and removes the entry.
+func (q *DeadLetterQueue) RedriveDeadLetter(ctx context.Context, id string) error {
+	messages, err := q.client.XRange(ctx, q.Name(), id, id).Result()
+	if err != nil {
+		return errors.Wrap(err, "read dead-letter entry")
+	}
+	if len(messages) == 0 {
+		return errors.Wrapf(usecase.ErrNotFound, "dead-letter entry %s", id)
+	}
+	raw, _ := messages[0].Values[eventField].(string)
+	return q.requeue(ctx, id, raw)
+}
+
+// ReplaceDeadLetter appends event to the stream in place of the entry's and removes the entry.
+func (q *DeadLetterQueue) ReplaceDeadLetter(ctx context.Context, id string, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal event payload")
+	}
+	return q.requeue(ctx, id, string(payload))
+}
+
+// requeue appends payload to the stream and deletes the dead-letter entry in one transaction.
+func (q *DeadLetterQueue) requeue(ctx context.Context, id, payload string) error {
+	pipe := q.client.TxPipeline()
+	pipe.XAdd(ctx, &redis.XAddArgs{Stream: q.stream, Values: []any{eventField, payload}})
+	deleted := pipe.XDel(ctx, q.Name(), id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return errors.Wrap(err, "requeue dead-letter entry")
+	}
+	if deleted.Val() == 0 {
+		return errors.Wrapf(usecase.ErrNotFound, "dead-letter entry %s", id)
+	}
+	return nil
+}
+
+// DeleteDeadLetter discards the entry.
+func (q *DeadLetterQueue) DeleteDeadLetter(ctx context.Context, id string) error {
+	deleted, err := q.client.XDel(ctx, q.Name(), id).Result()
+	if err != nil {
+		return errors.Wrap(err, "delete dead-letter entry")
+	}
+	if deleted == 0 {
+		return errors.Wrapf(usecase.ErrNotFound, "dead-letter entry %s", id)
+	}
+	return nil
+}
+
+// Ensure DeadLetterQueue satisfies the DeadLetterQueue dependency.
+var _ usecase.DeadLetterQueue = (*DeadLetterQueue)(nil)
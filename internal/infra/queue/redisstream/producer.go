@@ -0,0 +1,53 @@
+package redisstream
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// eventField is the stream entry field holding the JSON encoded event.
+const eventField = "event"
+
+// Producer appends events to a Redis stream, a lighter transport than one Asynq task per event.
+// The stream is never trimmed by length, which would drop entries not yet handled; consumers trim
+// the entries every group has acknowledged instead.
+type Producer struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewProducer constructs a Producer. Once the stream holds maxLen entries, Enqueue fails with
+// usecase.ErrQueueFull until consumers catch up; zero disables the limit.
+func NewProducer(client *redis.Client, stream string, maxLen int64) *Producer {
+	return &Producer{client: client, stream: stream, maxLen: maxLen}
+}
+
+// Enqueue appends the event to the stream. The length check and the append are separate
+// commands, so concurrent producers may overshoot maxLen slightly.
+func (p *Producer) Enqueue(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal event payload")
+	}
+	if p.maxLen > 0 {
+		length, err := p.client.XLen(ctx, p.stream).Result()
+		if err != nil {
+			return errors.Wrap(err, "read stream length")
+		}
+		if length >= p.maxLen {
+			return errors.Wrapf(usecase.ErrQueueFull, "stream %s holds %d entries", p.stream, length)
+		}
+	}
+	args := &redis.XAddArgs{Stream: p.stream, Values: []any{eventField, payload}}
+	return errors.Wrap(p.client.XAdd(ctx, args).Err(), "append event to stream")
+}
+
+// Ensure Producer satisfies the EventQueue dependency.
+var _ usecase.EventQueue = (*Producer)(nil)
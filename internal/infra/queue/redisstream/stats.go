@@ -0,0 +1,44 @@
+package redisstream
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// StreamStats describes the backlog of a stream as seen by one consumer group.
+type StreamStats struct {
+	Stream      string `json:"stream"`
+	Group       string `json:"group"`
+	Length      int64  `json:"length"`
+	Pending     int64  `json:"pending"`
+	Lag         int64  `json:"lag"`
+	DeadLetters int64  `json:"dead_letters"`
+}
+
+// Stats reads the backlog of stream for group. Pending counts entries delivered but not yet
+// acknowledged; Lag counts entries not yet delivered, and is only reported by Redis 7 or newer.
+func Stats(ctx context.Context, client *redis.Client, stream, group string) (StreamStats, error) {
+	stats := StreamStats{Stream: stream, Group: group}
+	var err error
+	if stats.Length, err = client.XLen(ctx, stream).Result(); err != nil {
+		return stats, errors.Wrap(err, "read stream length")
+	}
+	if stats.DeadLetters, err = client.XLen(ctx, deadLetterStream(stream)).Result(); err != nil {
+		return stats, errors.Wrap(err, "read dead-letter stream length")
+	}
+
+	groups, err := client.XInfoGroups(ctx, stream).Result()
+	// A stream nothing was ever written to has no groups yet.
+	if err != nil && !strings.HasPrefix(err.Error(), "ERR no such key") {
+		return stats, errors.Wrap(err, "read consumer groups")
+	}
+	for _, info := range groups {
+		if info.Name == group {
+			stats.Pending, stats.Lag = info.Pending, info.Lag
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,277 @@
+package redaction
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"regexp"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// ActionDrop removes the matching field from the metadata.
+	ActionDrop = "drop"
+	// ActionHash replaces the matching value with a keyed SHA-256 digest.
+	ActionHash = "hash"
+	// ActionMask replaces the matching value with asterisks, keeping the last four characters.
+	ActionMask = "mask"
+)
+
+const maskPlaceholder = "****"
+
+// RuleConfig is the declarative form of a redaction rule. At least one of Key or Pattern must be
+// set; when both are set a value must satisfy both to be redacted.
+type RuleConfig struct {
+	Name    string `json:"name"`
+	Key     string `json:"key"`
+	Pattern string `json:"pattern"`
+	Action  string `json:"action"`
+}
+
+type rule struct {
+	name    string
+	key     path
+	pattern *regexp.Regexp
+	action  string
+	hits    atomic.Uint64
+}
+
+// Engine applies redaction rules to event metadata and keeps per-rule audit counters.
+type Engine struct {
+	rules []*rule
+	salt  []byte
+}
+
+// NewEngine compiles rule configurations into an Engine. salt keys the digests produced by
+// ActionHash and is required by hash rules: unsalted digests of short values such as emails or
+// phone numbers are reversed by brute force.
+func NewEngine(configs []RuleConfig, salt string) (*Engine, error) {
+	engine := &Engine{salt: []byte(salt)}
+	seen := make(map[string]struct{}, len(configs))
+	for i, cfg := range configs {
+		if cfg.Name == "" {
+			return nil, errors.Errorf("rule %d: name is required", i)
+		}
+		if _, ok := seen[cfg.Name]; ok {
+			return nil, errors.Errorf("rule %q: duplicate name", cfg.Name)
+		}
+		seen[cfg.Name] = struct{}{}
+
+		if cfg.Key == "" && cfg.Pattern == "" {
+			return nil, errors.Errorf("rule %q: key or pattern is required", cfg.Name)
+		}
+		switch cfg.Action {
+		case ActionDrop, ActionHash, ActionMask:
+		default:
+			return nil, errors.Errorf("rule %q: unknown action %q", cfg.Name, cfg.Action)
+		}
+		if cfg.Action == ActionHash && salt == "" {
+			return nil, errors.Errorf("rule %q: action %q requires a hash salt", cfg.Name, cfg.Action)
+		}
+
+		r := &rule{name: cfg.Name, action: cfg.Action}
+		if cfg.Key != "" {
+			p, err := parsePath(cfg.Key)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %q", cfg.Name)
+			}
+			r.key = p
+		}
+		if cfg.Pattern != "" {
+			re, err := regexp.Compile(cfg.Pattern)
+			if err != nil {
+				return nil, errors.Wrapf(err, "rule %q: compile pattern", cfg.Name)
+			}
+			r.pattern = re
+		}
+		engine.rules = append(engine.rules, r)
+	}
+	return engine, nil
+}
+
+// LoadRules reads a JSON array of RuleConfig values from path.
+func LoadRules(path string) ([]RuleConfig, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read redaction rules")
+	}
+	var configs []RuleConfig
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		return nil, errors.Wrap(err, "decode redaction rules")
+	}
+	return configs, nil
+}
+
+// Filter redacts event.Metadata in place.
+func (e *Engine) Filter(_ context.Context, event *domain.Event) error {
+	redacted, err := e.Redact(event.Metadata)
+	if err != nil {
+		return err
+	}
+	event.Metadata = redacted
+	return nil
+}
+
+// Redact applies every rule to the metadata document and returns the rewritten JSON. The input
+// is returned unchanged when no rule matched.
+func (e *Engine) Redact(metadata json.RawMessage) (json.RawMessage, error) {
+	if len(e.rules) == 0 || len(metadata) == 0 {
+		return metadata, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(metadata))
+	decoder.UseNumber()
+	var doc any
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, errors.Wrap(err, "decode metadata")
+	}
+
+	doc, changed := e.walk(doc, nil)
+	if !changed {
+		return metadata, nil
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "encode metadata")
+	}
+	return out, nil
+}
+
+// Counters returns the number of values each rule has redacted since startup, keyed by rule name.
+func (e *Engine) Counters() map[string]uint64 {
+	out := make(map[string]uint64, len(e.rules))
+	for _, r := range e.rules {
+		out[r.name] = r.hits.Load()
+	}
+	return out
+}
+
+// walk rewrites value, located at loc, and its descendants. Object members and array elements
+// whose rule action is drop are removed from their parent.
+func (e *Engine) walk(value any, loc []step) (any, bool) {
+	if len(loc) > 0 {
+		for _, r := range e.rules {
+			if r.action != ActionDrop && r.pattern == nil && r.selects(loc) {
+				return e.apply(r, value)
+			}
+		}
+	}
+
+	changed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			childLoc := append(loc[:len(loc):len(loc)], step{key: key})
+			if e.dropped(child, childLoc) {
+				delete(v, key)
+				changed = true
+				continue
+			}
+			next, childChanged := e.walk(child, childLoc)
+			if childChanged {
+				v[key] = next
+				changed = true
+			}
+		}
+		return v, changed
+	case []any:
+		kept := v[:0]
+		for i, child := range v {
+			childLoc := append(loc[:len(loc):len(loc)], step{index: i, isIndex: true})
+			if e.dropped(child, childLoc) {
+				changed = true
+				continue
+			}
+			next, childChanged := e.walk(child, childLoc)
+			changed = changed || childChanged
+			kept = append(kept, next)
+		}
+		return kept, changed
+	}
+
+	for _, r := range e.rules {
+		if r.action == ActionDrop || r.pattern == nil || !r.selects(loc) {
+			continue
+		}
+		if next, ok := e.apply(r, value); ok {
+			value = next
+			changed = true
+		}
+	}
+	return value, changed
+}
+
+// dropped reports whether a drop rule selects value at loc, counting the hit.
+func (e *Engine) dropped(value any, loc []step) bool {
+	for _, r := range e.rules {
+		if r.action != ActionDrop || !r.selects(loc) {
+			continue
+		}
+		if r.pattern != nil {
+			s, ok := value.(string)
+			if !ok || !r.pattern.MatchString(s) {
+				continue
+			}
+		}
+		r.hits.Add(1)
+		return true
+	}
+	return false
+}
+
+func (r *rule) selects(loc []step) bool {
+	return r.key == nil || r.key.matches(loc)
+}
+
+// apply runs a hash or mask rule against value. Pattern rules rewrite only the matched
+// substrings; key-only rules rewrite the whole value.
+func (e *Engine) apply(r *rule, value any) (any, bool) {
+	if r.pattern != nil {
+		s, ok := value.(string)
+		if !ok || !r.pattern.MatchString(s) {
+			return value, false
+		}
+		r.hits.Add(1)
+		return r.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			return e.transform(r.action, match)
+		}), true
+	}
+
+	if value == nil {
+		return value, false
+	}
+	s, ok := value.(string)
+	if !ok {
+		encoded, _ := json.Marshal(value)
+		s = string(encoded)
+	}
+	r.hits.Add(1)
+	return e.transform(r.action, s), true
+}
+
+func (e *Engine) transform(action, s string) string {
+	if action == ActionHash {
+		mac := hmac.New(sha256.New, e.salt)
+		mac.Write([]byte(s))
+		return "sha256:" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	runes := []rune(s)
+	if len(runes) < 8 {
+		return maskPlaceholder
+	}
+	return maskPlaceholder + string(runes[len(runes)-4:])
+}
+
+// Ensure Engine satisfies the EventFilter dependency.
+var _ usecase.EventFilter = (*Engine)(nil)
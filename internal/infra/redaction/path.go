@@ -0,0 +1,134 @@
+package redaction
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type segmentKind int
+
+const (
+	segmentChild segmentKind = iota
+	segmentDescend
+	segmentWildcard
+	segmentIndex
+)
+
+type segment struct {
+	kind  segmentKind
+	name  string
+	index int
+}
+
+// path is a compiled JSONPath-style key expression. Supported syntax is a subset of JSONPath:
+// "$.a.b", "$..email" (recursive descent), "$.items[*].phone", "$.items[0]" and "$['a b']".
+// Key comparisons are case-insensitive.
+type path []segment
+
+func parsePath(expr string) (path, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, errors.Errorf("path %q must start with $", expr)
+	}
+
+	var out path
+	rest := expr[1:]
+	for rest != "" {
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			name, remaining := readName(rest[2:])
+			if name == "" {
+				return nil, errors.Errorf("path %q: missing key after ..", expr)
+			}
+			if name == "*" {
+				out = append(out, segment{kind: segmentDescend})
+			} else {
+				out = append(out, segment{kind: segmentDescend, name: strings.ToLower(name)})
+			}
+			rest = remaining
+		case strings.HasPrefix(rest, "."):
+			name, remaining := readName(rest[1:])
+			if name == "" {
+				return nil, errors.Errorf("path %q: missing key after .", expr)
+			}
+			out = append(out, childSegment(name))
+			rest = remaining
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, errors.Errorf("path %q: unterminated [", expr)
+			}
+			inner := strings.TrimSpace(rest[1:end])
+			rest = rest[end+1:]
+			switch {
+			case inner == "*":
+				out = append(out, segment{kind: segmentWildcard})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				out = append(out, segment{kind: segmentChild, name: strings.ToLower(inner[1 : len(inner)-1])})
+			default:
+				idx, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, errors.Errorf("path %q: invalid index %q", expr, inner)
+				}
+				out = append(out, segment{kind: segmentIndex, index: idx})
+			}
+		default:
+			return nil, errors.Errorf("path %q: unexpected %q", expr, rest)
+		}
+	}
+	return out, nil
+}
+
+func childSegment(name string) segment {
+	if name == "*" {
+		return segment{kind: segmentWildcard}
+	}
+	return segment{kind: segmentChild, name: strings.ToLower(name)}
+}
+
+func readName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// step is one element of a concrete location inside a JSON document: either an object key
+// or an array index.
+type step struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// matches reports whether the concrete location loc is selected by the path.
+func (p path) matches(loc []step) bool {
+	if len(p) == 0 {
+		return len(loc) == 0
+	}
+	if len(loc) == 0 {
+		return false
+	}
+
+	seg := p[0]
+	switch seg.kind {
+	case segmentChild:
+		return !loc[0].isIndex && strings.EqualFold(loc[0].key, seg.name) && p[1:].matches(loc[1:])
+	case segmentWildcard:
+		return p[1:].matches(loc[1:])
+	case segmentIndex:
+		return loc[0].isIndex && loc[0].index == seg.index && p[1:].matches(loc[1:])
+	case segmentDescend:
+		for i := range loc {
+			if seg.name == "" || (!loc[i].isIndex && strings.EqualFold(loc[i].key, seg.name)) {
+				if p[1:].matches(loc[i+1:]) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// countedKeyPrefix is shared by every counted-event mark.
+const countedKeyPrefix = "counted:"
+
+// CountedEvents keeps one expiring key per counter and counted event.
+type CountedEvents struct {
+	client    *redis.Client
+	retention time.Duration
+}
+
+// NewCountedEvents constructs a CountedEvents whose marks expire after retention. An event
+// delivered again after that is counted again.
+func NewCountedEvents(client *redis.Client, retention time.Duration) *CountedEvents {
+	return &CountedEvents{client: client, retention: retention}
+}
+
+// MarkCounted sets the mark unless it exists, reporting whether it was set.
+func (c *CountedEvents) MarkCounted(ctx context.Context, counter string, eventID uuid.UUID) (bool, error) {
+	set, err := c.client.SetNX(ctx, countedKey(counter, eventID), 1, c.retention).Result()
+	if err != nil {
+		return false, errors.Wrap(err, "set counted mark")
+	}
+	return set, nil
+}
+
+// UnmarkCounted deletes the mark.
+func (c *CountedEvents) UnmarkCounted(ctx context.Context, counter string, eventID uuid.UUID) error {
+	return errors.Wrap(c.client.Del(ctx, countedKey(counter, eventID)).Err(), "delete counted mark")
+}
+
+func countedKey(counter string, eventID uuid.UUID) string {
+	return countedKeyPrefix + counter + ":" + eventID.String()
+}
+
+// Ensure CountedEvents satisfies the CountedEvents dependency.
+var _ usecase.CountedEvents = (*CountedEvents)(nil)
@@ -0,0 +1,97 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// publishTimeout bounds how long ingestion waits on the live tail.
+	publishTimeout = 200 * time.Millisecond
+	// subscriberBuffer is the number of events buffered per subscriber before events are skipped.
+	subscriberBuffer = 256
+)
+
+// EventStream publishes ingested events on a Redis pub/sub channel and subscribes to it, so every
+// tracking-service replica can serve the live tail of events accepted by any other.
+type EventStream struct {
+	client  *redis.Client
+	channel string
+	logger  *slog.Logger
+}
+
+// NewEventStream constructs an EventStream on channel.
+func NewEventStream(client *redis.Client, channel string, logger *slog.Logger) *EventStream {
+	return &EventStream{client: client, channel: channel, logger: logger.With("component", "event_stream")}
+}
+
+// Publish broadcasts event. Failures are logged and otherwise ignored.
+func (s *EventStream) Publish(ctx context.Context, event domain.Event) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		s.logger.Warn("failed to encode published event", "event_id", event.ID, "error", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	if err := s.client.Publish(ctx, s.channel, payload).Err(); err != nil {
+		s.logger.Warn("failed to publish event", "event_id", event.ID, "error", err)
+	}
+}
+
+// Subscribe opens a dedicated subscription that lasts until ctx is cancelled. A subscriber that
+// falls behind by more than subscriberBuffer events skips events rather than stalling Redis.
+func (s *EventStream) Subscribe(ctx context.Context) (<-chan domain.Event, error) {
+	pubsub := s.client.Subscribe(ctx, s.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, errors.Wrap(err, "subscribe to event channel")
+	}
+
+	out := make(chan domain.Event, subscriberBuffer)
+	go func() {
+		defer close(out)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		skipped := 0
+		for {
+			select {
+			case <-ctx.Done():
+				if skipped > 0 {
+					s.logger.Info("slow subscriber skipped events", "skipped", skipped)
+				}
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event domain.Event
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					s.logger.Warn("failed to decode published event", "error", err)
+					continue
+				}
+				select {
+				case out <- event:
+				default:
+					skipped++
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+// Ensure EventStream satisfies the publisher and subscriber dependencies.
+var (
+	_ usecase.EventPublisher  = (*EventStream)(nil)
+	_ usecase.EventSubscriber = (*EventStream)(nil)
+)
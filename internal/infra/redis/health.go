@@ -0,0 +1,33 @@
+package redis
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// HealthCheck probes Redis for readiness.
+type HealthCheck struct {
+	client *redis.Client
+}
+
+// NewHealthCheck constructs a HealthCheck for client.
+func NewHealthCheck(client *redis.Client) *HealthCheck {
+	return &HealthCheck{client: client}
+}
+
+// Name identifies the dependency in readiness reports.
+func (h *HealthCheck) Name() string {
+	return "redis"
+}
+
+// Ping checks that Redis answers.
+func (h *HealthCheck) Ping(ctx context.Context) error {
+	return errors.Wrap(h.client.Ping(ctx).Err(), "ping redis")
+}
+
+// Ensure HealthCheck satisfies the DependencyCheck dependency.
+var _ usecase.DependencyCheck = (*HealthCheck)(nil)
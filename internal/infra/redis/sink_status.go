@@ -0,0 +1,87 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// sinkStatusPrefix starts the keys of the hashes holding the latest reports of one worker,
+	// keyed by sink.
+	sinkStatusPrefix = "tracking:sinks:health:"
+	// legacySinkStatusKey is the single hash earlier releases kept every report in.
+	legacySinkStatusKey = "tracking:sinks:health"
+)
+
+// SinkStatusStore keeps sink health reports in one Redis hash per worker. Each hash expires unless
+// its worker reports again, so workers that stopped leave nothing behind.
+type SinkStatusStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewSinkStatusStore constructs a SinkStatusStore. Reports expire ttl after they are saved.
+func NewSinkStatusStore(client *redis.Client, ttl time.Duration) *SinkStatusStore {
+	return &SinkStatusStore{client: client, ttl: ttl}
+}
+
+// SaveSinkStatuses overwrites the reports of the given worker and sink pairs.
+func (s *SinkStatusStore) SaveSinkStatuses(ctx context.Context, statuses []domain.SinkStatus) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	fields := make(map[string]map[string]any)
+	for _, status := range statuses {
+		raw, err := json.Marshal(status)
+		if err != nil {
+			return errors.Wrap(err, "marshal sink status")
+		}
+		key := sinkStatusPrefix + status.Worker
+		if fields[key] == nil {
+			fields[key] = make(map[string]any)
+		}
+		fields[key][status.Sink] = raw
+	}
+
+	pipe := s.client.TxPipeline()
+	for key, values := range fields {
+		pipe.HSet(ctx, key, values)
+		pipe.Expire(ctx, key, s.ttl)
+	}
+	pipe.Del(ctx, legacySinkStatusKey)
+	_, err := pipe.Exec(ctx)
+	return errors.Wrap(err, "save sink statuses")
+}
+
+// SinkStatuses returns every stored report that has not expired yet.
+func (s *SinkStatusStore) SinkStatuses(ctx context.Context) ([]domain.SinkStatus, error) {
+	var statuses []domain.SinkStatus
+	iter := s.client.Scan(ctx, 0, sinkStatusPrefix+"*", 100).Iterator()
+	for iter.Next(ctx) {
+		values, err := s.client.HGetAll(ctx, iter.Val()).Result()
+		if err != nil {
+			return nil, errors.Wrap(err, "load sink statuses")
+		}
+		for _, raw := range values {
+			var status domain.SinkStatus
+			if err := json.Unmarshal([]byte(raw), &status); err != nil {
+				return nil, errors.Wrap(err, "decode sink status")
+			}
+			statuses = append(statuses, status)
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, errors.Wrap(err, "list sink statuses")
+	}
+	return statuses, nil
+}
+
+// Ensure SinkStatusStore satisfies the SinkStatusStore dependency.
+var _ usecase.SinkStatusStore = (*SinkStatusStore)(nil)
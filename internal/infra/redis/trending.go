@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// trendingKeyPrefix is shared by every trending key. The hash tag keeps them in one cluster slot
+// so ZUNIONSTORE works on Redis Cluster.
+const trendingKeyPrefix = "trending:{quotes}:"
+
+// trendingCacheTTL is how long a computed leaderboard is reused before it is recomputed.
+const trendingCacheTTL = 5 * time.Second
+
+// TrendingStore keeps quote activity in one sorted set per time bucket. Queries merge the buckets
+// of a window with ZUNIONSTORE, weighting each bucket by exponential decay of its age so that
+// recent activity dominates.
+type TrendingStore struct {
+	client    *redis.Client
+	bucket    time.Duration
+	halfLife  time.Duration
+	maxWindow time.Duration
+}
+
+// NewTrendingStore constructs a TrendingStore. Buckets are kept for maxWindow plus one bucket.
+func NewTrendingStore(client *redis.Client, bucket, halfLife, maxWindow time.Duration) (*TrendingStore, error) {
+	if bucket <= 0 || halfLife <= 0 || maxWindow < bucket {
+		return nil, errors.New("trending bucket and half-life must be positive and the max window at least one bucket")
+	}
+	return &TrendingStore{client: client, bucket: bucket, halfLife: halfLife, maxWindow: maxWindow}, nil
+}
+
+// RecordQuoteActivity adds weight to quoteID in the bucket containing at.
+func (s *TrendingStore) RecordQuoteActivity(ctx context.Context, quoteID string, weight float64, at time.Time) error {
+	key := s.bucketKey(at.Truncate(s.bucket))
+	pipe := s.client.TxPipeline()
+	pipe.ZIncrBy(ctx, key, weight, quoteID)
+	pipe.Expire(ctx, key, s.maxWindow+s.bucket)
+	_, err := pipe.Exec(ctx)
+	return errors.Wrap(err, "increment quote score")
+}
+
+// TopQuotes merges the buckets covering window into a short-lived cached leaderboard and returns
+// its top limit entries.
+func (s *TrendingStore) TopQuotes(ctx context.Context, window time.Duration, limit int) ([]domain.TrendingQuote, error) {
+	dest := trendingKeyPrefix + "top:" + window.String()
+
+	exists, err := s.client.Exists(ctx, dest).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "check trending cache")
+	}
+	if exists == 0 {
+		if err := s.merge(ctx, dest, window); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := s.client.ZRevRangeWithScores(ctx, dest, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, errors.Wrap(err, "read trending quotes")
+	}
+	quotes := make([]domain.TrendingQuote, 0, len(entries))
+	for _, entry := range entries {
+		member, _ := entry.Member.(string)
+		quotes = append(quotes, domain.TrendingQuote{QuoteID: member, Score: entry.Score})
+	}
+	return quotes, nil
+}
+
+func (s *TrendingStore) merge(ctx context.Context, dest string, window time.Duration) error {
+	now := time.Now()
+	current := now.Truncate(s.bucket)
+	buckets := int(math.Ceil(float64(window) / float64(s.bucket)))
+
+	store := &redis.ZStore{Aggregate: "SUM"}
+	for i := 0; i < buckets; i++ {
+		start := current.Add(-time.Duration(i) * s.bucket)
+		age := now.Sub(start.Add(s.bucket))
+		if age < 0 {
+			age = 0
+		}
+		store.Keys = append(store.Keys, s.bucketKey(start))
+		store.Weights = append(store.Weights, math.Pow(0.5, float64(age)/float64(s.halfLife)))
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.ZUnionStore(ctx, dest, store)
+	pipe.Expire(ctx, dest, trendingCacheTTL)
+	_, err := pipe.Exec(ctx)
+	return errors.Wrap(err, "merge trending buckets")
+}
+
+func (s *TrendingStore) bucketKey(start time.Time) string {
+	return trendingKeyPrefix + strconv.FormatInt(start.Unix(), 10)
+}
+
+// Ensure TrendingStore satisfies the TrendingStore dependency.
+var _ usecase.TrendingStore = (*TrendingStore)(nil)
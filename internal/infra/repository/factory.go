@@ -0,0 +1,104 @@
+// Package repository selects the event storage backend configured for the deployment.
+package repository
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+	"quotesnap/internal/infra/config"
+	inframongorepo "quotesnap/internal/infra/repository/mongo"
+	"quotesnap/internal/infra/repository/postgres"
+	"quotesnap/internal/infra/repository/sqlite"
+)
+
+// EventStore is implemented by every raw event storage backend.
+type EventStore interface {
+	usecase.EventRepository
+	usecase.UserEventStore
+	usecase.EventPurger
+	usecase.ArchivableEventStore
+	usecase.EventSource
+	// RequiresPurge reports whether PurgeExpired must be scheduled to enforce retention.
+	RequiresPurge() bool
+}
+
+// RetentionApplier is implemented by stores that enforce retention with server-side expiry
+// settings. Only the worker and quotesnapctl indexes create apply them, so other processes never
+// rewrite settings that follow another deployment's configuration.
+type RetentionApplier interface {
+	ApplyRetention(ctx context.Context) error
+}
+
+// ApplyRetention updates the server-side expiry of store from its retention policy, if it has any.
+func ApplyRetention(ctx context.Context, store EventStore) error {
+	applier, ok := store.(RetentionApplier)
+	if !ok {
+		return nil
+	}
+	return errors.Wrap(applier.ApplyRetention(ctx), "apply retention")
+}
+
+// HealthChecks returns the readiness checks of store. MongoDB is already probed through its client.
+func HealthChecks(store EventStore) []usecase.DependencyCheck {
+	if check, ok := store.(usecase.DependencyCheck); ok {
+		return []usecase.DependencyCheck{check}
+	}
+	return nil
+}
+
+// NewEventStore opens the event store selected by cfg.EventStore. MongoDB stores events in db;
+// other backends open their own connection, which the returned close function releases.
+func NewEventStore(ctx context.Context, cfg config.Config, db *mongo.Database, retention domain.RetentionPolicy, logger *slog.Logger) (EventStore, func(), error) {
+	switch cfg.EventStore {
+	case config.EventStoreMongo:
+		storage := inframongorepo.EventStorage{Mode: cfg.MongoEventStorage, Granularity: cfg.MongoTimeSeriesGranularity}
+		repo, err := inframongorepo.NewEventRepository(db, storage, retention)
+		return repo, func() {}, err
+	case config.EventStorePostgres:
+		pool, err := postgres.Connect(ctx, cfg.PostgresURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		repo, err := postgres.NewEventRepository(ctx, pool, retention)
+		if err != nil {
+			pool.Close()
+			return nil, nil, err
+		}
+		return repo, pool.Close, nil
+	case config.EventStoreSQLite:
+		return NewSQLiteEventStore(ctx, cfg, retention, logger)
+	}
+	return nil, nil, errors.Errorf("unknown event store %q", cfg.EventStore)
+}
+
+// NewSQLiteEventStore opens the SQLite event store at cfg.SQLitePath. The close function commits
+// pending writes before closing the database.
+func NewSQLiteEventStore(ctx context.Context, cfg config.Config, retention domain.RetentionPolicy, logger *slog.Logger) (EventStore, func(), error) {
+	db, err := sqlite.Open(ctx, cfg.SQLitePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	repo, err := sqlite.NewEventRepository(ctx, db, retention, cfg.SQLiteBatchSize, cfg.SQLiteFlushInterval, logger)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return repo, func() {
+		repo.Close()
+		db.Close()
+	}, nil
+}
+
+// Ensure every backend satisfies EventStore.
+var (
+	_ EventStore = (*inframongorepo.EventRepository)(nil)
+	_ EventStore = (*postgres.EventRepository)(nil)
+	_ EventStore = (*sqlite.EventRepository)(nil)
+
+	_ RetentionApplier = (*inframongorepo.EventRepository)(nil)
+)
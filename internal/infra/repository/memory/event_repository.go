@@ -0,0 +1,341 @@
+package memory
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// EventRepository keeps events in memory. When a snapshot path is configured the events are
+// loaded from it on start and written back by Snapshot, as gzipped NDJSON.
+type EventRepository struct {
+	retention    domain.RetentionPolicy
+	snapshotPath string
+	logger       *slog.Logger
+
+	mu     sync.RWMutex
+	events map[uuid.UUID]domain.Event
+	dirty  bool
+}
+
+// NewEventRepository constructs an EventRepository, restoring snapshotPath when it exists. An
+// empty snapshotPath keeps events only for the lifetime of the process.
+func NewEventRepository(snapshotPath string, retention domain.RetentionPolicy, logger *slog.Logger) (*EventRepository, error) {
+	r := &EventRepository{
+		retention:    retention,
+		snapshotPath: snapshotPath,
+		logger:       logger.With("component", "memory_event_repository"),
+		events:       make(map[uuid.UUID]domain.Event),
+	}
+	if snapshotPath == "" {
+		return r, nil
+	}
+	if err := r.restore(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Persist stores a new event. Like the MongoDB repository it returns usecase.ErrDuplicateEvent for
+// an id that is already stored.
+func (r *EventRepository) Persist(_ context.Context, event domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.events[event.ID]; ok {
+		return errors.Wrapf(usecase.ErrDuplicateEvent, "event %s", event.ID)
+	}
+	r.events[event.ID] = event
+	r.dirty = true
+	return nil
+}
+
+// Upsert replaces the stored event with the same id, inserting it when absent.
+func (r *EventRepository) Upsert(_ context.Context, event domain.Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[event.ID] = event
+	r.dirty = true
+	return nil
+}
+
+// EachEvent streams the events matching query in reception order.
+func (r *EventRepository) EachEvent(ctx context.Context, query usecase.EventQuery, fn func(domain.Event) error) error {
+	events := r.sorted(query.Matches, func(a, b domain.Event) bool { return a.ReceivedAt.Before(b.ReceivedAt) })
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EachUserEvent streams every event belonging to userID in occurrence order.
+func (r *EventRepository) EachUserEvent(ctx context.Context, userID string, fn func(domain.Event) error) error {
+	events := r.sorted(func(event domain.Event) bool { return event.UserID == userID },
+		func(a, b domain.Event) bool { return a.OccurredAt.Before(b.OccurredAt) })
+	for _, event := range events {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UserProfile summarises the events stored for userID.
+func (r *EventRepository) UserProfile(_ context.Context, userID string) (domain.UserProfile, error) {
+	profile := domain.UserProfile{UserID: userID, Sources: []string{}, EventNames: []string{}}
+	sources, names := make(map[string]bool), make(map[string]bool)
+
+	r.mu.RLock()
+	for _, event := range r.events {
+		if event.UserID != userID {
+			continue
+		}
+		profile.EventCount++
+		if profile.FirstSeen.IsZero() || event.OccurredAt.Before(profile.FirstSeen) {
+			profile.FirstSeen = event.OccurredAt
+		}
+		if event.OccurredAt.After(profile.LastSeen) {
+			profile.LastSeen = event.OccurredAt
+		}
+		sources[event.Source], names[event.Name] = true, true
+	}
+	r.mu.RUnlock()
+
+	for source := range sources {
+		profile.Sources = append(profile.Sources, source)
+	}
+	for name := range names {
+		profile.EventNames = append(profile.EventNames, name)
+	}
+	sort.Strings(profile.Sources)
+	sort.Strings(profile.EventNames)
+	return profile, nil
+}
+
+// EraseUser deletes or anonymizes every event belonging to userID, mirroring the MongoDB repository.
+func (r *EventRepository) EraseUser(_ context.Context, userID string, mode domain.ErasureMode) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pseudonym := "anon-" + uuid.NewString()
+	var affected int64
+	for id, event := range r.events {
+		if event.UserID != userID {
+			continue
+		}
+		affected++
+		if mode != domain.ErasureModeAnonymize {
+			delete(r.events, id)
+			continue
+		}
+		event.UserID = pseudonym
+		event.Metadata = json.RawMessage("{}")
+		event.UserAgent, event.IP = "", ""
+		if event.Geo != nil {
+			geo := *event.Geo
+			geo.City, geo.Region = "", ""
+			event.Geo = &geo
+		}
+		r.events[id] = event
+	}
+	if affected > 0 {
+		r.dirty = true
+	}
+	return affected, nil
+}
+
+// PurgeExpired deletes events older than the retention that applies to them.
+func (r *EventRepository) PurgeExpired(_ context.Context, now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, event := range r.events {
+		if ttl := r.ttl(event); ttl > 0 && event.ReceivedAt.Before(now.Add(-ttl)) {
+			delete(r.events, id)
+			deleted++
+		}
+	}
+	if deleted > 0 {
+		r.dirty = true
+	}
+	return deleted, nil
+}
+
+// ttl returns the retention of event. Name rules win over source rules, which win over the default.
+func (r *EventRepository) ttl(event domain.Event) time.Duration {
+	ttl, bySource := r.retention.Default, false
+	for _, rule := range r.retention.Overrides {
+		switch {
+		case rule.Field == domain.RetentionFieldName && rule.Value == event.Name:
+			return rule.TTL
+		case rule.Field == domain.RetentionFieldSource && rule.Value == event.Source && !bySource:
+			ttl, bySource = rule.TTL, true
+		}
+	}
+	return ttl
+}
+
+// Len returns the number of stored events.
+func (r *EventRepository) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.events)
+}
+
+// Snapshot writes every event to the snapshot path when anything changed since the last
+// snapshot. The file is replaced atomically so a crash never leaves a truncated snapshot.
+func (r *EventRepository) Snapshot() error {
+	if r.snapshotPath == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	if !r.dirty {
+		r.mu.Unlock()
+		return nil
+	}
+	events := make([]domain.Event, 0, len(r.events))
+	for _, event := range r.events {
+		events = append(events, event)
+	}
+	r.dirty = false
+	r.mu.Unlock()
+
+	if err := r.write(events); err != nil {
+		r.mu.Lock()
+		r.dirty = true
+		r.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// RunSnapshots snapshots every interval until ctx is cancelled, then takes a final snapshot.
+func (r *EventRepository) RunSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := r.Snapshot(); err != nil {
+				r.logger.Error("final event snapshot failed", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := r.Snapshot(); err != nil {
+				r.logger.Warn("event snapshot failed", "error", err)
+			}
+		}
+	}
+}
+
+func (r *EventRepository) write(events []domain.Event) error {
+	if err := os.MkdirAll(filepath.Dir(r.snapshotPath), 0o750); err != nil {
+		return errors.Wrap(err, "create snapshot directory")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(r.snapshotPath), filepath.Base(r.snapshotPath)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "create snapshot file")
+	}
+	defer os.Remove(tmp.Name())
+
+	buffered := bufio.NewWriter(tmp)
+	gz := gzip.NewWriter(buffered)
+	encoder := json.NewEncoder(gz)
+	for _, event := range events {
+		if err := encoder.Encode(event); err != nil {
+			tmp.Close()
+			return errors.Wrap(err, "encode snapshot event")
+		}
+	}
+	if err := gz.Close(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "compress snapshot")
+	}
+	if err := buffered.Flush(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "write snapshot")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "sync snapshot")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "close snapshot")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), r.snapshotPath), "replace snapshot")
+}
+
+func (r *EventRepository) restore() error {
+	file, err := os.Open(r.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "open snapshot")
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(bufio.NewReader(file))
+	if err != nil {
+		return errors.Wrap(err, "open snapshot stream")
+	}
+	decoder := json.NewDecoder(gz)
+	for {
+		var event domain.Event
+		err := decoder.Decode(&event)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return errors.Wrap(err, "decode snapshot event")
+		}
+		r.events[event.ID] = event
+	}
+	r.logger.Info("restored event snapshot", "events", len(r.events))
+	return nil
+}
+
+// sorted returns the events accepted by keep ordered by less.
+func (r *EventRepository) sorted(keep func(domain.Event) bool, less func(a, b domain.Event) bool) []domain.Event {
+	r.mu.RLock()
+	events := make([]domain.Event, 0, len(r.events))
+	for _, event := range r.events {
+		if keep(event) {
+			events = append(events, event)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(events, func(i, j int) bool { return less(events[i], events[j]) })
+	return events
+}
+
+// Ensure interface compliance at compile-time.
+var (
+	_ usecase.EventRepository = (*EventRepository)(nil)
+	_ usecase.UserEventStore  = (*EventRepository)(nil)
+	_ usecase.EventPurger     = (*EventRepository)(nil)
+	_ usecase.EventSource     = (*EventRepository)(nil)
+)
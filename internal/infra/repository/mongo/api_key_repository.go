@@ -0,0 +1,129 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// APIKeyRepository stores admin API keys.
+type APIKeyRepository struct {
+	collection *mongo.Collection
+}
+
+// NewAPIKeyRepository wires the api_keys collection into a repository implementation.
+func NewAPIKeyRepository(ctx context.Context, db *mongo.Database) (*APIKeyRepository, error) {
+	collection := db.Collection("api_keys")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "hash", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ensure api key index")
+	}
+	return &APIKeyRepository{collection: collection}, nil
+}
+
+type apiKeyRecord struct {
+	ID        string     `bson:"_id"`
+	Name      string     `bson:"name"`
+	Hint      string     `bson:"hint"`
+	Hash      string     `bson:"hash"`
+	Scopes    []string   `bson:"scopes"`
+	CreatedAt time.Time  `bson:"created_at"`
+	RevokedAt *time.Time `bson:"revoked_at,omitempty"`
+}
+
+func (r apiKeyRecord) domain() (domain.APIKey, error) {
+	id, err := uuid.Parse(r.ID)
+	if err != nil {
+		return domain.APIKey{}, errors.Wrap(err, "parse api key id")
+	}
+	key := domain.APIKey{ID: id, Name: r.Name, Hint: r.Hint, Hash: r.Hash, CreatedAt: r.CreatedAt.UTC()}
+	for _, scope := range r.Scopes {
+		key.Scopes = append(key.Scopes, domain.APIKeyScope(scope))
+	}
+	if r.RevokedAt != nil {
+		revokedAt := r.RevokedAt.UTC()
+		key.RevokedAt = &revokedAt
+	}
+	return key, nil
+}
+
+// CreateAPIKey inserts key.
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, key domain.APIKey) error {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	_, err := r.collection.InsertOne(ctx, apiKeyRecord{
+		ID:        key.ID.String(),
+		Name:      key.Name,
+		Hint:      key.Hint,
+		Hash:      key.Hash,
+		Scopes:    scopes,
+		CreatedAt: key.CreatedAt,
+		RevokedAt: key.RevokedAt,
+	})
+	return errors.Wrap(err, "insert api key")
+}
+
+// ListAPIKeys returns every key, oldest first.
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "find api keys")
+	}
+	var records []apiKeyRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode api keys")
+	}
+	keys := make([]domain.APIKey, 0, len(records))
+	for _, record := range records {
+		key, err := record.domain()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// FindAPIKeyByHash returns the key with the given hash.
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (domain.APIKey, error) {
+	var record apiKeyRecord
+	err := r.collection.FindOne(ctx, bson.M{"hash": hash}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.APIKey{}, usecase.ErrNotFound
+	}
+	if err != nil {
+		return domain.APIKey{}, errors.Wrap(err, "find api key")
+	}
+	return record.domain()
+}
+
+// RevokeAPIKey sets the revocation time of the key unless it is already revoked.
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID, at time.Time) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": id.String()},
+		[]bson.M{{"$set": bson.M{"revoked_at": bson.M{"$ifNull": bson.A{"$revoked_at", at}}}}},
+	)
+	if err != nil {
+		return errors.Wrap(err, "revoke api key")
+	}
+	if result.MatchedCount == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+// Ensure APIKeyRepository satisfies the APIKeyStore dependency.
+var _ usecase.APIKeyStore = (*APIKeyRepository)(nil)
@@ -0,0 +1,135 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const suppressionDayLayout = "2006-01-02"
+
+// ConsentRepository stores per-user consent state and daily suppression counters.
+type ConsentRepository struct {
+	consents     *mongo.Collection
+	suppressions *mongo.Collection
+}
+
+// NewConsentRepository wires the consent collections into a repository implementation.
+func NewConsentRepository(db *mongo.Database) *ConsentRepository {
+	return &ConsentRepository{
+		consents:     db.Collection("user_consents"),
+		suppressions: db.Collection("consent_suppressions"),
+	}
+}
+
+type consentRecord struct {
+	UserID     string          `bson:"_id"`
+	Categories map[string]bool `bson:"categories"`
+	UpdatedAt  time.Time       `bson:"updated_at"`
+}
+
+// GetConsent loads the consent stored for userID. The boolean is false when none exists.
+func (r *ConsentRepository) GetConsent(ctx context.Context, userID string) (domain.Consent, bool, error) {
+	var record consentRecord
+	err := r.consents.FindOne(ctx, bson.M{"_id": userID}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.Consent{UserID: userID}, false, nil
+	}
+	if err != nil {
+		return domain.Consent{}, false, errors.Wrap(err, "find consent")
+	}
+	return domain.Consent{UserID: record.UserID, Categories: record.Categories, UpdatedAt: record.UpdatedAt.UTC()}, true, nil
+}
+
+// SaveConsent upserts the consent state of a user.
+func (r *ConsentRepository) SaveConsent(ctx context.Context, consent domain.Consent) error {
+	record := consentRecord{UserID: consent.UserID, Categories: consent.Categories, UpdatedAt: consent.UpdatedAt}
+	_, err := r.consents.ReplaceOne(ctx, bson.M{"_id": consent.UserID}, record, options.Replace().SetUpsert(true))
+	return errors.Wrap(err, "upsert consent")
+}
+
+// RecordSuppression increments today's counter for category and action.
+func (r *ConsentRepository) RecordSuppression(ctx context.Context, category string, action domain.ConsentAction) error {
+	day := time.Now().UTC().Format(suppressionDayLayout)
+	_, err := r.suppressions.UpdateOne(ctx,
+		bson.M{"_id": day + ":" + category + ":" + string(action)},
+		bson.M{
+			"$inc":         bson.M{"count": 1},
+			"$setOnInsert": bson.M{"day": day, "category": category, "action": string(action)},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return errors.Wrap(err, "increment suppression counter")
+}
+
+// SuppressionReport returns daily counters between from and to, inclusive.
+func (r *ConsentRepository) SuppressionReport(ctx context.Context, from, to time.Time) ([]domain.SuppressionCount, error) {
+	filter := bson.M{"day": bson.M{
+		"$gte": from.UTC().Format(suppressionDayLayout),
+		"$lte": to.UTC().Format(suppressionDayLayout),
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "category", Value: 1}})
+	cursor, err := r.suppressions.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "find suppression counters")
+	}
+
+	var rows []struct {
+		Day      string `bson:"day"`
+		Category string `bson:"category"`
+		Action   string `bson:"action"`
+		Count    int64  `bson:"count"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, errors.Wrap(err, "decode suppression counters")
+	}
+
+	report := make([]domain.SuppressionCount, 0, len(rows))
+	for _, row := range rows {
+		report = append(report, domain.SuppressionCount{
+			Day:      row.Day,
+			Category: row.Category,
+			Action:   domain.ConsentAction(row.Action),
+			Count:    row.Count,
+		})
+	}
+	return report, nil
+}
+
+// Name identifies the consent section of user data exports.
+func (r *ConsentRepository) Name() string {
+	return "consent"
+}
+
+// ExportUser returns the stored consent of userID, or nil when none exists.
+func (r *ConsentRepository) ExportUser(ctx context.Context, userID string) (any, error) {
+	consent, found, err := r.GetConsent(ctx, userID)
+	if err != nil || !found {
+		return nil, err
+	}
+	return consent, nil
+}
+
+// EraseUser removes the stored consent of userID. Both erasure modes delete the document since
+// consent state has no value once the user is gone.
+func (r *ConsentRepository) EraseUser(ctx context.Context, userID string, _ domain.ErasureMode) (int64, error) {
+	res, err := r.consents.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return 0, errors.Wrap(err, "delete consent")
+	}
+	return res.DeletedCount, nil
+}
+
+// Ensure interface compliance at compile-time.
+var (
+	_ usecase.ConsentStore        = (*ConsentRepository)(nil)
+	_ usecase.SuppressionRecorder = (*ConsentRepository)(nil)
+	_ usecase.UserDataSection     = (*ConsentRepository)(nil)
+)
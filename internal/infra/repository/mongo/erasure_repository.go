@@ -0,0 +1,90 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// ErasureRepository stores erasure requests so their progress can be polled.
+type ErasureRepository struct {
+	collection *mongo.Collection
+}
+
+// NewErasureRepository wires the erasure_requests collection into a repository implementation.
+func NewErasureRepository(db *mongo.Database) *ErasureRepository {
+	return &ErasureRepository{collection: db.Collection("erasure_requests")}
+}
+
+type erasureRecord struct {
+	ID          string           `bson:"_id"`
+	UserID      string           `bson:"user_id"`
+	Mode        string           `bson:"mode"`
+	Status      string           `bson:"status"`
+	Affected    map[string]int64 `bson:"affected,omitempty"`
+	Error       string           `bson:"error,omitempty"`
+	RequestedAt time.Time        `bson:"requested_at"`
+	UpdatedAt   time.Time        `bson:"updated_at"`
+}
+
+// CreateErasure inserts a new erasure request.
+func (r *ErasureRepository) CreateErasure(ctx context.Context, request domain.ErasureRequest) error {
+	_, err := r.collection.InsertOne(ctx, newErasureRecord(request))
+	return errors.Wrap(err, "insert erasure request")
+}
+
+// GetErasure loads an erasure request by id.
+func (r *ErasureRepository) GetErasure(ctx context.Context, id uuid.UUID) (domain.ErasureRequest, error) {
+	var record erasureRecord
+	err := r.collection.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.ErasureRequest{}, usecase.ErrNotFound
+	}
+	if err != nil {
+		return domain.ErasureRequest{}, errors.Wrap(err, "find erasure request")
+	}
+	return record.toDomain(), nil
+}
+
+// UpdateErasure replaces the stored state of an erasure request.
+func (r *ErasureRepository) UpdateErasure(ctx context.Context, request domain.ErasureRequest) error {
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": request.ID.String()}, newErasureRecord(request))
+	return errors.Wrap(err, "replace erasure request")
+}
+
+// Ensure interface compliance at compile-time.
+var _ usecase.ErasureRepository = (*ErasureRepository)(nil)
+
+func newErasureRecord(request domain.ErasureRequest) erasureRecord {
+	return erasureRecord{
+		ID:          request.ID.String(),
+		UserID:      request.UserID,
+		Mode:        string(request.Mode),
+		Status:      string(request.Status),
+		Affected:    request.Affected,
+		Error:       request.Error,
+		RequestedAt: request.RequestedAt,
+		UpdatedAt:   request.UpdatedAt,
+	}
+}
+
+func (r erasureRecord) toDomain() domain.ErasureRequest {
+	id, _ := uuid.Parse(r.ID)
+	return domain.ErasureRequest{
+		ID:          id,
+		UserID:      r.UserID,
+		Mode:        domain.ErasureMode(r.Mode),
+		Status:      domain.ErasureStatus(r.Status),
+		Affected:    r.Affected,
+		Error:       r.Error,
+		RequestedAt: r.RequestedAt.UTC(),
+		UpdatedAt:   r.UpdatedAt.UTC(),
+	}
+}
@@ -0,0 +1,137 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CopyEventsFrom copies events stored by src into r in (received_at, _id) order, batchSize
+// documents at a time, and returns how many were copied. Events already present in r are skipped
+// by id, so the copy can be rerun at any time.
+//
+// A rerun starts overlap before the newest event already in r rather than right after it: events
+// reach the store in queue order, not reception order, so an event received before the newest
+// copied one may still have been written to src after the previous run. overlap must exceed the
+// longest time an event can spend queued and retried. A zero overlap rechecks every event.
+// progress, when non-nil, is called with the running total after every batch.
+func (r *EventRepository) CopyEventsFrom(ctx context.Context, src *EventRepository, batchSize int, overlap time.Duration, progress func(int64)) (int64, error) {
+	if src.collection.Name() == r.collection.Name() {
+		return 0, errors.New("source and destination collections are the same")
+	}
+
+	filter, err := r.resumeFilter(ctx, overlap)
+	if err != nil {
+		return 0, err
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "received_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetBatchSize(int32(batchSize))
+	cursor, err := src.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return 0, errors.Wrap(err, "find source events")
+	}
+	defer cursor.Close(ctx)
+
+	var copied int64
+	batch := make([]eventRecord, 0, batchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		missing, err := r.missing(ctx, batch)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		if len(missing) == 0 {
+			return nil
+		}
+		if _, err := r.collection.InsertMany(ctx, missing); err != nil {
+			return errors.Wrap(err, "insert copied events")
+		}
+		copied += int64(len(missing))
+		if progress != nil {
+			progress(copied)
+		}
+		return nil
+	}
+
+	for cursor.Next(ctx) {
+		var record eventRecord
+		if err := cursor.Decode(&record); err != nil {
+			return copied, errors.Wrap(err, "decode source event")
+		}
+		batch = append(batch, r.record(record.toDomain()))
+		if len(batch) == batchSize {
+			if err := flush(); err != nil {
+				return copied, err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return copied, errors.Wrap(err, "iterate source events")
+	}
+	return copied, flush()
+}
+
+// resumeFilter selects the source events received at most overlap before the newest event
+// already stored in r, or every event when r is empty or overlap is zero.
+func (r *EventRepository) resumeFilter(ctx context.Context, overlap time.Duration) (bson.M, error) {
+	if overlap <= 0 {
+		return bson.M{}, nil
+	}
+	opts := options.FindOne().SetSort(bson.D{{Key: "received_at", Value: -1}})
+	var last eventRecord
+	err := r.collection.FindOne(ctx, bson.M{}, opts).Decode(&last)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return bson.M{}, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "find last copied event")
+	}
+	return bson.M{"received_at": bson.M{"$gte": last.ReceivedAt.Add(-overlap)}}, nil
+}
+
+// missing returns the records of batch whose id is not stored in r yet. The lookup is bounded by
+// the occurrence times of the batch so that time-series collections only read matching buckets.
+func (r *EventRepository) missing(ctx context.Context, batch []eventRecord) ([]interface{}, error) {
+	ids := make([]string, 0, len(batch))
+	from, to := batch[0].OccurredAt, batch[0].OccurredAt
+	for _, record := range batch {
+		ids = append(ids, record.ID)
+		if record.OccurredAt.Before(from) {
+			from = record.OccurredAt
+		}
+		if record.OccurredAt.After(to) {
+			to = record.OccurredAt
+		}
+	}
+	filter := bson.M{"_id": bson.M{"$in": ids}, "occurred_at": bson.M{"$gte": from, "$lte": to}}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, errors.Wrap(err, "find copied events")
+	}
+	var stored []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, errors.Wrap(err, "decode copied events")
+	}
+	present := make(map[string]bool, len(stored))
+	for _, record := range stored {
+		present[record.ID] = true
+	}
+
+	missing := make([]interface{}, 0, len(batch))
+	for _, record := range batch {
+		if !present[record.ID] {
+			missing = append(missing, record)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,110 @@
+package mongo
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"quotesnap/internal/core/domain"
+)
+
+// eventRecord is the BSON shape of an event document. Metadata is stored as the raw JSON bytes
+// received at ingest. Meta is only set in time-series mode, where it is the series meta field.
+type eventRecord struct {
+	ID         string           `bson:"_id"`
+	Name       string           `bson:"name"`
+	UserID     string           `bson:"user_id"`
+	Source     string           `bson:"source"`
+	Metadata   []byte           `bson:"metadata"`
+	OccurredAt time.Time        `bson:"occurred_at"`
+	ReceivedAt time.Time        `bson:"received_at"`
+	UserAgent  string           `bson:"user_agent,omitempty"`
+	IP         string           `bson:"ip,omitempty"`
+	Device     *deviceRecord    `bson:"device,omitempty"`
+	Geo        *geoRecord       `bson:"geo,omitempty"`
+	Consent    []string         `bson:"consent,omitempty"`
+	Restored   bool             `bson:"restored,omitempty"`
+	Meta       *eventMetaRecord `bson:"meta,omitempty"`
+}
+
+type eventMetaRecord struct {
+	Name   string `bson:"name"`
+	Source string `bson:"source"`
+}
+
+type deviceRecord struct {
+	Browser        string `bson:"browser"`
+	BrowserVersion string `bson:"browser_version"`
+	OS             string `bson:"os"`
+	OSVersion      string `bson:"os_version"`
+	Type           string `bson:"type"`
+	Bot            bool   `bson:"bot"`
+}
+
+type geoRecord struct {
+	Country string `bson:"country"`
+	Region  string `bson:"region,omitempty"`
+	City    string `bson:"city,omitempty"`
+}
+
+func newEventRecord(event domain.Event) eventRecord {
+	record := eventRecord{
+		ID:         event.ID.String(),
+		Name:       event.Name,
+		UserID:     event.UserID,
+		Source:     event.Source,
+		Metadata:   event.Metadata,
+		OccurredAt: event.OccurredAt,
+		ReceivedAt: event.ReceivedAt,
+		UserAgent:  event.UserAgent,
+		IP:         event.IP,
+		Consent:    event.Consent,
+		Restored:   event.Restored,
+	}
+	if d := event.Device; d != nil {
+		record.Device = &deviceRecord{
+			Browser:        d.Browser,
+			BrowserVersion: d.BrowserVersion,
+			OS:             d.OS,
+			OSVersion:      d.OSVersion,
+			Type:           d.Type,
+			Bot:            d.Bot,
+		}
+	}
+	if g := event.Geo; g != nil {
+		record.Geo = &geoRecord{Country: g.Country, Region: g.Region, City: g.City}
+	}
+	return record
+}
+
+func (r eventRecord) toDomain() domain.Event {
+	id, _ := uuid.Parse(r.ID)
+	event := domain.Event{
+		ID:         id,
+		Name:       r.Name,
+		UserID:     r.UserID,
+		Source:     r.Source,
+		Metadata:   json.RawMessage(r.Metadata),
+		OccurredAt: r.OccurredAt.UTC(),
+		ReceivedAt: r.ReceivedAt.UTC(),
+		UserAgent:  r.UserAgent,
+		IP:         r.IP,
+		Consent:    r.Consent,
+		Restored:   r.Restored,
+	}
+	if d := r.Device; d != nil {
+		event.Device = &domain.Device{
+			Browser:        d.Browser,
+			BrowserVersion: d.BrowserVersion,
+			OS:             d.OS,
+			OSVersion:      d.OSVersion,
+			Type:           d.Type,
+			Bot:            d.Bot,
+		}
+	}
+	if g := r.Geo; g != nil {
+		event.Geo = &domain.Geo{Country: g.Country, Region: g.Region, City: g.City}
+	}
+	return event
+}
@@ -2,7 +2,10 @@ package mongo
 
 import (
 	"context"
+	"sort"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -12,38 +15,471 @@ import (
 	"quotesnap/internal/core/usecase"
 )
 
+const receivedAtIndexName = "received_at_1"
+
+const (
+	// EventStorageCollection stores events as regular documents in the events collection.
+	EventStorageCollection = "collection"
+	// EventStorageTimeSeries stores events in the events_ts time-series collection. Ingest and
+	// queries work from MongoDB 5.0. Indexes on user_id and received_at need 6.0, and upserts,
+	// erasure, retention purges and archival deletes need 7.0, the first release allowing deletes
+	// and updates on fields other than the meta field. On older servers those operations fail
+	// with ErrTimeSeriesUnsupported.
+	EventStorageTimeSeries = "timeseries"
+)
+
+// EventStorage selects the collection layout used for events.
+type EventStorage struct {
+	Mode string
+	// Granularity is the time-series bucket granularity: seconds, minutes or hours.
+	Granularity string
+}
+
+// CollectionName returns the name of the collection holding events for the storage mode.
+func (s EventStorage) CollectionName() string {
+	if s.Mode == EventStorageTimeSeries {
+		return "events_ts"
+	}
+	return "events"
+}
+
+// ErrTimeSeriesUnsupported reports an operation the MongoDB server cannot run on a time-series
+// collection.
+var ErrTimeSeriesUnsupported = errors.New("operation on time-series events requires MongoDB 7.0 or later")
+
 // EventRepository stores events inside MongoDB with bounded indexes.
 type EventRepository struct {
 	collection *mongo.Collection
+	timeSeries bool
+	// serverVersion is the major version of the server, only read in time-series mode.
+	serverVersion int
+	retention     domain.RetentionPolicy
 }
 
-// NewEventRepository wires a Mongo collection into a repository implementation.
-func NewEventRepository(db *mongo.Database) (*EventRepository, error) {
-	collection := db.Collection("events")
-	if err := ensureIndexes(context.Background(), collection); err != nil {
+// NewEventRepository wires a Mongo collection into a repository implementation. The retention
+// policy decides whether expiry is delegated to a TTL index or to PurgeExpired; the TTL itself is
+// only set by ApplyRetention, so processes configured differently do not fight over it.
+//
+// In time-series mode occurred_at is the time field and {name, source} the meta field. Retention
+// is always enforced by PurgeExpired there, because native time-series expiry keys off
+// occurred_at while retention is defined on received_at.
+func NewEventRepository(db *mongo.Database, storage EventStorage, retention domain.RetentionPolicy) (*EventRepository, error) {
+	ctx := context.Background()
+	switch storage.Mode {
+	case EventStorageCollection:
+	case EventStorageTimeSeries:
+		if err := ensureTimeSeriesCollection(ctx, db, storage); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unknown event storage mode %q", storage.Mode)
+	}
+
+	repo := &EventRepository{
+		collection: db.Collection(storage.CollectionName()),
+		timeSeries: storage.Mode == EventStorageTimeSeries,
+		retention:  retention,
+	}
+	if repo.timeSeries {
+		version, err := serverMajorVersion(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		repo.serverVersion = version
+	}
+	if err := repo.ensureIndexes(ctx); err != nil {
 		return nil, errors.Wrap(err, "ensure indexes")
 	}
-	return &EventRepository{collection: collection}, nil
+	return repo, nil
 }
 
-// Persist writes a single event document.
+// Persist writes a single event document, returning usecase.ErrDuplicateEvent when its id is
+// already stored. Time-series collections have no unique _id index, so there the stored copy is
+// looked up first. Without an index the lookup cannot exclude a copy inserted concurrently by
+// another delivery of the same event; observers skip such an event all the same.
 func (r *EventRepository) Persist(ctx context.Context, event domain.Event) error {
-	_, err := r.collection.InsertOne(ctx, bson.M{
+	if r.timeSeries {
+		n, err := r.collection.CountDocuments(ctx, r.timeSeriesKey(event), options.Count().SetLimit(1))
+		if err != nil {
+			return errors.Wrap(classifyWriteError(err), "find stored event")
+		}
+		if n > 0 {
+			return errors.Wrapf(usecase.ErrDuplicateEvent, "event %s", event.ID)
+		}
+	}
+	_, err := r.collection.InsertOne(ctx, r.record(event))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.Wrapf(usecase.ErrDuplicateEvent, "event %s", event.ID)
+	}
+	return errors.Wrap(classifyWriteError(err), "insert event")
+}
+
+// timeSeriesKey matches the stored copy of event. Matching on the meta and time fields lets
+// MongoDB prune buckets instead of scanning.
+func (r *EventRepository) timeSeriesKey(event domain.Event) bson.M {
+	return bson.M{
 		"_id":         event.ID.String(),
-		"name":        event.Name,
-		"user_id":     event.UserID,
-		"source":      event.Source,
-		"metadata":    event.Metadata,
+		"meta.name":   event.Name,
+		"meta.source": event.Source,
 		"occurred_at": event.OccurredAt,
-		"received_at": event.ReceivedAt,
-	})
-	return errors.Wrap(err, "insert event")
+	}
+}
+
+// requireTimeSeriesWrites fails on servers that cannot delete or update time-series documents
+// by fields other than the meta field.
+func (r *EventRepository) requireTimeSeriesWrites() error {
+	if r.timeSeries && r.serverVersion < 7 {
+		return usecase.Permanent(ErrTimeSeriesUnsupported)
+	}
+	return nil
+}
+
+// Upsert replaces the stored event with the same id, inserting it when absent. Time-series
+// collections cannot upsert, so there the stored copy is deleted before inserting.
+func (r *EventRepository) Upsert(ctx context.Context, event domain.Event) error {
+	if r.timeSeries {
+		if err := r.requireTimeSeriesWrites(); err != nil {
+			return err
+		}
+		if _, err := r.collection.DeleteMany(ctx, r.timeSeriesKey(event)); err != nil {
+			return errors.Wrap(classifyWriteError(err), "delete replaced event")
+		}
+		return r.Persist(ctx, event)
+	}
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": event.ID.String()}, r.record(event), options.Replace().SetUpsert(true))
+	return errors.Wrap(classifyWriteError(err), "upsert event")
+}
+
+// documentValidationFailure is the MongoDB error code of a write rejected by a schema validator.
+const documentValidationFailure = 121
+
+// classifyWriteError marks writes MongoDB rejects for the event itself as permanent and timeouts
+// and connection failures as transient.
+func classifyWriteError(err error) error {
+	var writeErr mongo.WriteException
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &writeErr) && writeErr.HasErrorCode(documentValidationFailure):
+		return usecase.Permanent(err)
+	case mongo.IsTimeout(err), mongo.IsNetworkError(err):
+		return usecase.Transient(err)
+	}
+	return err
+}
+
+// EachEvent streams events matching query in reception order, or occurrence order when the query
+// selects by occurrence time. Metadata is stored as raw bytes, so metadata filters are applied to
+// the decoded events rather than by MongoDB.
+func (r *EventRepository) EachEvent(ctx context.Context, query usecase.EventQuery, fn func(domain.Event) error) error {
+	timeField := "received_at"
+	if query.ByOccurrence {
+		timeField = "occurred_at"
+	}
+
+	filter := bson.M{}
+	window := bson.M{}
+	if !query.Since.IsZero() {
+		window["$gte"] = query.Since
+	}
+	if !query.Until.IsZero() {
+		window["$lt"] = query.Until
+	}
+	if len(window) > 0 {
+		filter[timeField] = window
+	}
+	if query.Name != "" {
+		filter[r.field("name")] = query.Name
+	}
+	if query.Source != "" {
+		filter[r.field("source")] = query.Source
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: timeField, Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return errors.Wrap(err, "find events")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record eventRecord
+		if err := cursor.Decode(&record); err != nil {
+			return errors.Wrap(err, "decode event")
+		}
+		event := record.toDomain()
+		if len(query.Metadata) > 0 && !query.Matches(event) {
+			continue
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(cursor.Err(), "iterate events")
+}
+
+// EachUserEvent streams every event belonging to userID in occurrence order.
+func (r *EventRepository) EachUserEvent(ctx context.Context, userID string, fn func(domain.Event) error) error {
+	opts := options.Find().SetSort(bson.D{{Key: "occurred_at", Value: 1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		return errors.Wrap(err, "find user events")
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var record eventRecord
+		if err := cursor.Decode(&record); err != nil {
+			return errors.Wrap(err, "decode event")
+		}
+		if err := fn(record.toDomain()); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(cursor.Err(), "iterate user events")
+}
+
+// UserProfile aggregates a summary of the events stored for userID.
+func (r *EventRepository) UserProfile(ctx context.Context, userID string) (domain.UserProfile, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":         nil,
+			"event_count": bson.M{"$sum": 1},
+			"first_seen":  bson.M{"$min": "$occurred_at"},
+			"last_seen":   bson.M{"$max": "$occurred_at"},
+			"sources":     bson.M{"$addToSet": "$source"},
+			"event_names": bson.M{"$addToSet": "$name"},
+		}}},
+	}
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "aggregate user profile")
+	}
+	defer cursor.Close(ctx)
+
+	profile := domain.UserProfile{UserID: userID, Sources: []string{}, EventNames: []string{}}
+	if !cursor.Next(ctx) {
+		return profile, errors.Wrap(cursor.Err(), "read user profile")
+	}
+
+	var row struct {
+		EventCount int64     `bson:"event_count"`
+		FirstSeen  time.Time `bson:"first_seen"`
+		LastSeen   time.Time `bson:"last_seen"`
+		Sources    []string  `bson:"sources"`
+		EventNames []string  `bson:"event_names"`
+	}
+	if err := cursor.Decode(&row); err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "decode user profile")
+	}
+	sort.Strings(row.Sources)
+	sort.Strings(row.EventNames)
+
+	profile.EventCount = row.EventCount
+	profile.FirstSeen = row.FirstSeen.UTC()
+	profile.LastSeen = row.LastSeen.UTC()
+	profile.Sources = row.Sources
+	profile.EventNames = row.EventNames
+	return profile, nil
+}
+
+// EraseUser deletes or anonymizes every event belonging to userID. Anonymized events are
+// reassigned to a random pseudonym and stripped of metadata and client details.
+func (r *EventRepository) EraseUser(ctx context.Context, userID string, mode domain.ErasureMode) (int64, error) {
+	if err := r.requireTimeSeriesWrites(); err != nil {
+		return 0, err
+	}
+	filter := bson.M{"user_id": userID}
+
+	if mode == domain.ErasureModeAnonymize {
+		res, err := r.collection.UpdateMany(ctx, filter, bson.M{
+			"$set": bson.M{
+				"user_id":  "anon-" + uuid.NewString(),
+				"metadata": []byte("{}"),
+			},
+			"$unset": bson.M{"user_agent": "", "ip": "", "geo.city": "", "geo.region": ""},
+		})
+		if err != nil {
+			return 0, errors.Wrap(err, "anonymize user events")
+		}
+		return res.ModifiedCount, nil
+	}
+
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, errors.Wrap(err, "delete user events")
+	}
+	return res.DeletedCount, nil
+}
+
+// EventsReceivedBefore returns up to limit of the oldest events received before cutoff.
+func (r *EventRepository) EventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) ([]domain.Event, error) {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "received_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"received_at": bson.M{"$lt": cutoff}, "restored": bson.M{"$ne": true}}, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "find aged events")
+	}
+
+	var records []eventRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode aged events")
+	}
+	events := make([]domain.Event, 0, len(records))
+	for _, record := range records {
+		events = append(events, record.toDomain())
+	}
+	return events, nil
+}
+
+// DeleteEvents removes the events with the given ids.
+func (r *EventRepository) DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	if err := r.requireTimeSeriesWrites(); err != nil {
+		return 0, err
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	res, err := r.collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return 0, errors.Wrap(err, "delete events")
+	}
+	return res.DeletedCount, nil
+}
+
+// RequiresPurge reports whether the retention policy cannot be expressed as a TTL index and
+// PurgeExpired must be scheduled.
+func (r *EventRepository) RequiresPurge() bool {
+	if r.timeSeries {
+		return r.retention.Enabled()
+	}
+	return !r.retention.Uniform()
+}
+
+// PurgeExpired deletes events older than the retention that applies to them. Name rules win over
+// source rules, which win over the default.
+func (r *EventRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	if err := r.requireTimeSeriesWrites(); err != nil {
+		return 0, err
+	}
+	var names, sources []string
+	for _, rule := range r.retention.Overrides {
+		if rule.Field == domain.RetentionFieldName {
+			names = append(names, rule.Value)
+		} else {
+			sources = append(sources, rule.Value)
+		}
+	}
+
+	nameField, sourceField := r.field("name"), r.field("source")
+	var filters []bson.M
+	for _, rule := range r.retention.Overrides {
+		filter := bson.M{"received_at": bson.M{"$lt": now.Add(-rule.TTL)}}
+		if rule.Field == domain.RetentionFieldName {
+			filter[nameField] = rule.Value
+		} else {
+			filter[sourceField] = rule.Value
+			if len(names) > 0 {
+				filter[nameField] = bson.M{"$nin": names}
+			}
+		}
+		filters = append(filters, filter)
+	}
+	if r.retention.Default > 0 {
+		filter := bson.M{"received_at": bson.M{"$lt": now.Add(-r.retention.Default)}}
+		if len(names) > 0 {
+			filter[nameField] = bson.M{"$nin": names}
+		}
+		if len(sources) > 0 {
+			filter[sourceField] = bson.M{"$nin": sources}
+		}
+		filters = append(filters, filter)
+	}
+
+	var deleted int64
+	for _, filter := range filters {
+		res, err := r.collection.DeleteMany(ctx, filter)
+		if err != nil {
+			return deleted, errors.Wrap(err, "delete expired events")
+		}
+		deleted += res.DeletedCount
+	}
+	return deleted, nil
 }
 
 // Ensure interface compliance at compile-time.
-var _ usecase.EventRepository = (*EventRepository)(nil)
+var (
+	_ usecase.EventRepository      = (*EventRepository)(nil)
+	_ usecase.UserEventStore       = (*EventRepository)(nil)
+	_ usecase.EventPurger          = (*EventRepository)(nil)
+	_ usecase.ArchivableEventStore = (*EventRepository)(nil)
+	_ usecase.EventSource          = (*EventRepository)(nil)
+)
 
-func ensureIndexes(ctx context.Context, collection *mongo.Collection) error {
+// field maps a top-level event field to the one to filter on. Time-series collections filter on
+// the meta field copy so that MongoDB can prune whole buckets.
+func (r *EventRepository) field(name string) string {
+	if r.timeSeries && (name == "name" || name == "source") {
+		return "meta." + name
+	}
+	return name
+}
+
+func (r *EventRepository) record(event domain.Event) eventRecord {
+	record := newEventRecord(event)
+	if r.timeSeries {
+		record.Meta = &eventMetaRecord{Name: event.Name, Source: event.Source}
+	}
+	return record
+}
+
+// ensureTimeSeriesCollection creates the time-series collection on first use and refuses to run
+// against an existing regular collection of the same name.
+func ensureTimeSeriesCollection(ctx context.Context, db *mongo.Database, storage EventStorage) error {
+	name := storage.CollectionName()
+	specs, err := db.ListCollectionSpecifications(ctx, bson.M{"name": name})
+	if err != nil {
+		return errors.Wrap(err, "list collections")
+	}
+	if len(specs) > 0 {
+		if specs[0].Type != "timeseries" {
+			return errors.Errorf("collection %s exists but is not a time-series collection", name)
+		}
+		return nil
+	}
+
+	tsOpts := options.TimeSeries().SetTimeField("occurred_at").SetMetaField("meta")
+	if storage.Granularity != "" {
+		tsOpts.SetGranularity(storage.Granularity)
+	}
+	err = db.CreateCollection(ctx, name, options.CreateCollection().SetTimeSeriesOptions(tsOpts))
+	return errors.Wrap(err, "create time-series collection")
+}
+
+// serverMajorVersion reads the major version of the MongoDB server behind db.
+func serverMajorVersion(ctx context.Context, db *mongo.Database) (int, error) {
+	var info struct {
+		VersionArray []int32 `bson:"versionArray"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "buildInfo", Value: 1}}).Decode(&info); err != nil {
+		return 0, errors.Wrap(err, "read server version")
+	}
+	if len(info.VersionArray) == 0 {
+		return 0, errors.New("server reported no version")
+	}
+	return int(info.VersionArray[0]), nil
+}
+
+func (r *EventRepository) ensureIndexes(ctx context.Context) error {
+	if r.timeSeries && r.serverVersion < 6 {
+		// MongoDB 5.0 only indexes the time and meta fields of time-series collections.
+		return nil
+	}
+	collection := r.collection
 	model := mongo.IndexModel{
 		Keys: bson.D{
 			{Key: "user_id", Value: 1},
@@ -51,6 +487,95 @@ func ensureIndexes(ctx context.Context, collection *mongo.Collection) error {
 		},
 		Options: options.Index().SetBackground(true),
 	}
-	_, err := collection.Indexes().CreateOne(ctx, model)
-	return err
+	if _, err := collection.Indexes().CreateOne(ctx, model); err != nil {
+		return err
+	}
+	// The received_at index serves purge queries; whatever TTL it carries is left alone here.
+	if _, found, err := findIndex(ctx, collection, receivedAtIndexName); err != nil || found {
+		return err
+	}
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "received_at", Value: 1}},
+		Options: options.Index().SetName(receivedAtIndexName).SetBackground(true),
+	})
+	return errors.Wrapf(err, "create index %s", receivedAtIndexName)
+}
+
+// ApplyRetention sets the TTL of the received_at index from the retention policy. It carries
+// expireAfterSeconds only outside time-series mode and when the policy is a single non-zero
+// default; otherwise the index only serves purge queries. Only the worker and quotesnapctl
+// indexes create call it.
+func (r *EventRepository) ApplyRetention(ctx context.Context) error {
+	if r.timeSeries && r.serverVersion < 6 {
+		return nil
+	}
+	var wantTTL *int32
+	if !r.timeSeries && r.retention.Uniform() && r.retention.Default > 0 {
+		seconds := int32(r.retention.Default / time.Second)
+		wantTTL = &seconds
+	}
+	return ensureExpiringIndex(ctx, r.collection, receivedAtIndexName, "received_at", wantTTL)
+}
+
+type indexSpec struct {
+	Name               string `bson:"name"`
+	ExpireAfterSeconds *int32 `bson:"expireAfterSeconds"`
+}
+
+// findIndex looks up the index called name.
+func findIndex(ctx context.Context, collection *mongo.Collection, name string) (indexSpec, bool, error) {
+	cursor, err := collection.Indexes().List(ctx)
+	if err != nil {
+		return indexSpec{}, false, errors.Wrap(err, "list indexes")
+	}
+	var indexes []indexSpec
+	if err := cursor.All(ctx, &indexes); err != nil {
+		return indexSpec{}, false, errors.Wrap(err, "decode indexes")
+	}
+	for _, index := range indexes {
+		if index.Name == name {
+			return index, true, nil
+		}
+	}
+	return indexSpec{}, false, nil
+}
+
+// ensureExpiringIndex maintains the ascending index name on field, with expireAfterSeconds set
+// to wantTTL or unset when it is nil. A different TTL is changed in place with collMod, which
+// only rewrites index metadata; the index is rebuilt only when a TTL must be added or removed and
+// collMod cannot.
+func ensureExpiringIndex(ctx context.Context, collection *mongo.Collection, name, field string, wantTTL *int32) error {
+	index, found, err := findIndex(ctx, collection, name)
+	if err != nil {
+		return err
+	}
+	if found {
+		current := index.ExpireAfterSeconds
+		if (current == nil && wantTTL == nil) || (current != nil && wantTTL != nil && *current == *wantTTL) {
+			return nil
+		}
+		if wantTTL != nil {
+			// MongoDB 5.1 and later also turn a plain single-field index into a TTL index this way.
+			err := collection.Database().RunCommand(ctx, bson.D{
+				{Key: "collMod", Value: collection.Name()},
+				{Key: "index", Value: bson.D{{Key: "name", Value: name}, {Key: "expireAfterSeconds", Value: *wantTTL}}},
+			}).Err()
+			if err == nil {
+				return nil
+			}
+		}
+		if _, err := collection.Indexes().DropOne(ctx, name); err != nil {
+			return errors.Wrapf(err, "drop index %s", name)
+		}
+	}
+
+	opts := options.Index().SetName(name).SetBackground(true)
+	if wantTTL != nil {
+		opts.SetExpireAfterSeconds(*wantTTL)
+	}
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: field, Value: 1}},
+		Options: opts,
+	})
+	return errors.Wrapf(err, "create index %s", name)
 }
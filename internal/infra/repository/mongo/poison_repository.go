@@ -0,0 +1,117 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const poisonExpiryIndexName = "failed_at_ttl"
+
+// PoisonEventRepository stores payloads of tasks that failed permanently. Payloads hold raw
+// events, so they expire after a retention period and are erased with their user.
+type PoisonEventRepository struct {
+	collection *mongo.Collection
+}
+
+// NewPoisonEventRepository wires the poison_events collection into a repository implementation.
+// Poison events expire retention after they failed.
+func NewPoisonEventRepository(ctx context.Context, db *mongo.Database, retention time.Duration) (*PoisonEventRepository, error) {
+	collection := db.Collection("poison_events")
+	_, err := collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "error_class", Value: 1}, {Key: "failed_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ensure poison event indexes")
+	}
+	ttl := int32(retention / time.Second)
+	if err := ensureExpiringIndex(ctx, collection, poisonExpiryIndexName, "failed_at", &ttl); err != nil {
+		return nil, err
+	}
+	return &PoisonEventRepository{collection: collection}, nil
+}
+
+type poisonEventRecord struct {
+	ID         string    `bson:"_id"`
+	TaskType   string    `bson:"task_type"`
+	UserID     string    `bson:"user_id,omitempty"`
+	Payload    []byte    `bson:"payload"`
+	Error      string    `bson:"error"`
+	ErrorClass string    `bson:"error_class"`
+	FailedAt   time.Time `bson:"failed_at"`
+}
+
+// SavePoisonEvent inserts event. The payload is kept as raw bytes since it may not be valid JSON.
+func (r *PoisonEventRepository) SavePoisonEvent(ctx context.Context, event domain.PoisonEvent) error {
+	_, err := r.collection.InsertOne(ctx, poisonEventRecord{
+		ID:         event.ID.String(),
+		TaskType:   event.TaskType,
+		UserID:     event.UserID,
+		Payload:    event.Payload,
+		Error:      event.Error,
+		ErrorClass: event.ErrorClass,
+		FailedAt:   event.FailedAt,
+	})
+	return errors.Wrap(err, "insert poison event")
+}
+
+// Name identifies the section in exports and erasure reports.
+func (r *PoisonEventRepository) Name() string {
+	return "poison_events"
+}
+
+// ExportUser returns the poison events recorded for userID, or nil when there are none.
+func (r *PoisonEventRepository) ExportUser(ctx context.Context, userID string) (any, error) {
+	cursor, err := r.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "failed_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "find poison events")
+	}
+	var records []poisonEventRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode poison events")
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	events := make([]domain.PoisonEvent, 0, len(records))
+	for _, record := range records {
+		event := domain.PoisonEvent{
+			TaskType:   record.TaskType,
+			UserID:     record.UserID,
+			Payload:    record.Payload,
+			Error:      record.Error,
+			ErrorClass: record.ErrorClass,
+			FailedAt:   record.FailedAt,
+		}
+		if id, err := uuid.Parse(record.ID); err == nil {
+			event.ID = id
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// EraseUser deletes the poison events of userID. Both erasure modes delete them, since a raw
+// payload cannot be anonymized field by field.
+func (r *PoisonEventRepository) EraseUser(ctx context.Context, userID string, _ domain.ErasureMode) (int64, error) {
+	res, err := r.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, errors.Wrap(err, "delete poison events")
+	}
+	return res.DeletedCount, nil
+}
+
+// Ensure PoisonEventRepository satisfies the PoisonEventStore and UserDataSection dependencies.
+var (
+	_ usecase.PoisonEventStore = (*PoisonEventRepository)(nil)
+	_ usecase.UserDataSection  = (*PoisonEventRepository)(nil)
+)
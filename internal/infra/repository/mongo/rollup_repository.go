@@ -0,0 +1,146 @@
+package mongo
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// RollupRepository stores minute, hour and day event counters in the event_rollups collection.
+// Documents are keyed by granularity, bucket, name and source so increments are plain upserts.
+type RollupRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRollupRepository wires the rollup collection into a repository implementation.
+func NewRollupRepository(ctx context.Context, db *mongo.Database) (*RollupRepository, error) {
+	collection := db.Collection("event_rollups")
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "granularity", Value: 1}, {Key: "bucket", Value: 1}},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ensure rollup index")
+	}
+	return &RollupRepository{collection: collection}, nil
+}
+
+type rollupKey struct {
+	Granularity string    `bson:"g"`
+	Bucket      time.Time `bson:"b"`
+	Name        string    `bson:"n"`
+	Source      string    `bson:"s"`
+}
+
+type rollupRecord struct {
+	ID          rollupKey `bson:"_id"`
+	Granularity string    `bson:"granularity"`
+	Bucket      time.Time `bson:"bucket"`
+	Name        string    `bson:"name"`
+	Source      string    `bson:"source"`
+	Count       int64     `bson:"count"`
+}
+
+func newRollupKey(rollup domain.Rollup) rollupKey {
+	return rollupKey{
+		Granularity: string(rollup.Granularity),
+		Bucket:      rollup.Bucket,
+		Name:        rollup.Name,
+		Source:      rollup.Source,
+	}
+}
+
+// IncrementRollups adds each rollup's count to its stored counter in a single unordered bulk write.
+func (r *RollupRepository) IncrementRollups(ctx context.Context, rollups []domain.Rollup) error {
+	models := make([]mongo.WriteModel, 0, len(rollups))
+	for _, rollup := range rollups {
+		models = append(models, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": newRollupKey(rollup)}).
+			SetUpdate(bson.M{
+				"$inc": bson.M{"count": rollup.Count},
+				"$setOnInsert": bson.M{
+					"granularity": string(rollup.Granularity),
+					"bucket":      rollup.Bucket,
+					"name":        rollup.Name,
+					"source":      rollup.Source,
+				},
+			}).
+			SetUpsert(true))
+	}
+	_, err := r.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return errors.Wrap(err, "increment rollups")
+}
+
+// QueryRollups returns rollups matching query ordered by bucket, name and source.
+func (r *RollupRepository) QueryRollups(ctx context.Context, query usecase.RollupQuery) ([]domain.Rollup, error) {
+	filter := bson.M{
+		"granularity": string(query.Granularity),
+		"bucket":      bson.M{"$gte": query.From, "$lt": query.To},
+	}
+	if query.Name != "" {
+		filter["name"] = query.Name
+	}
+	if query.Source != "" {
+		filter["source"] = query.Source
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "bucket", Value: 1}, {Key: "name", Value: 1}, {Key: "source", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "find rollups")
+	}
+
+	var records []rollupRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode rollups")
+	}
+	rollups := make([]domain.Rollup, 0, len(records))
+	for _, record := range records {
+		rollups = append(rollups, domain.Rollup{
+			Granularity: domain.RollupGranularity(record.Granularity),
+			Bucket:      record.Bucket.UTC(),
+			Name:        record.Name,
+			Source:      record.Source,
+			Count:       record.Count,
+		})
+	}
+	return rollups, nil
+}
+
+// ReplaceRollups deletes the rollups with a bucket within [from, to) and inserts rollups.
+func (r *RollupRepository) ReplaceRollups(ctx context.Context, from, to time.Time, rollups []domain.Rollup) error {
+	granularities := make(bson.A, 0, len(domain.RollupGranularities))
+	for _, g := range domain.RollupGranularities {
+		granularities = append(granularities, string(g))
+	}
+	// Listing every granularity lets the delete use the (granularity, bucket) index.
+	filter := bson.M{"granularity": bson.M{"$in": granularities}, "bucket": bson.M{"$gte": from, "$lt": to}}
+	if _, err := r.collection.DeleteMany(ctx, filter); err != nil {
+		return errors.Wrap(err, "delete rollups")
+	}
+	if len(rollups) == 0 {
+		return nil
+	}
+
+	docs := make([]interface{}, 0, len(rollups))
+	for _, rollup := range rollups {
+		docs = append(docs, rollupRecord{
+			ID:          newRollupKey(rollup),
+			Granularity: string(rollup.Granularity),
+			Bucket:      rollup.Bucket,
+			Name:        rollup.Name,
+			Source:      rollup.Source,
+			Count:       rollup.Count,
+		})
+	}
+	_, err := r.collection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	return errors.Wrap(err, "insert rollups")
+}
+
+// Ensure RollupRepository satisfies the RollupStore dependency.
+var _ usecase.RollupStore = (*RollupRepository)(nil)
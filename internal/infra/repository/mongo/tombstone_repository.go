@@ -0,0 +1,115 @@
+package mongo
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/usecase"
+)
+
+// TombstoneRepository records erased users. Lookups are served from an in-memory set that is
+// refreshed periodically so the ingest hot path does not hit MongoDB for every event.
+type TombstoneRepository struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+
+	mu    sync.RWMutex
+	users map[string]struct{}
+}
+
+// NewTombstoneRepository wires the user_tombstones collection and loads the current set.
+func NewTombstoneRepository(ctx context.Context, db *mongo.Database, logger *slog.Logger) (*TombstoneRepository, error) {
+	r := &TombstoneRepository{
+		collection: db.Collection("user_tombstones"),
+		logger:     logger.With("component", "tombstones"),
+		users:      make(map[string]struct{}),
+	}
+	if err := r.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateTombstone marks userID as erased.
+func (r *TombstoneRepository) CreateTombstone(ctx context.Context, userID string, requestID uuid.UUID) error {
+	_, err := r.collection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{
+			"$set":         bson.M{"request_id": requestID.String()},
+			"$setOnInsert": bson.M{"created_at": time.Now().UTC()},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return errors.Wrap(err, "upsert tombstone")
+	}
+
+	r.mu.Lock()
+	r.users[userID] = struct{}{}
+	r.mu.Unlock()
+	return nil
+}
+
+// IsTombstoned reports whether userID has been erased, as of the last refresh.
+func (r *TombstoneRepository) IsTombstoned(_ context.Context, userID string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.users[userID]
+	return ok, nil
+}
+
+// Refresh reloads the tombstone set from MongoDB.
+func (r *TombstoneRepository) Refresh(ctx context.Context) error {
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return errors.Wrap(err, "find tombstones")
+	}
+	defer cursor.Close(ctx)
+
+	users := make(map[string]struct{})
+	for cursor.Next(ctx) {
+		var row struct {
+			UserID string `bson:"_id"`
+		}
+		if err := cursor.Decode(&row); err != nil {
+			return errors.Wrap(err, "decode tombstone")
+		}
+		users[row.UserID] = struct{}{}
+	}
+	if err := cursor.Err(); err != nil {
+		return errors.Wrap(err, "iterate tombstones")
+	}
+
+	r.mu.Lock()
+	r.users = users
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch refreshes the tombstone set every interval until ctx is cancelled.
+func (r *TombstoneRepository) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				r.logger.Warn("tombstone refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Ensure interface compliance at compile-time.
+var _ usecase.TombstoneStore = (*TombstoneRepository)(nil)
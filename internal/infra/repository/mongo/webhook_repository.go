@@ -0,0 +1,355 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const deliveryExpiryIndexName = "created_at_ttl"
+
+// WebhookRepository stores webhook subscriptions and their delivery log. Active subscriptions are
+// also kept in memory, refreshed by Watch, so dispatching does not query MongoDB for every event.
+type WebhookRepository struct {
+	subscriptions *mongo.Collection
+	deliveries    *mongo.Collection
+	logger        *slog.Logger
+
+	mu     sync.RWMutex
+	active []domain.WebhookSubscription
+}
+
+// NewWebhookRepository wires the webhook collections into a repository implementation. Delivery
+// log entries expire after deliveryRetention.
+func NewWebhookRepository(ctx context.Context, db *mongo.Database, deliveryRetention time.Duration, logger *slog.Logger) (*WebhookRepository, error) {
+	r := &WebhookRepository{
+		subscriptions: db.Collection("webhook_subscriptions"),
+		deliveries:    db.Collection("webhook_deliveries"),
+		logger:        logger.With("component", "webhooks"),
+	}
+
+	_, err := r.deliveries.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "subscription_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}, Options: options.Index().SetSparse(true)},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "ensure delivery indexes")
+	}
+	ttl := int32(deliveryRetention / time.Second)
+	if err := ensureExpiringIndex(ctx, r.deliveries, deliveryExpiryIndexName, "created_at", &ttl); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+type webhookSubscriptionRecord struct {
+	ID        string    `bson:"_id"`
+	EventName string    `bson:"event_name"`
+	Source    string    `bson:"source,omitempty"`
+	URL       string    `bson:"url"`
+	Secret    string    `bson:"secret"`
+	Active    bool      `bson:"active"`
+	CreatedAt time.Time `bson:"created_at"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type webhookDeliveryRecord struct {
+	ID             string    `bson:"_id"`
+	SubscriptionID string    `bson:"subscription_id"`
+	EventID        string    `bson:"event_id"`
+	EventName      string    `bson:"event_name"`
+	UserID         string    `bson:"user_id,omitempty"`
+	Payload        []byte    `bson:"payload"`
+	Status         string    `bson:"status"`
+	Attempts       int       `bson:"attempts"`
+	LastStatusCode int       `bson:"last_status_code,omitempty"`
+	LastError      string    `bson:"last_error,omitempty"`
+	RedeliveryOf   string    `bson:"redelivery_of,omitempty"`
+	CreatedAt      time.Time `bson:"created_at"`
+	UpdatedAt      time.Time `bson:"updated_at"`
+}
+
+// CreateSubscription inserts a new subscription.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	_, err := r.subscriptions.InsertOne(ctx, newWebhookSubscriptionRecord(sub))
+	return errors.Wrap(err, "insert webhook subscription")
+}
+
+// GetSubscription loads a subscription by id.
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id uuid.UUID) (domain.WebhookSubscription, error) {
+	var record webhookSubscriptionRecord
+	err := r.subscriptions.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.WebhookSubscription{}, usecase.ErrNotFound
+	}
+	if err != nil {
+		return domain.WebhookSubscription{}, errors.Wrap(err, "find webhook subscription")
+	}
+	return record.toDomain(), nil
+}
+
+// ListSubscriptions returns every subscription in creation order.
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]domain.WebhookSubscription, error) {
+	return r.findSubscriptions(ctx, bson.M{})
+}
+
+// UpdateSubscription replaces the stored state of a subscription.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub domain.WebhookSubscription) error {
+	res, err := r.subscriptions.ReplaceOne(ctx, bson.M{"_id": sub.ID.String()}, newWebhookSubscriptionRecord(sub))
+	if err != nil {
+		return errors.Wrap(err, "replace webhook subscription")
+	}
+	if res.MatchedCount == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+// DeleteSubscription removes a subscription.
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id uuid.UUID) error {
+	res, err := r.subscriptions.DeleteOne(ctx, bson.M{"_id": id.String()})
+	if err != nil {
+		return errors.Wrap(err, "delete webhook subscription")
+	}
+	if res.DeletedCount == 0 {
+		return usecase.ErrNotFound
+	}
+	return nil
+}
+
+// ActiveSubscriptions returns the active subscriptions as of the last refresh.
+func (r *WebhookRepository) ActiveSubscriptions(_ context.Context) ([]domain.WebhookSubscription, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.active, nil
+}
+
+// Refresh reloads the active subscriptions from MongoDB.
+func (r *WebhookRepository) Refresh(ctx context.Context) error {
+	active, err := r.findSubscriptions(ctx, bson.M{"active": true})
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.active = active
+	r.mu.Unlock()
+	return nil
+}
+
+// Watch refreshes the active subscriptions every interval until ctx is cancelled.
+func (r *WebhookRepository) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				r.logger.Warn("webhook subscription refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+func (r *WebhookRepository) findSubscriptions(ctx context.Context, filter bson.M) ([]domain.WebhookSubscription, error) {
+	cursor, err := r.subscriptions.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "find webhook subscriptions")
+	}
+	var records []webhookSubscriptionRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode webhook subscriptions")
+	}
+	subs := make([]domain.WebhookSubscription, 0, len(records))
+	for _, record := range records {
+		subs = append(subs, record.toDomain())
+	}
+	return subs, nil
+}
+
+// CreateDelivery inserts a new delivery log entry, returning usecase.ErrDuplicateDelivery when its
+// id is already logged.
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := r.deliveries.InsertOne(ctx, newWebhookDeliveryRecord(delivery))
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.Wrapf(usecase.ErrDuplicateDelivery, "delivery %s", delivery.ID)
+	}
+	return errors.Wrap(err, "insert webhook delivery")
+}
+
+// GetDelivery loads a delivery by id.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id uuid.UUID) (domain.WebhookDelivery, error) {
+	var record webhookDeliveryRecord
+	err := r.deliveries.FindOne(ctx, bson.M{"_id": id.String()}).Decode(&record)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return domain.WebhookDelivery{}, usecase.ErrNotFound
+	}
+	if err != nil {
+		return domain.WebhookDelivery{}, errors.Wrap(err, "find webhook delivery")
+	}
+	return record.toDomain(), nil
+}
+
+// UpdateDelivery replaces the stored state of a delivery.
+func (r *WebhookRepository) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	_, err := r.deliveries.ReplaceOne(ctx, bson.M{"_id": delivery.ID.String()}, newWebhookDeliveryRecord(delivery))
+	return errors.Wrap(err, "replace webhook delivery")
+}
+
+// ListDeliveries returns up to limit of the newest deliveries of a subscription.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID uuid.UUID, status domain.WebhookDeliveryStatus, limit int) ([]domain.WebhookDelivery, error) {
+	filter := bson.M{"subscription_id": subscriptionID.String()}
+	if status != "" {
+		filter["status"] = string(status)
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
+	cursor, err := r.deliveries.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "find webhook deliveries")
+	}
+	var records []webhookDeliveryRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode webhook deliveries")
+	}
+	deliveries := make([]domain.WebhookDelivery, 0, len(records))
+	for _, record := range records {
+		deliveries = append(deliveries, record.toDomain())
+	}
+	return deliveries, nil
+}
+
+// Name identifies the delivery log in exports and erasure reports.
+func (r *WebhookRepository) Name() string {
+	return "webhook_deliveries"
+}
+
+// webhookDeliveryExport adds the body sent, which the API otherwise hides, to a delivery.
+type webhookDeliveryExport struct {
+	domain.WebhookDelivery
+	Payload json.RawMessage `json:"payload"`
+}
+
+// ExportUser returns the logged deliveries of the events of userID, or nil when there are none.
+func (r *WebhookRepository) ExportUser(ctx context.Context, userID string) (any, error) {
+	cursor, err := r.deliveries.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}}))
+	if err != nil {
+		return nil, errors.Wrap(err, "find webhook deliveries")
+	}
+	var records []webhookDeliveryRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, errors.Wrap(err, "decode webhook deliveries")
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	deliveries := make([]webhookDeliveryExport, 0, len(records))
+	for _, record := range records {
+		delivery := record.toDomain()
+		deliveries = append(deliveries, webhookDeliveryExport{WebhookDelivery: delivery, Payload: delivery.Payload})
+	}
+	return deliveries, nil
+}
+
+// EraseUser deletes the logged deliveries of the events of userID. Both erasure modes delete
+// them: the payload is the signed body that was sent, which cannot be rewritten in place.
+// Deliveries logged before user ids were recorded are left to expire.
+func (r *WebhookRepository) EraseUser(ctx context.Context, userID string, _ domain.ErasureMode) (int64, error) {
+	res, err := r.deliveries.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return 0, errors.Wrap(err, "delete webhook deliveries")
+	}
+	return res.DeletedCount, nil
+}
+
+// Ensure interface compliance at compile-time.
+var (
+	_ usecase.WebhookSubscriptionRepository = (*WebhookRepository)(nil)
+	_ usecase.WebhookSubscriptionSource     = (*WebhookRepository)(nil)
+	_ usecase.WebhookDeliveryRepository     = (*WebhookRepository)(nil)
+	_ usecase.UserDataSection               = (*WebhookRepository)(nil)
+)
+
+func newWebhookSubscriptionRecord(sub domain.WebhookSubscription) webhookSubscriptionRecord {
+	return webhookSubscriptionRecord{
+		ID:        sub.ID.String(),
+		EventName: sub.EventName,
+		Source:    sub.Source,
+		URL:       sub.URL,
+		Secret:    sub.Secret,
+		Active:    sub.Active,
+		CreatedAt: sub.CreatedAt,
+		UpdatedAt: sub.UpdatedAt,
+	}
+}
+
+func (r webhookSubscriptionRecord) toDomain() domain.WebhookSubscription {
+	id, _ := uuid.Parse(r.ID)
+	return domain.WebhookSubscription{
+		ID:        id,
+		EventName: r.EventName,
+		Source:    r.Source,
+		URL:       r.URL,
+		Secret:    r.Secret,
+		Active:    r.Active,
+		CreatedAt: r.CreatedAt.UTC(),
+		UpdatedAt: r.UpdatedAt.UTC(),
+	}
+}
+
+func newWebhookDeliveryRecord(delivery domain.WebhookDelivery) webhookDeliveryRecord {
+	record := webhookDeliveryRecord{
+		ID:             delivery.ID.String(),
+		SubscriptionID: delivery.SubscriptionID.String(),
+		EventID:        delivery.EventID.String(),
+		EventName:      delivery.EventName,
+		UserID:         delivery.UserID,
+		Payload:        delivery.Payload,
+		Status:         string(delivery.Status),
+		Attempts:       delivery.Attempts,
+		LastStatusCode: delivery.LastStatusCode,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt,
+		UpdatedAt:      delivery.UpdatedAt,
+	}
+	if delivery.RedeliveryOf != nil {
+		record.RedeliveryOf = delivery.RedeliveryOf.String()
+	}
+	return record
+}
+
+func (r webhookDeliveryRecord) toDomain() domain.WebhookDelivery {
+	id, _ := uuid.Parse(r.ID)
+	subscriptionID, _ := uuid.Parse(r.SubscriptionID)
+	eventID, _ := uuid.Parse(r.EventID)
+	delivery := domain.WebhookDelivery{
+		ID:             id,
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+		EventName:      r.EventName,
+		UserID:         r.UserID,
+		Payload:        r.Payload,
+		Status:         domain.WebhookDeliveryStatus(r.Status),
+		Attempts:       r.Attempts,
+		LastStatusCode: r.LastStatusCode,
+		LastError:      r.LastError,
+		CreatedAt:      r.CreatedAt.UTC(),
+		UpdatedAt:      r.UpdatedAt.UTC(),
+	}
+	if original, err := uuid.Parse(r.RedeliveryOf); err == nil {
+		delivery.RedeliveryOf = &original
+	}
+	return delivery
+}
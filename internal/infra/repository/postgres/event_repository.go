@@ -0,0 +1,465 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// partitionLock is the advisory lock key serialising partition creation across workers.
+const partitionLock = 7283642
+
+const eventColumns = "id, name, user_id, source, metadata, occurred_at, received_at, user_agent, ip, device, geo, consent, restored"
+
+// Connect opens a connection pool to url and verifies it.
+func Connect(ctx context.Context, url string) (*pgxpool.Pool, error) {
+	pool, err := pgxpool.New(ctx, url)
+	if err != nil {
+		return nil, errors.Wrap(err, "create postgres pool")
+	}
+	if err := pool.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, errors.Wrap(err, "ping postgres")
+	}
+	return pool, nil
+}
+
+// EventRepository stores events in a PostgreSQL table range partitioned by month of reception.
+// Partitions are created the first time an event of their month is written, and dropped once
+// every retention rule has expired them.
+type EventRepository struct {
+	pool      *pgxpool.Pool
+	retention domain.RetentionPolicy
+
+	mu         sync.Mutex
+	partitions map[time.Time]bool
+}
+
+// NewEventRepository applies pending migrations and prepares the partitions of the current and
+// next month.
+func NewEventRepository(ctx context.Context, pool *pgxpool.Pool, retention domain.RetentionPolicy) (*EventRepository, error) {
+	if err := Migrate(ctx, pool); err != nil {
+		return nil, err
+	}
+	r := &EventRepository{pool: pool, retention: retention, partitions: make(map[time.Time]bool)}
+	now := time.Now().UTC()
+	for _, at := range []time.Time{now, now.AddDate(0, 1, 0)} {
+		if err := r.ensurePartition(ctx, at); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Persist inserts a new event, returning usecase.ErrDuplicateEvent when it is already stored.
+func (r *EventRepository) Persist(ctx context.Context, event domain.Event) error {
+	if err := r.ensurePartition(ctx, event.ReceivedAt); err != nil {
+		return err
+	}
+	_, err := r.pool.Exec(ctx, "INSERT INTO events ("+eventColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", eventArgs(event)...)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return errors.Wrapf(usecase.ErrDuplicateEvent, "event %s", event.ID)
+	}
+	return errors.Wrap(classifyWriteError(err), "insert event")
+}
+
+// Upsert replaces the stored event with the same id, inserting it when absent. The old row is
+// deleted first because the primary key also covers the partition key.
+func (r *EventRepository) Upsert(ctx context.Context, event domain.Event) error {
+	if err := r.ensurePartition(ctx, event.ReceivedAt); err != nil {
+		return err
+	}
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, "DELETE FROM events WHERE id = $1", event.ID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, "INSERT INTO events ("+eventColumns+") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)", eventArgs(event)...)
+		return err
+	})
+	return errors.Wrap(classifyWriteError(err), "upsert event")
+}
+
+// uniqueViolation is the SQLSTATE of a duplicate primary key.
+const uniqueViolation = "23505"
+
+// classifyWriteError marks data exceptions and integrity constraint violations (SQLSTATE classes
+// 22 and 23) as permanent, and timeouts and failures that never reached the server as transient.
+func classifyWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &pgErr) && (strings.HasPrefix(pgErr.Code, "22") || strings.HasPrefix(pgErr.Code, "23")):
+		return usecase.Permanent(err)
+	case pgconn.Timeout(err), pgconn.SafeToRetry(err):
+		return usecase.Transient(err)
+	}
+	return err
+}
+
+// EachEvent streams events matching query in reception order, or occurrence order when the query
+// selects by occurrence time. Metadata filters compare JSON strings, like EventQuery.Matches.
+func (r *EventRepository) EachEvent(ctx context.Context, query usecase.EventQuery, fn func(domain.Event) error) error {
+	timeColumn := "received_at"
+	if query.ByOccurrence {
+		timeColumn = "occurred_at"
+	}
+
+	var where []string
+	var args []any
+	add := func(clause string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(clause, len(args)))
+	}
+	if !query.Since.IsZero() {
+		add(timeColumn+" >= $%d", query.Since)
+	}
+	if !query.Until.IsZero() {
+		add(timeColumn+" < $%d", query.Until)
+	}
+	if query.Name != "" {
+		add("name = $%d", query.Name)
+	}
+	if query.Source != "" {
+		add("source = $%d", query.Source)
+	}
+	for key, value := range query.Metadata {
+		args = append(args, key, value)
+		where = append(where, fmt.Sprintf("metadata -> $%d = to_jsonb($%d::text)", len(args)-1, len(args)))
+	}
+
+	sql := "SELECT " + eventColumns + " FROM events"
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	sql += " ORDER BY " + timeColumn + ", id"
+	return r.each(ctx, sql, args, fn)
+}
+
+// EachUserEvent streams every event belonging to userID in occurrence order.
+func (r *EventRepository) EachUserEvent(ctx context.Context, userID string, fn func(domain.Event) error) error {
+	return r.each(ctx, "SELECT "+eventColumns+" FROM events WHERE user_id = $1 ORDER BY occurred_at, id", []any{userID}, fn)
+}
+
+// UserProfile aggregates a summary of the events stored for userID.
+func (r *EventRepository) UserProfile(ctx context.Context, userID string) (domain.UserProfile, error) {
+	var (
+		count               int64
+		firstSeen, lastSeen *time.Time
+		sources, names      []string
+	)
+	err := r.pool.QueryRow(ctx, `SELECT count(*), min(occurred_at), max(occurred_at),
+		array_agg(DISTINCT source ORDER BY source), array_agg(DISTINCT name ORDER BY name)
+		FROM events WHERE user_id = $1`, userID).Scan(&count, &firstSeen, &lastSeen, &sources, &names)
+	if err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "aggregate user profile")
+	}
+
+	profile := domain.UserProfile{UserID: userID, EventCount: count, Sources: []string{}, EventNames: []string{}}
+	if count == 0 {
+		return profile, nil
+	}
+	profile.FirstSeen = firstSeen.UTC()
+	profile.LastSeen = lastSeen.UTC()
+	profile.Sources = sources
+	profile.EventNames = names
+	return profile, nil
+}
+
+// EraseUser deletes or anonymizes every event belonging to userID. Anonymized events are
+// reassigned to a random pseudonym and stripped of metadata and client details.
+func (r *EventRepository) EraseUser(ctx context.Context, userID string, mode domain.ErasureMode) (int64, error) {
+	if mode == domain.ErasureModeAnonymize {
+		tag, err := r.pool.Exec(ctx, `UPDATE events SET user_id = $2, metadata = '{}'::jsonb,
+			user_agent = NULL, ip = NULL, geo = geo - 'city' - 'region'
+			WHERE user_id = $1`, userID, "anon-"+uuid.NewString())
+		if err != nil {
+			return 0, errors.Wrap(err, "anonymize user events")
+		}
+		return tag.RowsAffected(), nil
+	}
+
+	tag, err := r.pool.Exec(ctx, "DELETE FROM events WHERE user_id = $1", userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "delete user events")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// EventsReceivedBefore returns up to limit of the oldest events received before cutoff.
+func (r *EventRepository) EventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.each(ctx, "SELECT "+eventColumns+" FROM events WHERE received_at < $1 AND NOT restored ORDER BY received_at, id LIMIT $2",
+		[]any{cutoff, limit}, func(event domain.Event) error {
+			events = append(events, event)
+			return nil
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "find aged events")
+	}
+	return events, nil
+}
+
+// DeleteEvents removes the events with the given ids.
+func (r *EventRepository) DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	tag, err := r.pool.Exec(ctx, "DELETE FROM events WHERE id = ANY($1::uuid[])", keys)
+	if err != nil {
+		return 0, errors.Wrap(err, "delete events")
+	}
+	return tag.RowsAffected(), nil
+}
+
+// RequiresPurge reports whether PurgeExpired must be scheduled. PostgreSQL has no native TTL, so
+// any retention requires it.
+func (r *EventRepository) RequiresPurge() bool {
+	return r.retention.Enabled()
+}
+
+// PurgeExpired deletes events older than the retention that applies to them. Name rules win over
+// source rules, which win over the default. Partitions past the longest retention are dropped
+// first, which spares deleting their rows one by one.
+func (r *EventRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	deleted, err := r.dropExpiredPartitions(ctx, now)
+	if err != nil {
+		return deleted, err
+	}
+
+	names, sources := []string{}, []string{}
+	for _, rule := range r.retention.Overrides {
+		if rule.Field == domain.RetentionFieldName {
+			names = append(names, rule.Value)
+		} else {
+			sources = append(sources, rule.Value)
+		}
+	}
+
+	type deletion struct {
+		sql  string
+		args []any
+	}
+	var deletions []deletion
+	for _, rule := range r.retention.Overrides {
+		if rule.Field == domain.RetentionFieldName {
+			deletions = append(deletions, deletion{"DELETE FROM events WHERE received_at < $1 AND name = $2",
+				[]any{now.Add(-rule.TTL), rule.Value}})
+		} else {
+			deletions = append(deletions, deletion{"DELETE FROM events WHERE received_at < $1 AND source = $2 AND name <> ALL($3)",
+				[]any{now.Add(-rule.TTL), rule.Value, names}})
+		}
+	}
+	if r.retention.Default > 0 {
+		deletions = append(deletions, deletion{"DELETE FROM events WHERE received_at < $1 AND name <> ALL($2) AND source <> ALL($3)",
+			[]any{now.Add(-r.retention.Default), names, sources}})
+	}
+
+	for _, d := range deletions {
+		tag, err := r.pool.Exec(ctx, d.sql, d.args...)
+		if err != nil {
+			return deleted, errors.Wrap(err, "delete expired events")
+		}
+		deleted += tag.RowsAffected()
+	}
+	return deleted, nil
+}
+
+// Name identifies the event store in readiness reports.
+func (r *EventRepository) Name() string {
+	return "postgres"
+}
+
+// Ping checks that the database answers.
+func (r *EventRepository) Ping(ctx context.Context) error {
+	return errors.Wrap(r.pool.Ping(ctx), "ping postgres")
+}
+
+// Ensure interface compliance at compile-time.
+var (
+	_ usecase.DependencyCheck      = (*EventRepository)(nil)
+	_ usecase.EventRepository      = (*EventRepository)(nil)
+	_ usecase.UserEventStore       = (*EventRepository)(nil)
+	_ usecase.EventPurger          = (*EventRepository)(nil)
+	_ usecase.ArchivableEventStore = (*EventRepository)(nil)
+	_ usecase.EventSource          = (*EventRepository)(nil)
+)
+
+// dropExpiredPartitions drops the monthly partitions whose events all expired, returning how many
+// events they held. Nothing is dropped while some events are kept forever.
+func (r *EventRepository) dropExpiredPartitions(ctx context.Context, now time.Time) (int64, error) {
+	longest, ok := r.retention.Longest()
+	if !ok {
+		return 0, nil
+	}
+	cutoff := now.Add(-longest)
+
+	rows, err := r.pool.Query(ctx, "SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid WHERE i.inhparent = 'events'::regclass")
+	if err != nil {
+		return 0, errors.Wrap(err, "list partitions")
+	}
+	names, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return 0, errors.Wrap(err, "list partitions")
+	}
+
+	var dropped int64
+	for _, name := range names {
+		month, err := time.Parse("events_2006_01", name)
+		if err != nil || month.AddDate(0, 1, 0).After(cutoff) {
+			continue
+		}
+		table := pgx.Identifier{name}.Sanitize()
+		var count int64
+		err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", partitionLock); err != nil {
+				return err
+			}
+			if err := tx.QueryRow(ctx, "SELECT count(*) FROM "+table).Scan(&count); err != nil {
+				return err
+			}
+			_, err := tx.Exec(ctx, "DROP TABLE "+table)
+			return err
+		})
+		if err != nil {
+			return dropped, errors.Wrapf(err, "drop partition %s", name)
+		}
+		dropped += count
+
+		r.mu.Lock()
+		delete(r.partitions, month)
+		r.mu.Unlock()
+	}
+	return dropped, nil
+}
+
+// ensurePartition creates the monthly partition holding at unless it is known to exist.
+func (r *EventRepository) ensurePartition(ctx context.Context, at time.Time) error {
+	at = at.UTC()
+	month := time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	r.mu.Lock()
+	known := r.partitions[month]
+	r.mu.Unlock()
+	if known {
+		return nil
+	}
+
+	next := month.AddDate(0, 1, 0)
+	sql := fmt.Sprintf("CREATE TABLE IF NOT EXISTS events_%s PARTITION OF events FOR VALUES FROM ('%s') TO ('%s')",
+		month.Format("2006_01"), month.Format(time.RFC3339), next.Format(time.RFC3339))
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// IF NOT EXISTS alone still races when two workers create the same partition.
+		if _, err := tx.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", partitionLock); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, sql)
+		return err
+	})
+	if err != nil {
+		return errors.Wrapf(err, "create partition for %s", month.Format("2006-01"))
+	}
+
+	r.mu.Lock()
+	r.partitions[month] = true
+	r.mu.Unlock()
+	return nil
+}
+
+// each runs sql and calls fn for every decoded row.
+func (r *EventRepository) each(ctx context.Context, sql string, args []any, fn func(domain.Event) error) error {
+	rows, err := r.pool.Query(ctx, sql, args...)
+	if err != nil {
+		return errors.Wrap(err, "query events")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "iterate events")
+}
+
+func eventArgs(event domain.Event) []any {
+	metadata := []byte(event.Metadata)
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+	return []any{
+		event.ID, event.Name, event.UserID, event.Source, metadata,
+		event.OccurredAt, event.ReceivedAt, nullable(event.UserAgent), nullable(event.IP),
+		jsonOrNil(event.Device), jsonOrNil(event.Geo), event.Consent, event.Restored,
+	}
+}
+
+func scanEvent(rows pgx.Rows) (domain.Event, error) {
+	var (
+		event              domain.Event
+		metadata           []byte
+		userAgent, ip      *string
+		device, geo        []byte
+		occurred, received time.Time
+	)
+	err := rows.Scan(&event.ID, &event.Name, &event.UserID, &event.Source, &metadata,
+		&occurred, &received, &userAgent, &ip, &device, &geo, &event.Consent, &event.Restored)
+	if err != nil {
+		return domain.Event{}, errors.Wrap(err, "decode event")
+	}
+	event.Metadata = metadata
+	event.OccurredAt, event.ReceivedAt = occurred.UTC(), received.UTC()
+	if userAgent != nil {
+		event.UserAgent = *userAgent
+	}
+	if ip != nil {
+		event.IP = *ip
+	}
+	if device != nil {
+		event.Device = &domain.Device{}
+		if err := json.Unmarshal(device, event.Device); err != nil {
+			return domain.Event{}, errors.Wrap(err, "decode event device")
+		}
+	}
+	if geo != nil {
+		event.Geo = &domain.Geo{}
+		if err := json.Unmarshal(geo, event.Geo); err != nil {
+			return domain.Event{}, errors.Wrap(err, "decode event geo")
+		}
+	}
+	return event, nil
+}
+
+func nullable(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// jsonOrNil encodes value as JSON, or returns nil so a nil pointer is stored as NULL.
+func jsonOrNil[T any](value *T) []byte {
+	if value == nil {
+		return nil
+	}
+	raw, _ := json.Marshal(value)
+	return raw
+}
@@ -0,0 +1,21 @@
+-- Events are range partitioned by month of occurrence. Monthly partitions are created on demand
+-- by the repository; the primary key must include the partition key.
+CREATE TABLE IF NOT EXISTS events (
+    id          uuid        NOT NULL,
+    name        text        NOT NULL,
+    user_id     text        NOT NULL,
+    source      text        NOT NULL,
+    metadata    jsonb       NOT NULL DEFAULT '{}'::jsonb,
+    occurred_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL,
+    user_agent  text,
+    ip          text,
+    device      jsonb,
+    geo         jsonb,
+    consent     text[],
+    PRIMARY KEY (id, occurred_at)
+) PARTITION BY RANGE (occurred_at);
+
+CREATE INDEX IF NOT EXISTS events_user_id_occurred_at_idx ON events (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS events_received_at_idx ON events (received_at);
+CREATE INDEX IF NOT EXISTS events_name_received_at_idx ON events (name, received_at);
@@ -0,0 +1,48 @@
+-- Events move to monthly partitions by reception, the time retention purges by, so partitions
+-- past every retention can be dropped whole. Reception times are set by the service, unlike
+-- occurrence times, so they also bound the number of partitions. Existing rows are copied into
+-- partitions created for their months.
+CREATE TABLE events_by_reception (
+    id          uuid        NOT NULL,
+    name        text        NOT NULL,
+    user_id     text        NOT NULL,
+    source      text        NOT NULL,
+    metadata    jsonb       NOT NULL DEFAULT '{}'::jsonb,
+    occurred_at timestamptz NOT NULL,
+    received_at timestamptz NOT NULL,
+    user_agent  text,
+    ip          text,
+    device      jsonb,
+    geo         jsonb,
+    consent     text[],
+    PRIMARY KEY (id, received_at)
+) PARTITION BY RANGE (received_at);
+
+DO $$
+DECLARE
+    month timestamp;
+    part  text;
+BEGIN
+    FOR month IN SELECT DISTINCT date_trunc('month', received_at AT TIME ZONE 'UTC') FROM events LOOP
+        EXECUTE format('CREATE TABLE %I PARTITION OF events_by_reception FOR VALUES FROM (%L) TO (%L)',
+            'events_r_' || to_char(month, 'YYYY_MM'),
+            to_char(month, 'YYYY-MM-DD"T"HH24:MI:SS"Z"'),
+            to_char(month + interval '1 month', 'YYYY-MM-DD"T"HH24:MI:SS"Z"'));
+    END LOOP;
+
+    INSERT INTO events_by_reception SELECT id, name, user_id, source, metadata, occurred_at,
+        received_at, user_agent, ip, device, geo, consent FROM events;
+    DROP TABLE events;
+    ALTER TABLE events_by_reception RENAME TO events;
+    ALTER TABLE events RENAME CONSTRAINT events_by_reception_pkey TO events_pkey;
+
+    FOR part IN SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid
+        WHERE i.inhparent = 'events'::regclass LOOP
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', part, 'events_' || substr(part, length('events_r_') + 1));
+    END LOOP;
+END
+$$;
+
+CREATE INDEX events_user_id_occurred_at_idx ON events (user_id, occurred_at);
+CREATE INDEX events_received_at_idx ON events (received_at);
+CREATE INDEX events_name_received_at_idx ON events (name, received_at);
@@ -0,0 +1,2 @@
+-- Events replayed from the archive are already archived; archival skips them.
+ALTER TABLE events ADD COLUMN IF NOT EXISTS restored boolean NOT NULL DEFAULT false;
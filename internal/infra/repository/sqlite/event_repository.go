@@ -0,0 +1,504 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// ErrClosed is returned for writes submitted after Close.
+var ErrClosed = errors.New("sqlite event repository closed")
+
+const eventColumns = "id, name, user_id, source, metadata, occurred_at, received_at, user_agent, ip, device, geo, consent, restored"
+
+// write is one Persist or Upsert waiting for the batch that commits it.
+type write struct {
+	event  domain.Event
+	upsert bool
+	done   chan error
+}
+
+// EventRepository stores events in an embedded SQLite database. Persist and Upsert calls are
+// grouped into batches committed in a single transaction, which amortises fsyncs on small disks;
+// each call still returns only once its batch is committed.
+type EventRepository struct {
+	db            *sql.DB
+	retention     domain.RetentionPolicy
+	batchSize     int
+	flushInterval time.Duration
+	logger        *slog.Logger
+
+	mu      sync.RWMutex
+	closed  bool
+	writes  chan write
+	stopped chan struct{}
+}
+
+// NewEventRepository applies pending migrations and starts the batch writer. A batch is committed
+// once it holds batchSize writes or flushInterval after its first write.
+func NewEventRepository(ctx context.Context, db *sql.DB, retention domain.RetentionPolicy, batchSize int, flushInterval time.Duration, logger *slog.Logger) (*EventRepository, error) {
+	if batchSize <= 0 || flushInterval <= 0 {
+		return nil, errors.New("sqlite batch size and flush interval must be positive")
+	}
+	if err := Migrate(ctx, db); err != nil {
+		return nil, err
+	}
+	r := &EventRepository{
+		db:            db,
+		retention:     retention,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		logger:        logger.With("component", "sqlite_event_repository"),
+		writes:        make(chan write, batchSize),
+		stopped:       make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Persist inserts a new event, returning usecase.ErrDuplicateEvent when its id is already stored.
+// A call whose context ends while its write is queued may still store the event, which its retry
+// then finds.
+func (r *EventRepository) Persist(ctx context.Context, event domain.Event) error {
+	err := r.submit(ctx, event, false)
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY {
+		return errors.Wrapf(usecase.ErrDuplicateEvent, "event %s", event.ID)
+	}
+	return errors.Wrap(classifyWriteError(err), "insert event")
+}
+
+// Upsert replaces the stored event with the same id, inserting it when absent.
+func (r *EventRepository) Upsert(ctx context.Context, event domain.Event) error {
+	return errors.Wrap(classifyWriteError(r.submit(ctx, event, true)), "upsert event")
+}
+
+// classifyWriteError marks constraint violations and oversized values as permanent, and a locked
+// database as transient.
+func classifyWriteError(err error) error {
+	var sqliteErr *sqlite.Error
+	if err == nil || !errors.As(err, &sqliteErr) {
+		return err
+	}
+	// Extended result codes keep the primary code in their low byte.
+	switch sqliteErr.Code() & 0xff {
+	case sqlite3.SQLITE_CONSTRAINT, sqlite3.SQLITE_TOOBIG, sqlite3.SQLITE_MISMATCH:
+		return usecase.Permanent(err)
+	case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+		return usecase.Transient(err)
+	}
+	return err
+}
+
+// Close commits pending writes and stops the batch writer. The database itself is left open.
+func (r *EventRepository) Close() {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.writes)
+	}
+	r.mu.Unlock()
+	<-r.stopped
+}
+
+func (r *EventRepository) submit(ctx context.Context, event domain.Event, upsert bool) error {
+	w := write{event: event, upsert: upsert, done: make(chan error, 1)}
+
+	r.mu.RLock()
+	if r.closed {
+		r.mu.RUnlock()
+		return ErrClosed
+	}
+	select {
+	case r.writes <- w:
+	case <-ctx.Done():
+		r.mu.RUnlock()
+		return ctx.Err()
+	}
+	r.mu.RUnlock()
+
+	select {
+	case err := <-w.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run collects writes into batches until the writes channel is closed.
+func (r *EventRepository) run() {
+	defer close(r.stopped)
+	for first := range r.writes {
+		batch := []write{first}
+		timer := time.NewTimer(r.flushInterval)
+	collect:
+		for len(batch) < r.batchSize {
+			select {
+			case w, ok := <-r.writes:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, w)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		r.commit(batch)
+	}
+}
+
+// commit writes batch in one transaction. A failing statement only fails its own write, since
+// SQLite rolls back just that statement; a failed commit fails the whole batch.
+func (r *EventRepository) commit(batch []write) {
+	ctx := context.Background()
+	errs := make([]error, len(batch))
+
+	err := func() error {
+		tx, err := r.db.BeginTx(ctx, nil)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = tx.Rollback() }()
+
+		insert, err := tx.PrepareContext(ctx, "INSERT INTO events ("+eventColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		if err != nil {
+			return err
+		}
+		defer insert.Close()
+		upsert, err := tx.PrepareContext(ctx, "INSERT OR REPLACE INTO events ("+eventColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)")
+		if err != nil {
+			return err
+		}
+		defer upsert.Close()
+
+		for i, w := range batch {
+			stmt := insert
+			if w.upsert {
+				stmt = upsert
+			}
+			_, errs[i] = stmt.ExecContext(ctx, eventArgs(w.event)...)
+		}
+		return tx.Commit()
+	}()
+	if err != nil {
+		r.logger.Error("event batch failed", "events", len(batch), "error", err)
+	}
+
+	for i, w := range batch {
+		if err != nil {
+			w.done <- err
+		} else {
+			w.done <- errs[i]
+		}
+	}
+}
+
+// EachEvent streams events matching query in reception order, or occurrence order when the query
+// selects by occurrence time. Metadata filters compare JSON strings with json_extract.
+func (r *EventRepository) EachEvent(ctx context.Context, query usecase.EventQuery, fn func(domain.Event) error) error {
+	timeColumn := "received_at"
+	if query.ByOccurrence {
+		timeColumn = "occurred_at"
+	}
+
+	var where []string
+	var args []any
+	if !query.Since.IsZero() {
+		where, args = append(where, timeColumn+" >= ?"), append(args, query.Since.UnixNano())
+	}
+	if !query.Until.IsZero() {
+		where, args = append(where, timeColumn+" < ?"), append(args, query.Until.UnixNano())
+	}
+	if query.Name != "" {
+		where, args = append(where, "name = ?"), append(args, query.Name)
+	}
+	if query.Source != "" {
+		where, args = append(where, "source = ?"), append(args, query.Source)
+	}
+	for key, value := range query.Metadata {
+		where = append(where, "json_type(metadata, ?) = 'text' AND json_extract(metadata, ?) = ?")
+		path := metadataPath(key)
+		args = append(args, path, path, value)
+	}
+
+	sqlQuery := "SELECT " + eventColumns + " FROM events"
+	if len(where) > 0 {
+		sqlQuery += " WHERE " + strings.Join(where, " AND ")
+	}
+	sqlQuery += " ORDER BY " + timeColumn + ", id"
+	return r.each(ctx, sqlQuery, args, fn)
+}
+
+// EachUserEvent streams every event belonging to userID in occurrence order.
+func (r *EventRepository) EachUserEvent(ctx context.Context, userID string, fn func(domain.Event) error) error {
+	return r.each(ctx, "SELECT "+eventColumns+" FROM events WHERE user_id = ? ORDER BY occurred_at, id", []any{userID}, fn)
+}
+
+// UserProfile aggregates a summary of the events stored for userID.
+func (r *EventRepository) UserProfile(ctx context.Context, userID string) (domain.UserProfile, error) {
+	var (
+		count               int64
+		firstSeen, lastSeen sql.NullInt64
+		sources, names      string
+	)
+	err := r.db.QueryRowContext(ctx, `SELECT count(*), min(occurred_at), max(occurred_at),
+		json_group_array(DISTINCT source), json_group_array(DISTINCT name)
+		FROM events WHERE user_id = ?`, userID).Scan(&count, &firstSeen, &lastSeen, &sources, &names)
+	if err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "aggregate user profile")
+	}
+
+	profile := domain.UserProfile{UserID: userID, EventCount: count, Sources: []string{}, EventNames: []string{}}
+	if count == 0 {
+		return profile, nil
+	}
+	if err := json.Unmarshal([]byte(sources), &profile.Sources); err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "decode user sources")
+	}
+	if err := json.Unmarshal([]byte(names), &profile.EventNames); err != nil {
+		return domain.UserProfile{}, errors.Wrap(err, "decode user event names")
+	}
+	sort.Strings(profile.Sources)
+	sort.Strings(profile.EventNames)
+	profile.FirstSeen = time.Unix(0, firstSeen.Int64).UTC()
+	profile.LastSeen = time.Unix(0, lastSeen.Int64).UTC()
+	return profile, nil
+}
+
+// EraseUser deletes or anonymizes every event belonging to userID. Anonymized events are
+// reassigned to a random pseudonym and stripped of metadata and client details.
+func (r *EventRepository) EraseUser(ctx context.Context, userID string, mode domain.ErasureMode) (int64, error) {
+	if mode == domain.ErasureModeAnonymize {
+		res, err := r.db.ExecContext(ctx, `UPDATE events SET user_id = ?, metadata = '{}',
+			user_agent = NULL, ip = NULL, geo = json_remove(geo, '$.city', '$.region')
+			WHERE user_id = ?`, "anon-"+uuid.NewString(), userID)
+		if err != nil {
+			return 0, errors.Wrap(err, "anonymize user events")
+		}
+		return res.RowsAffected()
+	}
+
+	res, err := r.db.ExecContext(ctx, "DELETE FROM events WHERE user_id = ?", userID)
+	if err != nil {
+		return 0, errors.Wrap(err, "delete user events")
+	}
+	return res.RowsAffected()
+}
+
+// EventsReceivedBefore returns up to limit of the oldest events received before cutoff.
+func (r *EventRepository) EventsReceivedBefore(ctx context.Context, cutoff time.Time, limit int) ([]domain.Event, error) {
+	var events []domain.Event
+	err := r.each(ctx, "SELECT "+eventColumns+" FROM events WHERE received_at < ? AND NOT restored ORDER BY received_at, id LIMIT ?",
+		[]any{cutoff.UnixNano(), limit}, func(event domain.Event) error {
+			events = append(events, event)
+			return nil
+		})
+	if err != nil {
+		return nil, errors.Wrap(err, "find aged events")
+	}
+	return events, nil
+}
+
+// DeleteEvents removes the events with the given ids.
+func (r *EventRepository) DeleteEvents(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	res, err := r.db.ExecContext(ctx, "DELETE FROM events WHERE id IN (SELECT value FROM json_each(?))", jsonArray(keys))
+	if err != nil {
+		return 0, errors.Wrap(err, "delete events")
+	}
+	return res.RowsAffected()
+}
+
+// RequiresPurge reports whether PurgeExpired must be scheduled. SQLite has no native TTL, so any
+// retention requires it.
+func (r *EventRepository) RequiresPurge() bool {
+	return r.retention.Enabled()
+}
+
+// PurgeExpired deletes events older than the retention that applies to them. Name rules win over
+// source rules, which win over the default.
+func (r *EventRepository) PurgeExpired(ctx context.Context, now time.Time) (int64, error) {
+	var names, sources []string
+	for _, rule := range r.retention.Overrides {
+		if rule.Field == domain.RetentionFieldName {
+			names = append(names, rule.Value)
+		} else {
+			sources = append(sources, rule.Value)
+		}
+	}
+
+	type deletion struct {
+		sql  string
+		args []any
+	}
+	var deletions []deletion
+	for _, rule := range r.retention.Overrides {
+		cutoff := now.Add(-rule.TTL).UnixNano()
+		if rule.Field == domain.RetentionFieldName {
+			deletions = append(deletions, deletion{"DELETE FROM events WHERE received_at < ? AND name = ?",
+				[]any{cutoff, rule.Value}})
+		} else {
+			deletions = append(deletions, deletion{"DELETE FROM events WHERE received_at < ? AND source = ? AND name NOT IN (SELECT value FROM json_each(?))",
+				[]any{cutoff, rule.Value, jsonArray(names)}})
+		}
+	}
+	if r.retention.Default > 0 {
+		deletions = append(deletions, deletion{`DELETE FROM events WHERE received_at < ?
+			AND name NOT IN (SELECT value FROM json_each(?)) AND source NOT IN (SELECT value FROM json_each(?))`,
+			[]any{now.Add(-r.retention.Default).UnixNano(), jsonArray(names), jsonArray(sources)}})
+	}
+
+	var deleted int64
+	for _, d := range deletions {
+		res, err := r.db.ExecContext(ctx, d.sql, d.args...)
+		if err != nil {
+			return deleted, errors.Wrap(err, "delete expired events")
+		}
+		n, _ := res.RowsAffected()
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// Name identifies the event store in readiness reports.
+func (r *EventRepository) Name() string {
+	return "sqlite"
+}
+
+// Ping checks that the database answers.
+func (r *EventRepository) Ping(ctx context.Context) error {
+	return errors.Wrap(r.db.PingContext(ctx), "ping sqlite")
+}
+
+// Ensure interface compliance at compile-time.
+var (
+	_ usecase.DependencyCheck      = (*EventRepository)(nil)
+	_ usecase.EventRepository      = (*EventRepository)(nil)
+	_ usecase.UserEventStore       = (*EventRepository)(nil)
+	_ usecase.EventPurger          = (*EventRepository)(nil)
+	_ usecase.ArchivableEventStore = (*EventRepository)(nil)
+	_ usecase.EventSource          = (*EventRepository)(nil)
+)
+
+// each runs query and calls fn for every decoded row.
+func (r *EventRepository) each(ctx context.Context, query string, args []any, fn func(domain.Event) error) error {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return errors.Wrap(err, "query events")
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+	return errors.Wrap(rows.Err(), "iterate events")
+}
+
+// metadataPath builds the JSON path of a top-level metadata key, quoted so any key is addressable.
+func metadataPath(key string) string {
+	quoted, _ := json.Marshal(key)
+	return "$." + string(quoted)
+}
+
+func jsonArray(values []string) string {
+	if values == nil {
+		values = []string{}
+	}
+	raw, _ := json.Marshal(values)
+	return string(raw)
+}
+
+func eventArgs(event domain.Event) []any {
+	metadata := string(event.Metadata)
+	if metadata == "" {
+		metadata = "{}"
+	}
+	return []any{
+		event.ID.String(), event.Name, event.UserID, event.Source, metadata,
+		event.OccurredAt.UnixNano(), event.ReceivedAt.UnixNano(), nullable(event.UserAgent), nullable(event.IP),
+		jsonOrNil(event.Device), jsonOrNil(event.Geo), jsonOrNil(consentOrNil(event.Consent)), event.Restored,
+	}
+}
+
+func scanEvent(rows *sql.Rows) (domain.Event, error) {
+	var (
+		event                domain.Event
+		id, metadata         string
+		occurred, received   int64
+		userAgent, ip        sql.NullString
+		device, geo, consent sql.NullString
+	)
+	err := rows.Scan(&id, &event.Name, &event.UserID, &event.Source, &metadata,
+		&occurred, &received, &userAgent, &ip, &device, &geo, &consent, &event.Restored)
+	if err != nil {
+		return domain.Event{}, errors.Wrap(err, "decode event")
+	}
+	if event.ID, err = uuid.Parse(id); err != nil {
+		return domain.Event{}, errors.Wrap(err, "decode event id")
+	}
+	event.Metadata = json.RawMessage(metadata)
+	event.OccurredAt = time.Unix(0, occurred).UTC()
+	event.ReceivedAt = time.Unix(0, received).UTC()
+	event.UserAgent, event.IP = userAgent.String, ip.String
+	if device.Valid {
+		event.Device = &domain.Device{}
+		if err := json.Unmarshal([]byte(device.String), event.Device); err != nil {
+			return domain.Event{}, errors.Wrap(err, "decode event device")
+		}
+	}
+	if geo.Valid {
+		event.Geo = &domain.Geo{}
+		if err := json.Unmarshal([]byte(geo.String), event.Geo); err != nil {
+			return domain.Event{}, errors.Wrap(err, "decode event geo")
+		}
+	}
+	if consent.Valid {
+		if err := json.Unmarshal([]byte(consent.String), &event.Consent); err != nil {
+			return domain.Event{}, errors.Wrap(err, "decode event consent")
+		}
+	}
+	return event, nil
+}
+
+func nullable(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func consentOrNil(consent []string) *[]string {
+	if consent == nil {
+		return nil
+	}
+	return &consent
+}
+
+// jsonOrNil encodes value as JSON text, or NULL for a nil pointer.
+func jsonOrNil[T any](value *T) sql.NullString {
+	if value == nil {
+		return sql.NullString{}
+	}
+	raw, _ := json.Marshal(value)
+	return sql.NullString{String: string(raw), Valid: true}
+}
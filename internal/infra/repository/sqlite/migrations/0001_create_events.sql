@@ -0,0 +1,20 @@
+-- Times are stored as UTC Unix nanoseconds. JSON columns are validated with JSON1.
+CREATE TABLE IF NOT EXISTS events (
+    id          TEXT    PRIMARY KEY,
+    name        TEXT    NOT NULL,
+    user_id     TEXT    NOT NULL,
+    source      TEXT    NOT NULL,
+    metadata    TEXT    NOT NULL DEFAULT '{}' CHECK (json_valid(metadata)),
+    occurred_at INTEGER NOT NULL,
+    received_at INTEGER NOT NULL,
+    user_agent  TEXT,
+    ip          TEXT,
+    device      TEXT CHECK (device IS NULL OR json_valid(device)),
+    geo         TEXT CHECK (geo IS NULL OR json_valid(geo)),
+    consent     TEXT CHECK (consent IS NULL OR json_valid(consent))
+);
+
+CREATE INDEX IF NOT EXISTS events_received_at_idx ON events (received_at);
+CREATE INDEX IF NOT EXISTS events_occurred_at_idx ON events (occurred_at);
+CREATE INDEX IF NOT EXISTS events_user_id_occurred_at_idx ON events (user_id, occurred_at);
+CREATE INDEX IF NOT EXISTS events_name_received_at_idx ON events (name, received_at);
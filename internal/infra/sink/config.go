@@ -0,0 +1,111 @@
+package sink
+
+import (
+	"encoding/json"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	KindFile        = "file"
+	KindHTTP        = "http"
+	KindRedisStream = "redis_stream"
+)
+
+// defaultMaxAttempts is used when a sink does not configure max_attempts.
+const defaultMaxAttempts = 10
+
+var sinkNamePattern = regexp.MustCompile(`^[a-z0-9_-]{1,64}$`)
+
+// Config declares one secondary sink. Only the fields of the sink's type are used.
+//
+//	[
+//	  {"name": "audit", "type": "file", "path": "/var/lib/quotesnap/events.ndjson", "max_size_mb": 100, "max_files": 10},
+//	  {"name": "partner", "type": "http", "url": "https://example.com/events", "headers": {"Authorization": "Bearer x"}, "timeout": "5s"},
+//	  {"name": "stream", "type": "redis_stream", "stream": "quotesnap:events", "max_len": 100000}
+//	]
+type Config struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	MaxAttempts int    `json:"max_attempts"`
+
+	Path      string `json:"path"`
+	MaxSizeMB int    `json:"max_size_mb"`
+	MaxFiles  int    `json:"max_files"`
+
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers"`
+	Timeout string            `json:"timeout"`
+
+	Stream string `json:"stream"`
+	MaxLen int64  `json:"max_len"`
+}
+
+// Attempts returns how many times a write to the sink is attempted before it is abandoned.
+func (c Config) Attempts() int {
+	if c.MaxAttempts > 0 {
+		return c.MaxAttempts
+	}
+	return defaultMaxAttempts
+}
+
+// LoadConfigs reads sink declarations from a JSON file.
+func LoadConfigs(path string) ([]Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "read sink config")
+	}
+	var configs []Config
+	if err := json.Unmarshal(raw, &configs); err != nil {
+		return nil, errors.Wrap(err, "decode sink config")
+	}
+	return configs, nil
+}
+
+// Build validates configs and constructs the declared sinks. redisClient backs redis_stream sinks.
+func Build(configs []Config, redisClient *redis.Client) ([]usecase.EventSink, error) {
+	seen := make(map[string]bool)
+	sinks := make([]usecase.EventSink, 0, len(configs))
+	for _, cfg := range configs {
+		if !sinkNamePattern.MatchString(cfg.Name) {
+			return nil, errors.Errorf("sink name %q must match %s", cfg.Name, sinkNamePattern)
+		}
+		if seen[cfg.Name] {
+			return nil, errors.Errorf("duplicate sink name %q", cfg.Name)
+		}
+		seen[cfg.Name] = true
+
+		sink, err := build(cfg, redisClient)
+		if err != nil {
+			return nil, errors.Wrapf(err, "sink %s", cfg.Name)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+func build(cfg Config, redisClient *redis.Client) (usecase.EventSink, error) {
+	switch cfg.Type {
+	case KindFile:
+		return NewFileSink(cfg.Name, cfg.Path, int64(cfg.MaxSizeMB)<<20, cfg.MaxFiles)
+	case KindHTTP:
+		timeout := 10 * time.Second
+		if cfg.Timeout != "" {
+			parsed, err := time.ParseDuration(cfg.Timeout)
+			if err != nil {
+				return nil, errors.Errorf("invalid timeout %q", cfg.Timeout)
+			}
+			timeout = parsed
+		}
+		return NewHTTPSink(cfg.Name, cfg.URL, cfg.Headers, timeout)
+	case KindRedisStream:
+		return NewRedisStreamSink(cfg.Name, redisClient, cfg.Stream, cfg.MaxLen)
+	}
+	return nil, errors.Errorf("unknown sink type %q", cfg.Type)
+}
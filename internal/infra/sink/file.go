@@ -0,0 +1,173 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// rotationLayout is the timestamp suffix of rotated files.
+const rotationLayout = "20060102T150405.000000000Z"
+
+// FileSink appends events as NDJSON to a local file. Once the file exceeds maxSize it is renamed
+// with a timestamp suffix and a new file is started; only the newest maxFiles rotated files are
+// kept.
+type FileSink struct {
+	name     string
+	path     string
+	maxSize  int64
+	maxFiles int
+
+	mu   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// NewFileSink opens path for appending. A zero maxSize disables rotation and a zero maxFiles
+// keeps every rotated file.
+func NewFileSink(name, path string, maxSize int64, maxFiles int) (*FileSink, error) {
+	if path == "" {
+		return nil, errors.New("path is required")
+	}
+	s := &FileSink{name: name, path: path, maxSize: maxSize, maxFiles: maxFiles}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Name returns the configured sink name.
+func (s *FileSink) Name() string { return s.name }
+
+// Kind returns the sink type.
+func (s *FileSink) Kind() string { return KindFile }
+
+// Write appends event as one JSON line, rotating the file first when it is full.
+func (s *FileSink) Write(_ context.Context, event domain.Event) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal event")
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A failed rotation may have left no file open.
+	if s.file == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	if s.maxSize > 0 && s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	return errors.Wrap(err, "append event")
+}
+
+// Check verifies the current file is still present and writable.
+func (s *FileSink) Check(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := os.Stat(s.path); err != nil {
+		return errors.Wrap(err, "stat sink file")
+	}
+	if s.file == nil {
+		return errors.New("sink file is not open")
+	}
+	return errors.Wrap(s.file.Sync(), "sync sink file")
+}
+
+func (s *FileSink) open() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o750); err != nil {
+		return errors.Wrap(err, "create sink directory")
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return errors.Wrap(err, "open sink file")
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return errors.Wrap(err, "stat sink file")
+	}
+	s.file, s.size = file, info.Size()
+	return nil
+}
+
+// rotate renames the current file and starts a new one. When renaming fails the current file is
+// reopened, so writes carry on and rotation is tried again by the next write; when opening fails
+// s.file is left nil for the next write to retry.
+func (s *FileSink) rotate() error {
+	err := s.file.Close()
+	s.file = nil
+	if err != nil {
+		return errors.Wrap(err, "close sink file")
+	}
+	rotated := s.path + "." + time.Now().UTC().Format(rotationLayout)
+	if err := os.Rename(s.path, rotated); err != nil {
+		if openErr := s.open(); openErr != nil {
+			return openErr
+		}
+		return errors.Wrap(err, "rotate sink file")
+	}
+	if err := s.open(); err != nil {
+		return err
+	}
+	return s.prune()
+}
+
+// prune removes the oldest rotated files beyond maxFiles. Only names carrying a rotation
+// timestamp count, and they sort chronologically.
+func (s *FileSink) prune() error {
+	if s.maxFiles <= 0 {
+		return nil
+	}
+	candidates, err := filepath.Glob(s.path + ".*")
+	if err != nil {
+		return errors.Wrap(err, "list rotated files")
+	}
+	var rotated []string
+	for _, candidate := range candidates {
+		if _, err := time.Parse(rotationLayout, strings.TrimPrefix(candidate, s.path+".")); err == nil {
+			rotated = append(rotated, candidate)
+		}
+	}
+	sort.Strings(rotated)
+	for len(rotated) > s.maxFiles {
+		if err := os.Remove(rotated[0]); err != nil {
+			return errors.Wrap(err, "remove rotated file")
+		}
+		rotated = rotated[1:]
+	}
+	return nil
+}
+
+// Close closes the current file.
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// Ensure FileSink satisfies the EventSink dependency.
+var _ usecase.EventSink = (*FileSink)(nil)
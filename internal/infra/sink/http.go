@@ -0,0 +1,75 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// HTTPSink forwards each event as a JSON POST to a fixed URL.
+type HTTPSink struct {
+	name    string
+	url     string
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewHTTPSink constructs an HTTPSink posting to target with the extra headers.
+func NewHTTPSink(name, target string, headers map[string]string, timeout time.Duration) (*HTTPSink, error) {
+	parsed, err := url.Parse(target)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+		return nil, errors.New("url must be an absolute http or https URL")
+	}
+	return &HTTPSink{name: name, url: target, headers: headers, client: &http.Client{Timeout: timeout}}, nil
+}
+
+// Name returns the configured sink name.
+func (s *HTTPSink) Name() string { return s.name }
+
+// Kind returns the sink type.
+func (s *HTTPSink) Kind() string { return KindHTTP }
+
+// Write posts event. Responses outside 2xx are errors.
+func (s *HTTPSink) Write(ctx context.Context, event domain.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal event")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "build request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "post event")
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.Errorf("forwarder responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Check has no side-effect free probe for an arbitrary endpoint; health relies on write outcomes.
+func (s *HTTPSink) Check(_ context.Context) error {
+	return nil
+}
+
+// Ensure HTTPSink satisfies the EventSink dependency.
+var _ usecase.EventSink = (*HTTPSink)(nil)
@@ -0,0 +1,59 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+// RedisStreamSink appends events to a Redis stream, trimmed approximately to maxLen entries.
+type RedisStreamSink struct {
+	name   string
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+// NewRedisStreamSink constructs a RedisStreamSink. A zero maxLen disables trimming.
+func NewRedisStreamSink(name string, client *redis.Client, stream string, maxLen int64) (*RedisStreamSink, error) {
+	if stream == "" {
+		return nil, errors.New("stream is required")
+	}
+	return &RedisStreamSink{name: name, client: client, stream: stream, maxLen: maxLen}, nil
+}
+
+// Name returns the configured sink name.
+func (s *RedisStreamSink) Name() string { return s.name }
+
+// Kind returns the sink type.
+func (s *RedisStreamSink) Kind() string { return KindRedisStream }
+
+// Write adds event to the stream with its id, name and JSON encoding as fields.
+func (s *RedisStreamSink) Write(ctx context.Context, event domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "marshal event")
+	}
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]any{"id": event.ID.String(), "name": event.Name, "event": payload},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+	return errors.Wrap(s.client.XAdd(ctx, args).Err(), "append to stream")
+}
+
+// Check pings Redis.
+func (s *RedisStreamSink) Check(ctx context.Context) error {
+	return errors.Wrap(s.client.Ping(ctx).Err(), "ping redis")
+}
+
+// Ensure RedisStreamSink satisfies the EventSink dependency.
+var _ usecase.EventSink = (*RedisStreamSink)(nil)
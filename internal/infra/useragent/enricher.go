@@ -0,0 +1,49 @@
+package useragent
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// BotActionFlag keeps bot traffic but marks it via Device.Bot.
+	BotActionFlag = "flag"
+	// BotActionDrop discards events emitted by known bots.
+	BotActionDrop = "drop"
+)
+
+// Enricher attaches parsed User-Agent details to events and applies the configured bot policy.
+type Enricher struct {
+	botAction string
+}
+
+// NewEnricher constructs an Enricher for the given bot action.
+func NewEnricher(botAction string) (*Enricher, error) {
+	switch botAction {
+	case BotActionFlag, BotActionDrop:
+	default:
+		return nil, errors.Errorf("unknown bot action %q", botAction)
+	}
+	return &Enricher{botAction: botAction}, nil
+}
+
+// Enrich parses event.UserAgent into event.Device.
+func (e *Enricher) Enrich(_ context.Context, event *domain.Event) error {
+	if event.UserAgent == "" {
+		return nil
+	}
+
+	device := Parse(event.UserAgent)
+	if device.Bot && e.botAction == BotActionDrop {
+		return errors.Wrap(usecase.ErrEventDropped, "bot user agent")
+	}
+	event.Device = &device
+	return nil
+}
+
+// Ensure Enricher satisfies the EventEnricher dependency.
+var _ usecase.EventEnricher = (*Enricher)(nil)
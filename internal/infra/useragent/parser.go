@@ -0,0 +1,149 @@
+package useragent
+
+import (
+	"strings"
+
+	"quotesnap/internal/core/domain"
+)
+
+// Device types reported by Parse.
+const (
+	DeviceTypeDesktop = "desktop"
+	DeviceTypeMobile  = "mobile"
+	DeviceTypeTablet  = "tablet"
+	DeviceTypeTV      = "tv"
+	DeviceTypeBot     = "bot"
+	DeviceTypeUnknown = "unknown"
+)
+
+// botTokens lists lower-cased substrings that identify crawlers, previewers and scripted clients.
+var botTokens = []string{
+	"bot", "crawl", "spider", "slurp", "mediapartners", "facebookexternalhit",
+	"embedly", "whatsapp", "headlesschrome", "phantomjs", "lighthouse", "pingdom",
+	"curl/", "wget/", "python-requests", "python-urllib", "go-http-client",
+	"scrapy", "httpclient", "libwww-perl",
+}
+
+type token struct {
+	name   string
+	marker string
+}
+
+// browserTokens is ordered so that derived browsers are matched before the engines they embed.
+var browserTokens = []token{
+	{name: "Edge", marker: "Edg/"},
+	{name: "Edge", marker: "EdgA/"},
+	{name: "Edge", marker: "EdgiOS/"},
+	{name: "Edge", marker: "Edge/"},
+	{name: "Opera", marker: "OPR/"},
+	{name: "Samsung Internet", marker: "SamsungBrowser/"},
+	{name: "Firefox", marker: "FxiOS/"},
+	{name: "Firefox", marker: "Firefox/"},
+	{name: "Chrome", marker: "CriOS/"},
+	{name: "Chrome", marker: "Chrome/"},
+	{name: "Internet Explorer", marker: "MSIE "},
+	{name: "Internet Explorer", marker: "Trident/"},
+}
+
+// Parse derives browser, operating system, device type and bot status from a raw User-Agent.
+func Parse(ua string) domain.Device {
+	ua = strings.TrimSpace(ua)
+	if ua == "" {
+		return domain.Device{Type: DeviceTypeUnknown}
+	}
+
+	device := domain.Device{}
+	device.Bot = isBot(ua)
+	device.Browser, device.BrowserVersion = parseBrowser(ua)
+	device.OS, device.OSVersion = parseOS(ua)
+	device.Type = parseDeviceType(ua, device)
+	return device
+}
+
+func isBot(ua string) bool {
+	lower := strings.ToLower(ua)
+	for _, t := range botTokens {
+		if strings.Contains(lower, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseBrowser(ua string) (string, string) {
+	for _, t := range browserTokens {
+		if v, ok := versionAfter(ua, t.marker); ok {
+			return t.name, v
+		}
+	}
+	if strings.Contains(ua, "Safari/") {
+		v, _ := versionAfter(ua, "Version/")
+		return "Safari", v
+	}
+	return "", ""
+}
+
+func parseOS(ua string) (string, string) {
+	switch {
+	case strings.Contains(ua, "Windows NT "):
+		v, _ := versionAfter(ua, "Windows NT ")
+		return "Windows", v
+	case strings.Contains(ua, "iPhone OS "):
+		v, _ := versionAfter(ua, "iPhone OS ")
+		return "iOS", v
+	case strings.Contains(ua, "iPad") && strings.Contains(ua, "CPU OS "):
+		v, _ := versionAfter(ua, "CPU OS ")
+		return "iPadOS", v
+	case strings.Contains(ua, "iOS "):
+		v, _ := versionAfter(ua, "iOS ")
+		return "iOS", v
+	case strings.Contains(ua, "Android"):
+		v, _ := versionAfter(ua, "Android ")
+		return "Android", v
+	case strings.Contains(ua, "CrOS"):
+		return "ChromeOS", ""
+	case strings.Contains(ua, "Mac OS X"):
+		v, _ := versionAfter(ua, "Mac OS X ")
+		return "macOS", v
+	case strings.Contains(ua, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+func parseDeviceType(ua string, device domain.Device) string {
+	switch {
+	case device.Bot:
+		return DeviceTypeBot
+	case strings.Contains(ua, "SmartTV"), strings.Contains(ua, "SMART-TV"),
+		strings.Contains(ua, "AppleTV"), strings.Contains(ua, "CrKey"):
+		return DeviceTypeTV
+	case strings.Contains(ua, "iPad"), strings.Contains(ua, "Tablet"),
+		device.OS == "Android" && !strings.Contains(ua, "Mobile"):
+		return DeviceTypeTablet
+	case strings.Contains(ua, "Mobi"), strings.Contains(ua, "iPhone"), device.OS == "iOS":
+		return DeviceTypeMobile
+	case device.OS == "Windows", device.OS == "macOS", device.OS == "Linux", device.OS == "ChromeOS":
+		return DeviceTypeDesktop
+	}
+	return DeviceTypeUnknown
+}
+
+// versionAfter returns the dotted version that immediately follows marker, normalising
+// underscore separators used by Apple platforms.
+func versionAfter(ua, marker string) (string, bool) {
+	idx := strings.Index(ua, marker)
+	if idx < 0 {
+		return "", false
+	}
+	rest := ua[idx+len(marker):]
+	end := 0
+	for end < len(rest) {
+		c := rest[end]
+		if (c < '0' || c > '9') && c != '.' && c != '_' {
+			break
+		}
+		end++
+	}
+	return strings.TrimRight(strings.ReplaceAll(rest[:end], "_", "."), "."), true
+}
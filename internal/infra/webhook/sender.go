@@ -0,0 +1,98 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"quotesnap/internal/core/domain"
+	"quotesnap/internal/core/usecase"
+)
+
+const (
+	// HeaderEvent carries the event name of a delivery.
+	HeaderEvent = "X-QuoteSnap-Event"
+	// HeaderDelivery carries the delivery id, stable across retries of the same delivery.
+	HeaderDelivery = "X-QuoteSnap-Delivery"
+	// HeaderTimestamp carries the unix time the attempt was signed at.
+	HeaderTimestamp = "X-QuoteSnap-Timestamp"
+	// HeaderSignature carries domain.SignWebhook of the timestamp and body.
+	HeaderSignature = "X-QuoteSnap-Signature"
+)
+
+// Sender posts signed webhook deliveries over HTTP.
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender constructs a Sender whose attempts time out after timeout. Redirects are not followed
+// so a subscription cannot bounce deliveries to an unregistered URL. Unless allowPrivate is set,
+// connections to internal addresses are refused after name resolution, which also covers names
+// re-pointed since the subscription was registered; deliveries then bypass HTTP_PROXY so the
+// checked address is the one posted to.
+func NewSender(timeout time.Duration, allowPrivate bool) *Sender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !allowPrivate {
+		dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: refuseInternal}
+		transport.DialContext = dialer.DialContext
+		transport.Proxy = nil
+	}
+	return &Sender{client: &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}}
+}
+
+// refuseInternal fails dials to addresses domain.PublicWebhookAddr refuses.
+func refuseInternal(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return errors.Wrapf(err, "parse dialed address %q", address)
+	}
+	if !domain.PublicWebhookAddr(addrPort.Addr().WithZone("")) {
+		return errors.Errorf("refusing to deliver to internal address %s", addrPort.Addr())
+	}
+	return nil
+}
+
+// Send posts the delivery payload to the subscription URL.
+func (s *Sender) Send(ctx context.Context, sub domain.WebhookSubscription, delivery domain.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, errors.Wrap(err, "build webhook request")
+	}
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "QuoteSnap-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventName)
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set(HeaderSignature, domain.SignWebhook(sub.Secret, now, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "post webhook")
+	}
+	defer resp.Body.Close()
+	// Drain a bounded amount so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("webhook endpoint responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Ensure Sender satisfies the WebhookSender dependency.
+var _ usecase.WebhookSender = (*Sender)(nil)